@@ -0,0 +1,113 @@
+// Package diff строит построчный diff двух текстов для истории правок.
+package diff
+
+import "strings"
+
+// maxCells ограничивает таблицу LCS: для очень длинных текстов diff сводится к замене целиком.
+const maxCells = 4_000_000
+
+// Op — вид строки в diff.
+type Op byte
+
+const (
+	Equal  Op = ' '
+	Delete Op = '-'
+	Insert Op = '+'
+)
+
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Lines сравнивает тексты построчно по наибольшей общей подпоследовательности.
+func Lines(a, b string) []Line {
+	x, y := split(a), split(b)
+
+	// Общие начало и конец не попадают в таблицу
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	out := make([]Line, 0, len(x)+len(y))
+	for _, s := range x[:prefix] {
+		out = append(out, Line{Equal, s})
+	}
+	out = append(out, middle(x[prefix:len(x)-suffix], y[prefix:len(y)-suffix])...)
+	for _, s := range x[len(x)-suffix:] {
+		out = append(out, Line{Equal, s})
+	}
+	return out
+}
+
+// Unified — diff в текстовом виде: каждая строка с префиксом " ", "-" или "+".
+func Unified(a, b string) string {
+	var sb strings.Builder
+	for _, l := range Lines(a, b) {
+		sb.WriteByte(byte(l.Op))
+		sb.WriteString(l.Text)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+func middle(x, y []string) []Line {
+	out := make([]Line, 0, len(x)+len(y))
+	if len(x)*len(y) > maxCells {
+		for _, s := range x {
+			out = append(out, Line{Delete, s})
+		}
+		for _, s := range y {
+			out = append(out, Line{Insert, s})
+		}
+		return out
+	}
+
+	// lcs[i][j] — длина LCS для x[i:] и y[j:]
+	w := len(y) + 1
+	lcs := make([]int32, (len(x)+1)*w)
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i*w+j] = lcs[(i+1)*w+j+1] + 1
+			} else {
+				lcs[i*w+j] = max(lcs[(i+1)*w+j], lcs[i*w+j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			out = append(out, Line{Equal, x[i]})
+			i++
+			j++
+		case lcs[(i+1)*w+j] >= lcs[i*w+j+1]:
+			out = append(out, Line{Delete, x[i]})
+			i++
+		default:
+			out = append(out, Line{Insert, y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		out = append(out, Line{Delete, x[i]})
+	}
+	for ; j < len(y); j++ {
+		out = append(out, Line{Insert, y[j]})
+	}
+	return out
+}
+
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
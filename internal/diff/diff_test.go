@@ -0,0 +1,84 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Line
+	}{
+		{name: "both empty", want: []Line{}},
+		{name: "equal", a: "one\ntwo\n", b: "one\ntwo", want: []Line{{Equal, "one"}, {Equal, "two"}}},
+		{name: "from empty", b: "one\ntwo", want: []Line{{Insert, "one"}, {Insert, "two"}}},
+		{name: "to empty", a: "one", want: []Line{{Delete, "one"}}},
+		{
+			name: "line changed in the middle",
+			a:    "one\ntwo\nthree",
+			b:    "one\n2\nthree",
+			want: []Line{{Equal, "one"}, {Delete, "two"}, {Insert, "2"}, {Equal, "three"}},
+		},
+		{
+			name: "insert and delete around common lines",
+			a:    "a\nb\nc\nd",
+			b:    "b\nc\nx\nd\ne",
+			want: []Line{{Delete, "a"}, {Equal, "b"}, {Equal, "c"}, {Insert, "x"}, {Equal, "d"}, {Insert, "e"}},
+		},
+		{
+			name: "reordered lines keep the longest common part",
+			a:    "x\ny\nz",
+			b:    "y\nz\nx",
+			want: []Line{{Delete, "x"}, {Equal, "y"}, {Equal, "z"}, {Insert, "x"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lines(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Lines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Любой diff должен восстанавливать обе стороны: a — из Equal и Delete, b — из Equal и Insert.
+func TestLinesRestoresBothSides(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{name: "small edit", a: "a\nb\nc\nd\ne", b: "a\nc\nd\nx\ne\nf"},
+		{name: "repeated lines", a: "x\nx\ny\nx", b: "y\nx\nx\nx\ny"},
+		{name: "past the table limit", a: strings.Repeat("a\n", 2500) + "end", b: "start\n" + strings.Repeat("b\n", 2500) + "end"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var a, b []string
+			for _, l := range Lines(tt.a, tt.b) {
+				if l.Op != Insert {
+					a = append(a, l.Text)
+				}
+				if l.Op != Delete {
+					b = append(b, l.Text)
+				}
+			}
+			if got := strings.Join(a, "\n"); got != tt.a {
+				t.Fatalf("old side = %q, want %q", got, tt.a)
+			}
+			if got := strings.Join(b, "\n"); got != tt.b {
+				t.Fatalf("new side = %q, want %q", got, tt.b)
+			}
+		})
+	}
+}
+
+func TestUnified(t *testing.T) {
+	got := Unified("one\ntwo", "one\nthree")
+	want := " one\n-two\n+three\n"
+	if got != want {
+		t.Fatalf("Unified() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,61 @@
+package entity
+
+import "time"
+
+type AuditAction string
+
+const (
+	AuditActionTopicMove         AuditAction = "topic.move"
+	AuditActionTopicMerge        AuditAction = "topic.merge"
+	AuditActionTopicSplit        AuditAction = "topic.split"
+	AuditActionTopicUpdate       AuditAction = "topic.update"
+	AuditActionTopicDelete       AuditAction = "topic.delete"
+	AuditActionTopicLock         AuditAction = "topic.lock"
+	AuditActionTopicUnlock       AuditAction = "topic.unlock"
+	AuditActionPostUpdate        AuditAction = "post.update"
+	AuditActionPostDelete        AuditAction = "post.delete"
+	AuditActionPostRollback      AuditAction = "post.rollback"
+	AuditActionCommentUpdate     AuditAction = "comment.update"
+	AuditActionCommentDelete     AuditAction = "comment.delete"
+	AuditActionCommentRollback   AuditAction = "comment.rollback"
+	AuditActionCategoryCreate    AuditAction = "category.create"
+	AuditActionCategoryUpdate    AuditAction = "category.update"
+	AuditActionCategoryDelete    AuditAction = "category.delete"
+	AuditActionCategoryReorder   AuditAction = "category.reorder"
+	AuditActionTagCreate         AuditAction = "tag.create"
+	AuditActionWebhookCreate     AuditAction = "webhook.create"
+	AuditActionWebhookUpdate     AuditAction = "webhook.update"
+	AuditActionWebhookDelete     AuditAction = "webhook.delete"
+	AuditActionWebhookReactivate AuditAction = "webhook.reactivate"
+	AuditActionCaseResolve       AuditAction = "moderation.resolve"
+	AuditActionSanctionIssue     AuditAction = "sanction.issue"
+	AuditActionSanctionLift      AuditAction = "sanction.lift"
+	AuditActionTrustOverride     AuditAction = "trust.override"
+	AuditActionTrustClear        AuditAction = "trust.clear"
+)
+
+type AuditTargetType string
+
+const (
+	AuditTargetTopic    AuditTargetType = "topic"
+	AuditTargetPost     AuditTargetType = "post"
+	AuditTargetComment  AuditTargetType = "comment"
+	AuditTargetCategory AuditTargetType = "category"
+	AuditTargetTag      AuditTargetType = "tag"
+	AuditTargetWebhook  AuditTargetType = "webhook"
+	AuditTargetCase     AuditTargetType = "moderation_case"
+	AuditTargetUser     AuditTargetType = "user"
+)
+
+type AuditEntry struct {
+	ID         int64
+	ActorID    int64
+	Action     AuditAction
+	TargetType AuditTargetType
+	TargetID   int64
+	Reason     string
+	Details    map[string]any
+	Before     map[string]any // состояние до действия; nil для создания
+	After      map[string]any // состояние после; nil для удаления
+	CreatedAt  time.Time
+}
@@ -4,9 +4,31 @@ import "time"
 
 type Category struct {
 	ID          int64
+	ParentID    int64 // 0 — корневая категория
 	Title       string
 	Slug        string
 	Description string
+	Position    int // порядок отображения среди соседей, по возрастанию
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+	// Premoderated — новый контент в категории и её подкатегориях публикуется только после одобрения
+	Premoderated bool
+	// Version растёт при каждой правке. В запросе на правку — ожидаемая версия, 0 — без проверки.
+	Version  int64
+	Children []*Category // заполняется только при построении дерева
+
+	// Статистика, заполняется при чтении категории
+	TopicsCount int64
+	PostsCount  int64
+	LastTopic   *ActivityRef
+	LastPost    *ActivityRef
+}
+
+// ActivityRef — краткая ссылка на последний активный топик или пост категории.
+type ActivityRef struct {
+	ID             int64
+	Title          string
+	AuthorID       int64
+	AuthorNickname string
+	At             time.Time
 }
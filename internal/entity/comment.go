@@ -10,4 +10,9 @@ type Comment struct {
 	AuthorNickname string
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
+	Status         Status // ACTIVE, HIDDEN, DELETED и статусы премодерации, как у топиков и постов
+	EditCount      int
+	EditedBy       int64      // последний, кто правил; 0 — комментарий не правили
+	EditedAt       *time.Time // nil — комментарий не правили
+	Version        int64      // растёт при каждой правке; в запросе на правку — ожидаемая версия
 }
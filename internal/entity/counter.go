@@ -0,0 +1,34 @@
+package entity
+
+// Counter — денормализованный счётчик, который можно пересчитать по исходным таблицам.
+type Counter string
+
+const (
+	CounterCategoryTopics Counter = "categories.topics_count"
+	CounterTopicPosts     Counter = "topics.posts_count"
+	CounterPostComments   Counter = "posts.comments_count"
+	CounterPostViews      Counter = "posts.views_count"
+)
+
+// Counters — все счётчики в порядке сверки.
+var Counters = []Counter{
+	CounterCategoryTopics,
+	CounterTopicPosts,
+	CounterPostComments,
+	CounterPostViews,
+}
+
+// CounterDrift — расхождение сохранённого значения счётчика с фактическим.
+type CounterDrift struct {
+	Counter  Counter
+	EntityID int64
+	Stored   int64
+	Actual   int64
+}
+
+type CounterReport struct {
+	Counter Counter
+	Checked int64
+	Drift   []CounterDrift
+	Fixed   bool
+}
@@ -0,0 +1,52 @@
+package entity
+
+import "time"
+
+type DraftKind string
+
+const (
+	DraftTopic   DraftKind = "topic"
+	DraftPost    DraftKind = "post"
+	DraftComment DraftKind = "comment"
+)
+
+func (k DraftKind) Valid() bool {
+	switch k {
+	case DraftTopic, DraftPost, DraftComment:
+		return true
+	}
+	return false
+}
+
+// Draft — неопубликованный текст автора. Куда он будет опубликован, задаёт Kind:
+// топик — в CategoryID, пост — в TopicID, комментарий — к PostID.
+type Draft struct {
+	ID             int64
+	AuthorID       int64
+	AuthorNickname string
+	Kind           DraftKind
+	CategoryID     int64
+	TopicID        int64
+	PostID         int64
+	Title          string
+	Content        string
+	Images         []string
+	TagIDs         []int64
+	PublishAt      *time.Time // nil — публикация не запланирована
+	LastError      string     // почему не удалась запланированная публикация; сбрасывается новым расписанием
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// ParentID — куда публикуется черновик.
+func (d *Draft) ParentID() int64 {
+	switch d.Kind {
+	case DraftTopic:
+		return d.CategoryID
+	case DraftPost:
+		return d.TopicID
+	case DraftComment:
+		return d.PostID
+	}
+	return 0
+}
@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventTopicCreated   EventType = "topic.created"
+	EventTopicUpdated   EventType = "topic.updated"
+	EventTopicDeleted   EventType = "topic.deleted"
+	EventTopicMoved     EventType = "topic.moved"
+	EventTopicMerged    EventType = "topic.merged" // агрегат — исходный топик, посты ушли в target_topic_id
+	EventTopicSplit     EventType = "topic.split"  // агрегат — новый топик, посты пришли из source_topic_id
+	EventTopicLocked    EventType = "topic.locked"
+	EventTopicUnlocked  EventType = "topic.unlocked"
+	EventPostCreated    EventType = "post.created"
+	EventPostUpdated    EventType = "post.updated"
+	EventPostDeleted    EventType = "post.deleted"
+	EventCommentCreated EventType = "comment.created"
+	EventCommentUpdated EventType = "comment.updated"
+	EventCommentDeleted EventType = "comment.deleted"
+	EventTagCreated     EventType = "tag.created"
+	EventTagAttached    EventType = "tag.attached"
+	EventTagDetached    EventType = "tag.detached"
+)
+
+var EventTypes = []EventType{
+	EventTopicCreated, EventTopicUpdated, EventTopicDeleted,
+	EventTopicMoved, EventTopicMerged, EventTopicSplit, EventTopicLocked, EventTopicUnlocked,
+	EventPostCreated, EventPostUpdated, EventPostDeleted,
+	EventCommentCreated, EventCommentUpdated, EventCommentDeleted,
+	EventTagCreated, EventTagAttached, EventTagDetached,
+}
+
+// EventVersion — версия схемы payload. Поднимается при несовместимых изменениях полей.
+const EventVersion = 1
+
+// Event — доменное событие из outbox. Подписчики читают события в порядке Cursor:
+// ID выдаётся до коммита, и по нему событие может стать видимым позже следующих.
+type Event struct {
+	ID            int64           `json:"id"`
+	TxID          int64           `json:"-"` // транзакция, записавшая событие
+	Type          EventType       `json:"type"`
+	Version       int             `json:"version"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int64           `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+}
+
+// EventCursor — позиция подписчика в outbox; события упорядочены по (TxID, ID).
+type EventCursor struct {
+	TxID int64
+	ID   int64
+}
+
+func (e *Event) Cursor() EventCursor {
+	return EventCursor{TxID: e.TxID, ID: e.ID}
+}
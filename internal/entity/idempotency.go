@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// IdempotencyKey — ответ на create-запрос, сохранённый под ключом клиента, чтобы повтор не создал дубль.
+type IdempotencyKey struct {
+	UserID      int64
+	Key         string
+	Method      string
+	RequestHash []byte
+	Response    []byte // nil, пока первый запрос выполняется
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+func (k *IdempotencyKey) Completed() bool {
+	return k.Response != nil
+}
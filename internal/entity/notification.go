@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+type NotificationKind string
+
+const (
+	NotificationNewTopic   NotificationKind = "topic.created"
+	NotificationNewPost    NotificationKind = "post.created"
+	NotificationNewComment NotificationKind = "comment.created"
+	NotificationWarning    NotificationKind = "moderation.warning"
+	NotificationRejected   NotificationKind = "moderation.rejected"
+)
+
+type Notification struct {
+	ID            int64
+	UserID        int64
+	Kind          NotificationKind
+	ActorID       int64
+	ActorNickname string
+	TopicID       int64
+	PostID        int64
+	CommentID     int64  // 0 для уведомлений о постах
+	Text          string // текст предупреждения модератора
+	CreatedAt     time.Time
+	ReadAt        *time.Time
+}
@@ -17,4 +17,8 @@ type Post struct {
 	ViewsCount     int64
 	CommentsCount  int64
 	LikesCount     int64
+	Wiki           bool       // править могут и другие пользователи с привилегией edit_wiki
+	EditedBy       int64      // последний, кто правил; 0 — пост не правили
+	EditedAt       *time.Time // nil — пост не правили
+	Version        int64      // растёт при каждой правке
 }
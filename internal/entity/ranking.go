@@ -0,0 +1,40 @@
+package entity
+
+import "time"
+
+type RankingPeriod string
+
+const (
+	RankingHot   RankingPeriod = "hot"
+	RankingDay   RankingPeriod = "day"
+	RankingWeek  RankingPeriod = "week"
+	RankingMonth RankingPeriod = "month"
+	RankingAll   RankingPeriod = "all"
+)
+
+// Activity — активность вокруг топика или поста за некоторое окно времени.
+type Activity struct {
+	Posts    int64
+	Comments int64
+	Likes    int64
+	Views    int64
+}
+
+// ActivityStats — активность топика или поста за сутки, неделю, месяц и всё время.
+type ActivityStats struct {
+	ID        int64
+	CreatedAt time.Time
+	Day       Activity
+	Week      Activity
+	Month     Activity
+	All       Activity
+}
+
+type Scores struct {
+	ID    int64
+	Hot   float64
+	Day   float64
+	Week  float64
+	Month float64
+	All   float64
+}
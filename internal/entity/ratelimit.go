@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+type RateAction string
+
+const (
+	RateTopicCreate   RateAction = "topic.create"
+	RatePostCreate    RateAction = "post.create"
+	RateCommentCreate RateAction = "comment.create"
+	RateSearch        RateAction = "search"
+)
+
+// RateLimit — token bucket: Events событий за Per, с запасом Burst подряд.
+// Нулевой Events отключает ограничение.
+type RateLimit struct {
+	Events int
+	Per    time.Duration
+	Burst  int
+}
+
+func (l RateLimit) Enabled() bool {
+	return l.Events > 0 && l.Per > 0
+}
+
+// TokensPerSecond — скорость пополнения корзины.
+func (l RateLimit) TokensPerSecond() float64 {
+	return float64(l.Events) / l.Per.Seconds()
+}
+
+// Capacity — размер корзины; без Burst равен Events.
+func (l RateLimit) Capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Events)
+}
@@ -0,0 +1,9 @@
+package entity
+
+// ReadState — состояние прочтения топика конкретным пользователем.
+type ReadState struct {
+	TopicID        int64
+	LastReadPostID int64
+	UnreadCount    int64 // посты после маркера, не считая собственных
+	IsNew          bool  // пользователь ещё не открывал топик
+}
@@ -0,0 +1,86 @@
+package entity
+
+import "time"
+
+// ContentType — на что можно пожаловаться.
+type ContentType string
+
+const (
+	ContentTopic   ContentType = "topic"
+	ContentPost    ContentType = "post"
+	ContentComment ContentType = "comment"
+)
+
+type ReportReason string
+
+const (
+	ReasonSpam     ReportReason = "spam"
+	ReasonAbuse    ReportReason = "abuse"
+	ReasonOffTopic ReportReason = "off_topic"
+	ReasonIllegal  ReportReason = "illegal"
+	ReasonOther    ReportReason = "other"
+	// ReasonPremoderation — системная жалоба на контент, задержанный премодерацией
+	ReasonPremoderation ReportReason = "premoderation"
+)
+
+type Report struct {
+	ID         int64
+	CaseID     int64
+	TargetType ContentType
+	TargetID   int64
+	ReporterID int64
+	Reason     ReportReason
+	Comment    string
+	Weight     int // зависит от уровня доверия жалующегося
+	CreatedAt  time.Time
+}
+
+type CaseStatus string
+
+const (
+	CaseOpen     CaseStatus = "open"
+	CaseClaimed  CaseStatus = "claimed"
+	CaseResolved CaseStatus = "resolved"
+)
+
+type ModerationAction string
+
+const (
+	ActionDismiss ModerationAction = "dismiss"
+	ActionHide    ModerationAction = "hide"
+	ActionDelete  ModerationAction = "delete"
+	ActionWarn    ModerationAction = "warn"
+	ActionReject  ModerationAction = "reject" // только для контента, ждущего одобрения
+)
+
+// ModerationCase объединяет все жалобы на один объект до его разбора.
+type ModerationCase struct {
+	ID             int64
+	TargetType     ContentType
+	TargetID       int64
+	CategoryID     int64
+	AuthorID       int64
+	ReportsCount   int
+	ReportWeight   int // сумма весов жалоб, по ней срабатывает автоскрытие
+	Status         CaseStatus
+	AutoHidden     bool
+	ClaimedBy      int64
+	ClaimedAt      *time.Time
+	ResolvedBy     int64
+	ResolvedAt     *time.Time
+	Resolution     ModerationAction
+	ResolutionNote string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// ContentRef — минимум сведений об объекте жалобы, нужный очереди модерации.
+type ContentRef struct {
+	Type       ContentType
+	ID         int64
+	AuthorID   int64
+	TopicID    int64
+	PostID     int64 // 0 для топиков
+	CategoryID int64
+	Status     Status
+}
@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+// Revision — версия поста или комментария после правки. Number = 1 — исходный текст.
+type Revision struct {
+	ID           int64
+	TargetType   ContentType
+	TargetID     int64
+	Number       int
+	Title        string // пусто у комментариев
+	Content      string
+	EditorID     int64
+	Reason       string
+	Diff         string // построчный diff относительно предыдущей версии
+	RestoredFrom int    // не 0, если модератор откатил к этой версии
+	CreatedAt    time.Time
+}
+
+// Text — то, что сравнивается между версиями.
+func (r *Revision) Text() string {
+	if r.Title == "" {
+		return r.Content
+	}
+	return r.Title + "\n\n" + r.Content
+}
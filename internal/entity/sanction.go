@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+type SanctionKind string
+
+const (
+	SanctionBan  SanctionKind = "ban"  // запрещает создавать и редактировать контент
+	SanctionMute SanctionKind = "mute" // запрещает только создавать, всегда с истечением
+)
+
+type Sanction struct {
+	ID         int64
+	UserID     int64
+	Kind       SanctionKind
+	CategoryID int64 // 0 — на весь форум; иначе действует и на подкатегории
+	Reason     string
+	IssuedBy   int64
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time // nil — бессрочно
+	LiftedAt   *time.Time // снята досрочно
+	LiftedBy   int64
+}
+
+func (s *Sanction) IsActive(at time.Time) bool {
+	return s.LiftedAt == nil && (s.ExpiresAt == nil || s.ExpiresAt.After(at))
+}
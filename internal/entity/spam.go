@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+// Fingerprint — отпечаток текста автора для поиска повторов.
+type Fingerprint struct {
+	ID          int64
+	AuthorID    int64
+	ContentType ContentType
+	ContentID   int64
+	Hash        string // sha256 нормализованного текста — точные повторы
+	SimHash     uint64 // по шинглам слов — почти повторы
+	CreatedAt   time.Time
+}
+
+// SpamVerdict — результат проверки нового контента. Пустой Flags — контент можно публиковать.
+type SpamVerdict struct {
+	Flags       []string
+	Score       float64 // вероятность спама по байесовскому классификатору; 0 — не обучен
+	Fingerprint Fingerprint
+}
+
+func (v *SpamVerdict) Flagged() bool {
+	return len(v.Flags) > 0
+}
+
+// TokenStats — в скольких спамовых и нормальных текстах встречалось слово.
+type TokenStats struct {
+	Spam int64
+	Ham  int64
+}
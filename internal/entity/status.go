@@ -6,4 +6,11 @@ const (
 	StatusActive Status = iota + 1
 	StatusDeleted
 	StatusHidden
+	StatusPending  // ждёт проверки модератором, публично не виден
+	StatusRejected // отклонён модератором до публикации
 )
+
+// Published — контент был опубликован: учитывается в счётчиках топиков и категорий.
+func (s Status) Published() bool {
+	return s != StatusPending && s != StatusRejected
+}
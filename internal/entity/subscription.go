@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+type SubscriptionTarget string
+
+const (
+	SubscriptionTopic    SubscriptionTarget = "topic"
+	SubscriptionCategory SubscriptionTarget = "category"
+	SubscriptionTag      SubscriptionTarget = "tag"
+)
+
+type Subscription struct {
+	UserID     int64
+	TargetType SubscriptionTarget
+	TargetID   int64
+	Muted      bool // подписка есть, но уведомления по цели не приходят
+	CreatedAt  time.Time
+}
@@ -4,4 +4,6 @@ type Tag struct {
 	ID   int64
 	Name string
 	Slug string
+	// Version заведена заранее: правки тегов пока нет, поэтому версия всегда 1
+	Version int64
 }
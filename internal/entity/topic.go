@@ -13,4 +13,28 @@ type Topic struct {
 	PostsCount     int64
 	ViewsCount     int64
 	LastActivity   time.Time
+	LockedAt       *time.Time // nil, если топик открыт
+	LockedBy       int64      // 0 — закрыт автоматически
+	LockReason     string
+	MovedToID      int64       // не 0 у redirect-заглушки, оставленной после переноса
+	AcceptedPostID int64       // ответ, принятый автором топика; 0 — не выбран
+	Version        int64       // как у Category: растёт при правке, в запросе — ожидаемая версия
+	Breadcrumbs    []*Category // путь от корня до категории топика, заполняется в GetByID
+	Read           *ReadState  // для текущего пользователя, nil у анонимов
+}
+
+func (t *Topic) IsLocked() bool {
+	return t.LockedAt != nil
+}
+
+func (t *Topic) IsRedirect() bool {
+	return t.MovedToID != 0
+}
+
+// TopicView — отметка просмотра: зритель ViewerHash смотрел топик в окне дедупликации Bucket
+// (секунды эпохи / длина окна). Одна отметка на зрителя в окне, общая для всех реплик.
+type TopicView struct {
+	TopicID    int64
+	ViewerHash string
+	Bucket     int64
 }
@@ -0,0 +1,70 @@
+package entity
+
+import "time"
+
+// TrustLevel открывает пользователю действия по мере роста репутации.
+type TrustLevel int
+
+const (
+	TrustNew TrustLevel = iota
+	TrustBasic
+	TrustMember
+	TrustRegular
+	TrustLeader
+)
+
+func (l TrustLevel) Valid() bool {
+	return l >= TrustNew && l <= TrustLeader
+}
+
+func (l TrustLevel) String() string {
+	switch l {
+	case TrustNew:
+		return "new"
+	case TrustBasic:
+		return "basic"
+	case TrustMember:
+		return "member"
+	case TrustRegular:
+		return "regular"
+	case TrustLeader:
+		return "leader"
+	}
+	return "unknown"
+}
+
+// Privilege — действие, доступное с определённого уровня доверия.
+type Privilege string
+
+const (
+	PrivilegePostLinks     Privilege = "post_links"
+	PrivilegeCreateTags    Privilege = "create_tags"
+	PrivilegeEditWiki      Privilege = "edit_wiki"
+	PrivilegeWeightedFlags Privilege = "weighted_flags" // жалобы весят больше при подсчёте порога автоскрытия
+)
+
+// ReputationStats — из чего складывается репутация.
+type ReputationStats struct {
+	LikesReceived   int64
+	AcceptedAnswers int64 // ответы, принятые автором чужого топика
+	SurvivedPosts   int64 // посты, опубликованные дольше периода проверки и не снятые модератором
+	UpheldReports   int64 // кейсы против пользователя, закрытые с hide, delete, warn или reject
+}
+
+type Reputation struct {
+	UserID        int64
+	Stats         ReputationStats
+	Score         int64
+	ComputedLevel TrustLevel // по Score
+	Level         TrustLevel // действующий: ComputedLevel или Override
+	Override      *TrustOverride
+}
+
+// TrustOverride — уровень, назначенный администратором вместо вычисленного.
+type TrustOverride struct {
+	UserID int64
+	Level  TrustLevel
+	Reason string
+	SetBy  int64
+	SetAt  time.Time
+}
@@ -0,0 +1,24 @@
+package entity
+
+// Viewer — тот, кто читает топик или пост. Для анонимов используется SessionID,
+// а если его нет — отпечаток из ClientIP и UserAgent.
+type Viewer struct {
+	UserID    int64
+	SessionID string
+	ClientIP  string
+	UserAgent string
+	Moderator bool
+}
+
+// CanSee — может ли зритель видеть контент автора authorID в статусе s.
+// Неопубликованный контент видят автор и модераторы, скрытый — только модераторы.
+func (v Viewer) CanSee(authorID int64, s Status) bool {
+	switch s {
+	case StatusActive:
+		return true
+	case StatusPending, StatusRejected:
+		return v.Moderator || (v.UserID != 0 && v.UserID == authorID)
+	default:
+		return v.Moderator
+	}
+}
@@ -0,0 +1,61 @@
+package entity
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+type Webhook struct {
+	ID                  int64
+	URL                 string
+	Secret              string      // ключ HMAC-подписи, наружу не отдаётся после создания
+	EventTypes          []EventType // пусто — все события
+	CategoryIDs         []int64     // пусто — все категории
+	Active              bool
+	ConsecutiveFailures int
+	DeadAt              *time.Time // не nil — вебхук в dead-letter
+	CreatedBy           int64
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+func (w *Webhook) IsDead() bool {
+	return w.DeadAt != nil
+}
+
+// Matches сообщает, подписан ли вебхук на событие. categoryID = 0 — категория неизвестна,
+// такое событие получают только вебхуки без фильтра по категориям.
+func (w *Webhook) Matches(eventType EventType, categoryID int64) bool {
+	if len(w.EventTypes) > 0 && !slices.Contains(w.EventTypes, eventType) {
+		return false
+	}
+	if len(w.CategoryIDs) > 0 && !slices.Contains(w.CategoryIDs, categoryID) {
+		return false
+	}
+	return true
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryFailed    DeliveryStatus = "failed" // попытки исчерпаны
+	DeliveryDead      DeliveryStatus = "dead"   // вебхук ушёл в dead-letter
+)
+
+type WebhookDelivery struct {
+	ID             int64
+	WebhookID      int64
+	EventID        int64
+	EventType      EventType
+	Payload        json.RawMessage // тело запроса — событие целиком
+	Status         DeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode int
+	LastError      string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
@@ -0,0 +1,104 @@
+// Package events доставляет доменные события из outbox во внешние приёмники.
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+// Sink — приёмник событий. Deliver получает пачку по возрастанию id;
+// при ошибке пачка будет доставлена повторно, поэтому приёмник должен терпеть дубли.
+type Sink interface {
+	Name() string
+	Deliver(ctx context.Context, events []*entity.Event) error
+}
+
+// Dispatcher переносит события из outbox во все приёмники и отмечает их доставленными.
+// Гарантия — at-least-once: событие считается доставленным, только когда его приняли все приёмники.
+// Пачка забирается с блокировкой строк, поэтому диспетчеры нескольких реплик не доставляют одно и то же;
+// приёмники, которые пишут в базу (вебхуки), делают это в той же транзакции.
+type Dispatcher struct {
+	outbox    repository.OutboxRepository
+	tx        repository.Transactor
+	sinks     []Sink
+	batchSize int
+	logger    *slog.Logger
+}
+
+func NewDispatcher(outbox repository.OutboxRepository, tx repository.Transactor, batchSize int, logger *slog.Logger, sinks ...Sink) *Dispatcher {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	return &Dispatcher{
+		outbox:    outbox,
+		tx:        tx,
+		sinks:     sinks,
+		batchSize: batchSize,
+		logger:    logger,
+	}
+}
+
+// Dispatch доставляет все накопившиеся события. Подходит для worker.Run.
+func (d *Dispatcher) Dispatch(ctx context.Context) error {
+	for {
+		n, err := d.dispatchBatch(ctx)
+		if err != nil {
+			return err
+		}
+		if n < d.batchSize {
+			return nil
+		}
+	}
+}
+
+func (d *Dispatcher) dispatchBatch(ctx context.Context) (int, error) {
+	var n int
+	err := d.tx.WithinTx(ctx, func(ctx context.Context) error {
+		batch, err := d.outbox.ListPending(ctx, d.batchSize)
+		if err != nil || len(batch) == 0 {
+			return err
+		}
+		n = len(batch)
+
+		for _, sink := range d.sinks {
+			if err := sink.Deliver(ctx, batch); err != nil {
+				return fmt.Errorf("sink %s: %w", sink.Name(), err)
+			}
+		}
+
+		ids := make([]int64, len(batch))
+		for i, e := range batch {
+			ids[i] = e.ID
+		}
+		return d.outbox.MarkDispatched(ctx, ids, time.Now().UTC())
+	})
+	return n, err
+}
+
+// Cleanup удаляет доставленные события старше retention. Возобновить подписку
+// с токена старше retention уже нельзя — такие события пропадут.
+func (d *Dispatcher) Cleanup(ctx context.Context, retention time.Duration) error {
+	before := time.Now().UTC().Add(-retention)
+
+	var total int64
+	for {
+		n, err := d.outbox.DeleteDispatched(ctx, before, d.batchSize*10)
+		if err != nil {
+			return err
+		}
+		total += n
+		if n < int64(d.batchSize*10) {
+			break
+		}
+	}
+
+	if total > 0 {
+		d.logger.Info("outbox cleaned up", slog.Int64("deleted", total))
+	}
+	return nil
+}
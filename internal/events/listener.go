@@ -0,0 +1,118 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+	"github.com/lib/pq"
+)
+
+// Channel — канал NOTIFY, в который триггер на outbox_events пишет id события.
+const Channel = "forum_events"
+
+// listenBatch — сколько событий читается из outbox за раз.
+const listenBatch = 500
+
+// Consumer получает события по возрастанию курсора. Publish не должен блокироваться.
+type Consumer interface {
+	Publish(events []*entity.Event)
+}
+
+// Listener раздаёт события outbox потребителям внутри реплики. NOTIFY приходит при коммите
+// на любой реплике и служит только сигналом: события читаются по курсору через ListAfter,
+// поэтому порядок совпадает с порядком возобновления подписки, а после разрыва соединения
+// ничего не теряется. Раз в poll outbox перечитывается и без сигнала — на случай, когда
+// события придерживала чужая долгая транзакция.
+type Listener struct {
+	outbox    repository.OutboxRepository
+	consumers []Consumer
+	poll      time.Duration
+	logger    *slog.Logger
+
+	cursor entity.EventCursor
+}
+
+func NewListener(outbox repository.OutboxRepository, poll time.Duration, logger *slog.Logger, consumers ...Consumer) *Listener {
+	if poll <= 0 {
+		poll = 5 * time.Second
+	}
+	return &Listener{
+		outbox:    outbox,
+		consumers: consumers,
+		poll:      poll,
+		logger:    logger,
+	}
+}
+
+// Run слушает NOTIFY и раздаёт новые события, пока не отменён ctx.
+// Раздаются только события, закоммиченные после запуска.
+func (l *Listener) Run(ctx context.Context, dsn string) error {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			l.logger.Warn("events listener event", slog.Int("event", int(ev)), slog.String("err", err.Error()))
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(Channel); err != nil {
+		return err
+	}
+	// Курсор берётся после LISTEN: всё, что закоммитят позже, придёт сигналом или при опросе
+	head, err := l.outbox.Head(ctx)
+	if err != nil {
+		return err
+	}
+	l.cursor = head
+	l.logger.Info("events listener started", slog.String("channel", Channel))
+
+	poll := time.NewTicker(l.poll)
+	defer poll.Stop()
+	ping := time.NewTicker(time.Minute)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ping.C:
+			go listener.Ping()
+		case <-poll.C:
+			l.fetch(ctx)
+		case <-listener.Notify:
+			// Сигналы, накопившиеся за время чтения, покрываются одним проходом
+		drain:
+			for {
+				select {
+				case <-listener.Notify:
+				default:
+					break drain
+				}
+			}
+			l.fetch(ctx)
+		}
+	}
+}
+
+// fetch читает outbox от курсора, пока пачки не кончатся.
+func (l *Listener) fetch(ctx context.Context) {
+	for {
+		batch, err := l.outbox.ListAfter(ctx, l.cursor, listenBatch)
+		if err != nil {
+			l.logger.Error("failed to read outbox events", slog.String("err", err.Error()))
+			return
+		}
+		if len(batch) == 0 {
+			return
+		}
+		for _, c := range l.consumers {
+			c.Publish(batch)
+		}
+		l.cursor = batch[len(batch)-1].Cursor()
+		if len(batch) < listenBatch {
+			return
+		}
+	}
+}
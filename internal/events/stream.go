@@ -0,0 +1,156 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+var (
+	ErrInvalidResumeToken = errors.New("invalid resume token")
+	// ErrSubscriberLagged — подписчик не успевал читать и был отключён; переподключиться с последним токеном.
+	ErrSubscriberLagged = errors.New("subscriber is too slow")
+)
+
+// replayBatch — сколько событий читается из outbox за раз при возобновлении.
+const replayBatch = 500
+
+// Stream раздаёт события server-streaming подписчикам (WatchEvents). Живые события приходят
+// от Listener, поэтому подписчики каждой реплики видят изменения, сделанные на любой другой.
+// Они раздаются в буферизованные каналы; медленный подписчик отключается,
+// а не тормозит доставку остальным.
+type Stream struct {
+	outbox repository.OutboxRepository
+	buffer int
+
+	mu   sync.Mutex
+	subs map[chan *entity.Event]struct{}
+}
+
+func NewStream(outbox repository.OutboxRepository, buffer int) *Stream {
+	if buffer <= 0 {
+		buffer = 256
+	}
+	return &Stream{
+		outbox: outbox,
+		buffer: buffer,
+		subs:   make(map[chan *entity.Event]struct{}),
+	}
+}
+
+// Publish раздаёт события подписчикам; вызывается Listener.
+func (s *Stream) Publish(events []*entity.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch := range s.subs {
+		if !push(ch, events) {
+			delete(s.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// push не блокируется: если буфер подписчика переполнен, возвращает false.
+func push(ch chan *entity.Event, events []*entity.Event) bool {
+	for _, e := range events {
+		select {
+		case ch <- e:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ResumeToken — токен, с которого подписчик продолжит после события e.
+func ResumeToken(e *entity.Event) string {
+	return strconv.FormatInt(e.TxID, 10) + "-" + strconv.FormatInt(e.ID, 10)
+}
+
+func parseResumeToken(token string) (entity.EventCursor, error) {
+	txID, id, ok := strings.Cut(token, "-")
+	if !ok {
+		return entity.EventCursor{}, ErrInvalidResumeToken
+	}
+	var c entity.EventCursor
+	var errTx, errID error
+	c.TxID, errTx = strconv.ParseInt(txID, 10, 64)
+	c.ID, errID = strconv.ParseInt(id, 10, 64)
+	if errTx != nil || errID != nil || c.TxID < 0 || c.ID < 0 {
+		return entity.EventCursor{}, ErrInvalidResumeToken
+	}
+	return c, nil
+}
+
+// Watch отправляет в send события после resumeToken, а затем новые, пока не отменён ctx.
+// С пустым токеном отдаются только новые события. Доставка at-least-once:
+// на стыке истории и живого потока возможны повторы, клиент дедуплицирует по id.
+func (s *Stream) Watch(ctx context.Context, resumeToken string, send func(*entity.Event) error) error {
+	ch := s.subscribe()
+	defer s.unsubscribe(ch)
+
+	if resumeToken != "" {
+		after, err := parseResumeToken(resumeToken)
+		if err != nil {
+			return err
+		}
+		if err := s.replay(ctx, after, send); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e, ok := <-ch:
+			if !ok {
+				return ErrSubscriberLagged
+			}
+			if err := send(e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Stream) replay(ctx context.Context, after entity.EventCursor, send func(*entity.Event) error) error {
+	for {
+		batch, err := s.outbox.ListAfter(ctx, after, replayBatch)
+		if err != nil {
+			return err
+		}
+		for _, e := range batch {
+			if err := send(e); err != nil {
+				return err
+			}
+			after = e.Cursor()
+		}
+		if len(batch) < replayBatch {
+			return nil
+		}
+	}
+}
+
+func (s *Stream) subscribe() chan *entity.Event {
+	ch := make(chan *entity.Event, s.buffer)
+	s.mu.Lock()
+	s.subs[ch] = struct{}{}
+	s.mu.Unlock()
+	return ch
+}
+
+func (s *Stream) unsubscribe(ch chan *entity.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subs[ch]; ok {
+		delete(s.subs, ch)
+		close(ch)
+	}
+}
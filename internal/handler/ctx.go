@@ -2,10 +2,18 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"net"
 	"strconv"
+	"strings"
 
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 // GetUserIDFromCtx извлекает user_id из gRPC metadata.
@@ -28,6 +36,93 @@ func GetUserIDFromCtx(ctx context.Context) int64 {
 	return userID
 }
 
+// GetAuthorIDFromCtx возвращает автора создаваемого контента из metadata user_id.
+// author_id из запроса допускается только пустым или совпадающим с ним — писать от чужого имени нельзя.
+func GetAuthorIDFromCtx(ctx context.Context, claimed int64) (int64, error) {
+	userID := GetUserIDFromCtx(ctx)
+	if userID == 0 {
+		return 0, status.Error(codes.Unauthenticated, "user is not authenticated")
+	}
+	if claimed != 0 && claimed != userID {
+		return 0, status.Error(codes.PermissionDenied, "author_id does not match the caller")
+	}
+	return userID, nil
+}
+
+// GetViewerFromCtx собирает данные о зрителе из gRPC metadata.
+// IP и user-agent клиента берутся из заголовков шлюза, а при их отсутствии — из самого соединения.
+func GetViewerFromCtx(ctx context.Context) entity.Viewer {
+	viewer := entity.Viewer{
+		UserID:    GetUserIDFromCtx(ctx),
+		SessionID: firstMetadataValue(ctx, "session_id"),
+		ClientIP:  firstMetadataValue(ctx, "x-forwarded-for"),
+		UserAgent: firstMetadataValue(ctx, "x-user-agent"),
+		Moderator: isModeratorRole(firstMetadataValue(ctx, "role")),
+	}
+
+	// x-forwarded-for может содержать цепочку прокси — клиент первый
+	if ip, _, found := strings.Cut(viewer.ClientIP, ","); found {
+		viewer.ClientIP = strings.TrimSpace(ip)
+	}
+	if viewer.ClientIP == "" {
+		if p, ok := peer.FromContext(ctx); ok {
+			if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+				viewer.ClientIP = host
+			}
+		}
+	}
+	if viewer.UserAgent == "" {
+		viewer.UserAgent = firstMetadataValue(ctx, "user-agent")
+	}
+	return viewer
+}
+
+// GetEditReasonFromCtx — необязательная причина правки из metadata: в запросах правки для неё пока нет поля.
+func GetEditReasonFromCtx(ctx context.Context) string {
+	return firstMetadataValue(ctx, "edit-reason")
+}
+
+// GetExpectedVersionFromCtx читает из metadata if-match версию, которую клиент видел перед правкой:
+// "7", 7 или * (без проверки, как и отсутствие заголовка). В запросах правки для неё пока нет поля.
+func GetExpectedVersionFromCtx(ctx context.Context) (int64, error) {
+	v := strings.TrimSpace(firstMetadataValue(ctx, "if-match"))
+	if v == "" || v == "*" {
+		return 0, nil
+	}
+	version, err := strconv.ParseInt(strings.Trim(v, `"`), 10, 64)
+	if err != nil || version <= 0 {
+		return 0, status.Error(codes.InvalidArgument, "if-match must be a version number")
+	}
+	return version, nil
+}
+
+// mergeVersion — версия для правки, собранной хендлером поверх прочитанной записи: без if-match
+// правка всё равно не должна затереть то, что изменили после чтения.
+func mergeVersion(expected, read int64) int64 {
+	if expected != 0 {
+		return expected
+	}
+	return read
+}
+
+// setVersionHeader отдаёт текущую версию в заголовке ответа etag — её клиент пришлёт в if-match.
+func setVersionHeader(ctx context.Context, version int64) {
+	_ = grpc.SetHeader(ctx, metadata.Pairs("etag", fmt.Sprintf("%q", strconv.FormatInt(version, 10))))
+}
+
+func firstMetadataValue(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
 func GetUserNicknameFromCtx(ctx context.Context) string {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
@@ -41,3 +136,12 @@ func GetUserNicknameFromCtx(ctx context.Context) string {
 	slog.Info("ctx nickname", "nickname", nicks[0])
 	return nicks[0]
 }
+
+// isModeratorRole — роли из metadata шлюза, которым виден неопубликованный и скрытый контент.
+func isModeratorRole(role string) bool {
+	switch strings.ToLower(role) {
+	case "moderator", "admin":
+		return true
+	}
+	return false
+}
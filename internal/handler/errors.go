@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/VaneZ444/forum-service/internal/usecase"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// toStatusError переводит доменные ошибки юзкейсов в gRPC-статусы.
+// Неизвестные ошибки возвращаются как есть.
+func toStatusError(err error) error {
+	var rateErr *usecase.RateLimitError
+	if errors.As(err, &rateErr) {
+		return rateLimitStatus(rateErr)
+	}
+
+	switch {
+	case errors.Is(err, usecase.ErrTopicLocked):
+		return status.Error(codes.FailedPrecondition, "topic is locked")
+	case errors.Is(err, usecase.ErrTopicNotLocked):
+		return status.Error(codes.FailedPrecondition, "topic is not locked")
+	case errors.Is(err, usecase.ErrInvalidTopicMove):
+		return status.Error(codes.FailedPrecondition, "topic cannot be moved")
+	case errors.Is(err, usecase.ErrInvalidSplit):
+		return status.Error(codes.InvalidArgument, "invalid topic split")
+	case errors.Is(err, usecase.ErrCategoryCycle):
+		return status.Error(codes.FailedPrecondition, "category cannot be moved into its own subtree")
+	case errors.Is(err, usecase.ErrCategoryHasChildren):
+		return status.Error(codes.FailedPrecondition, "category has subcategories, move or delete them first")
+	case errors.Is(err, usecase.ErrCategoryTooDeep):
+		return status.Error(codes.FailedPrecondition, "category nesting is too deep")
+	case errors.Is(err, usecase.ErrInvalidCategoryOrder):
+		return status.Error(codes.InvalidArgument, "order must list every sibling category exactly once")
+	case errors.Is(err, usecase.ErrInvalidRankingPeriod):
+		return status.Error(codes.InvalidArgument, "invalid ranking period")
+	case errors.Is(err, usecase.ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, "user is not authenticated")
+	case errors.Is(err, usecase.ErrInvalidSubscriptionTarget):
+		return status.Error(codes.InvalidArgument, "invalid subscription target")
+	case errors.Is(err, usecase.ErrSubscriptionNotFound):
+		return status.Error(codes.NotFound, "subscription not found")
+	case errors.Is(err, usecase.ErrTagNotFound):
+		return status.Error(codes.NotFound, "tag not found")
+	case errors.Is(err, usecase.ErrWebhookNotFound):
+		return status.Error(codes.NotFound, "webhook not found")
+	case errors.Is(err, usecase.ErrWebhookDeliveryNotFound):
+		return status.Error(codes.NotFound, "webhook delivery not found")
+	case errors.Is(err, usecase.ErrInvalidWebhook):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrWebhookDead):
+		return status.Error(codes.FailedPrecondition, "webhook is in dead-letter state, reactivate it first")
+	case errors.Is(err, usecase.ErrInvalidReport):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrAlreadyReported):
+		return status.Error(codes.AlreadyExists, "content already reported by this user")
+	case errors.Is(err, usecase.ErrCaseNotFound):
+		return status.Error(codes.NotFound, "moderation case not found")
+	case errors.Is(err, usecase.ErrCaseClaimed):
+		return status.Error(codes.FailedPrecondition, "moderation case is claimed by another moderator")
+	case errors.Is(err, usecase.ErrCaseResolved):
+		return status.Error(codes.FailedPrecondition, "moderation case is already resolved")
+	case errors.Is(err, usecase.ErrNotPending):
+		return status.Error(codes.FailedPrecondition, "content is not awaiting approval")
+	case errors.Is(err, usecase.ErrUserBanned), errors.Is(err, usecase.ErrUserMuted):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, usecase.ErrInvalidSanction):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrSanctionNotFound):
+		return status.Error(codes.NotFound, "sanction not found")
+	case errors.Is(err, usecase.ErrSanctionInactive):
+		return status.Error(codes.FailedPrecondition, "sanction is already lifted or expired")
+	case errors.Is(err, usecase.ErrInsufficientTrust), errors.Is(err, usecase.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, usecase.ErrInvalidTrustLevel), errors.Is(err, usecase.ErrInvalidAnswer):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrRevisionNotFound):
+		return status.Error(codes.NotFound, "revision not found")
+	case errors.Is(err, usecase.ErrInvalidRevision):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrVersionMismatch):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, usecase.ErrInvalidIdempotencyKey):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrIdempotencyKeyReused):
+		return status.Error(codes.AlreadyExists, "idempotency key was already used for a different request")
+	case errors.Is(err, usecase.ErrIdempotencyInProgress):
+		return status.Error(codes.Aborted, "request with this idempotency key is still in progress, retry later")
+	case errors.Is(err, usecase.ErrSpamSuspected):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrDraftNotFound):
+		return status.Error(codes.NotFound, "draft not found")
+	case errors.Is(err, usecase.ErrInvalidDraft):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrTooManyDrafts):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, usecase.ErrDraftPublishing):
+		return status.Error(codes.Aborted, "draft is already being published")
+	case errors.Is(err, usecase.ErrTrustOverrideNotFound):
+		return status.Error(codes.NotFound, "trust level override not found")
+	case errors.Is(err, usecase.ErrCategoryNotFound):
+		return status.Error(codes.NotFound, "category not found")
+	case errors.Is(err, usecase.ErrTopicNotFound):
+		return status.Error(codes.NotFound, "topic not found")
+	case errors.Is(err, usecase.ErrPostNotFound):
+		return status.Error(codes.NotFound, "post not found")
+	case errors.Is(err, usecase.ErrCommentNotFound):
+		return status.Error(codes.NotFound, "comment not found")
+	default:
+		return err
+	}
+}
+
+// rateLimitStatus отдаёт ResourceExhausted с RetryInfo, чтобы клиент знал, когда повторить запрос.
+func rateLimitStatus(err *usecase.RateLimitError) error {
+	st := status.New(codes.ResourceExhausted, err.Error())
+	detailed, derr := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(err.RetryAfter)})
+	if derr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
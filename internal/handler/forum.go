@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/events"
+	"github.com/VaneZ444/forum-service/internal/live"
 	"github.com/VaneZ444/forum-service/internal/usecase"
 	forumv1 "github.com/VaneZ444/golang-forum-protos/gen/go/forum"
 	"github.com/gosimple/slug"
@@ -23,7 +25,34 @@ type ForumHandler struct {
 	postUC     usecase.PostUseCase
 	commentUC  usecase.CommentUseCase
 	tagUC      usecase.TagUseCase
-	logger     *slog.Logger
+	topicViews usecase.TopicViewCounter
+	readUC     usecase.ReadUseCase
+	limiter    usecase.RateLimiter
+	// idempotencyUC делает CreateTopic, CreatePost и CreateComment идемпотентными по ключу из metadata
+	idempotencyUC usecase.IdempotencyUseCase
+	logger        *slog.Logger
+
+	// RPC для подписок и уведомлений ждут новой версии golang-forum-protos
+	subscriptionUC usecase.SubscriptionUseCase
+	notificationUC usecase.NotificationUseCase
+	// WatchEvents ждёт новой версии golang-forum-protos
+	eventStream *events.Stream
+	// Admin RPC для вебхуков ждут новой версии golang-forum-protos
+	webhookUC usecase.WebhookUseCase
+	// WatchTopic ждёт новой версии golang-forum-protos
+	liveHub *live.Hub
+	// RPC жалоб и очереди модерации ждут новой версии golang-forum-protos
+	moderationUC usecase.ModerationUseCase
+	// ListAuditLog ждёт новой версии golang-forum-protos
+	auditUC usecase.AuditUseCase
+	// RPC банов и мутов ждут новой версии golang-forum-protos
+	sanctionUC usecase.SanctionUseCase
+	// RPC репутации и уровней доверия ждут новой версии golang-forum-protos
+	trustUC usecase.TrustUseCase
+	// RPC истории правок ждут новой версии golang-forum-protos
+	revisionUC usecase.RevisionUseCase
+	// RPC черновиков ждут новой версии golang-forum-protos
+	draftUC usecase.DraftUseCase
 }
 
 func NewForumHandler(
@@ -32,6 +61,21 @@ func NewForumHandler(
 	postUC usecase.PostUseCase,
 	commentUC usecase.CommentUseCase,
 	tagUC usecase.TagUseCase,
+	topicViews usecase.TopicViewCounter,
+	readUC usecase.ReadUseCase,
+	subscriptionUC usecase.SubscriptionUseCase,
+	notificationUC usecase.NotificationUseCase,
+	eventStream *events.Stream,
+	webhookUC usecase.WebhookUseCase,
+	liveHub *live.Hub,
+	moderationUC usecase.ModerationUseCase,
+	auditUC usecase.AuditUseCase,
+	sanctionUC usecase.SanctionUseCase,
+	trustUC usecase.TrustUseCase,
+	revisionUC usecase.RevisionUseCase,
+	draftUC usecase.DraftUseCase,
+	limiter usecase.RateLimiter,
+	idempotencyUC usecase.IdempotencyUseCase,
 	logger *slog.Logger,
 ) *ForumHandler {
 	return &ForumHandler{
@@ -40,7 +84,24 @@ func NewForumHandler(
 		postUC:     postUC,
 		commentUC:  commentUC,
 		tagUC:      tagUC,
+		topicViews: topicViews,
+		readUC:     readUC,
+		limiter:    limiter,
 		logger:     logger,
+
+		idempotencyUC: idempotencyUC,
+
+		subscriptionUC: subscriptionUC,
+		notificationUC: notificationUC,
+		eventStream:    eventStream,
+		webhookUC:      webhookUC,
+		liveHub:        liveHub,
+		moderationUC:   moderationUC,
+		auditUC:        auditUC,
+		sanctionUC:     sanctionUC,
+		trustUC:        trustUC,
+		revisionUC:     revisionUC,
+		draftUC:        draftUC,
 	}
 }
 
@@ -52,7 +113,7 @@ func (h *ForumHandler) CreateCategory(ctx context.Context, req *forumv1.CreateCa
 		Description: req.GetDescription(),
 	}
 
-	createdCategory, err := h.categoryUC.CreateCategory(ctx, category)
+	createdCategory, err := h.categoryUC.CreateCategory(ctx, category, GetUserIDFromCtx(ctx))
 	if err != nil {
 		h.logger.Error("failed to create category", "error", err)
 		return nil, err
@@ -66,6 +127,7 @@ func (h *ForumHandler) GetCategory(ctx context.Context, req *forumv1.GetCategory
 		h.logger.Error("failed to get category", "error", err)
 		return nil, err
 	}
+	setVersionHeader(ctx, category.Version)
 	return &forumv1.CategoryResponse{Category: toProtoCategory(category)}, nil
 }
 
@@ -97,6 +159,10 @@ func (h *ForumHandler) ListCategories(ctx context.Context, req *forumv1.ListCate
 
 func (h *ForumHandler) UpdateCategory(ctx context.Context, req *forumv1.UpdateCategoryRequest) (*forumv1.CategoryResponse, error) {
 	h.logger.Info("updating category", "id", req.GetId())
+	expected, err := GetExpectedVersionFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	// 1) Берём текущую версию
 	existing, err := h.categoryUC.GetByID(ctx, req.GetId())
@@ -134,40 +200,55 @@ func (h *ForumHandler) UpdateCategory(ctx context.Context, req *forumv1.UpdateCa
 	// 4) Собираем сущность для апдейта
 	cat := &entity.Category{
 		ID:          existing.ID,
+		ParentID:    existing.ParentID,
+		Position:    existing.Position,
 		Title:       title,
 		Slug:        newSlug,
 		Description: description,
 		CreatedAt:   existing.CreatedAt,
 		UpdatedAt:   time.Now().UTC(),
+		Version:     mergeVersion(expected, existing.Version),
 	}
 
 	// 5) Апдейт
-	updated, err := h.categoryUC.UpdateCategory(ctx, cat)
+	updated, err := h.categoryUC.UpdateCategory(ctx, cat, GetUserIDFromCtx(ctx))
 	if err != nil {
 		h.logger.Error("update category failed", "error", err)
-		return nil, err
+		return nil, toStatusError(err)
 	}
 
+	setVersionHeader(ctx, updated.Version)
 	return &forumv1.CategoryResponse{Category: toProtoCategory(updated)}, nil
 }
 
 func (h *ForumHandler) DeleteCategory(ctx context.Context, req *forumv1.DeleteCategoryRequest) (*forumv1.Empty, error) {
-	err := h.categoryUC.DeleteCategory(ctx, req.GetId())
+	err := h.categoryUC.DeleteCategory(ctx, req.GetId(), GetUserIDFromCtx(ctx))
 	if err != nil {
 		h.logger.Error("failed to delete category", "error", err)
-		return nil, err
+		return nil, toStatusError(err)
 	}
 	return &forumv1.Empty{}, nil
 }
 
 // ================== Topic Handlers ==================
 func (h *ForumHandler) CreateTopic(ctx context.Context, req *forumv1.CreateTopicRequest) (*forumv1.TopicResponse, error) {
+	return idempotent(ctx, h, "CreateTopic", req, &forumv1.TopicResponse{}, func() (*forumv1.TopicResponse, error) {
+		return h.createTopic(ctx, req)
+	})
+}
+
+func (h *ForumHandler) createTopic(ctx context.Context, req *forumv1.CreateTopicRequest) (*forumv1.TopicResponse, error) {
 	h.logger.Info("creating topic", "title", req.GetTitle())
 
+	authorID, err := GetAuthorIDFromCtx(ctx, req.GetAuthorId())
+	if err != nil {
+		return nil, err
+	}
+
 	// Create topic entity
 	topic := &entity.Topic{
 		Title:          req.GetTitle(),
-		AuthorID:       req.GetAuthorId(),
+		AuthorID:       authorID,
 		AuthorNickname: GetUserNicknameFromCtx(ctx),
 		CategoryID:     req.GetCategoryId(),
 	}
@@ -176,14 +257,14 @@ func (h *ForumHandler) CreateTopic(ctx context.Context, req *forumv1.CreateTopic
 	post := &entity.Post{
 		Title:          req.GetTitle(),
 		Content:        req.GetContent(),
-		AuthorID:       req.GetAuthorId(),
+		AuthorID:       authorID,
 		AuthorNickname: GetUserNicknameFromCtx(ctx),
 	}
 
 	topicID, postID, err := h.topicUC.CreateTopic(ctx, topic, post)
 	if err != nil {
 		h.logger.Error("failed to create topic", "error", err)
-		return nil, err
+		return nil, toStatusError(err)
 	}
 
 	// Set IDs for response
@@ -198,11 +279,19 @@ func (h *ForumHandler) CreateTopic(ctx context.Context, req *forumv1.CreateTopic
 }
 
 func (h *ForumHandler) GetTopic(ctx context.Context, req *forumv1.GetTopicRequest) (*forumv1.TopicResponse, error) {
-	topic, firstPost, err := h.topicUC.GetByID(ctx, req.GetId())
+	viewer := GetViewerFromCtx(ctx)
+	topic, firstPost, err := h.topicUC.GetByID(ctx, req.GetId(), viewer)
 	if err != nil {
 		h.logger.Error("failed to get topic", "error", err)
 		return nil, err
 	}
+	if topic.Status == entity.StatusActive {
+		h.topicViews.RecordView(topic.ID, viewer)
+	}
+	if firstPost != nil {
+		h.markRead(ctx, topic.ID, firstPost.ID)
+	}
+	setVersionHeader(ctx, topic.Version)
 	return &forumv1.TopicResponse{
 		Topic:     toProtoTopic(topic),
 		FirstPost: toProtoPost(firstPost),
@@ -211,9 +300,13 @@ func (h *ForumHandler) GetTopic(ctx context.Context, req *forumv1.GetTopicReques
 
 func (h *ForumHandler) UpdateTopic(ctx context.Context, req *forumv1.UpdateTopicRequest) (*forumv1.TopicResponse, error) {
 	h.logger.Info("updating topic", "id", req.GetId())
+	expected, err := GetExpectedVersionFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	// 1) Берём текущий топик
-	existing, _, err := h.topicUC.GetByID(ctx, req.GetId())
+	existing, _, err := h.topicUC.GetByID(ctx, req.GetId(), GetViewerFromCtx(ctx))
 	if err != nil {
 		h.logger.Error("get topic failed", "error", err)
 		return nil, err
@@ -246,14 +339,16 @@ func (h *ForumHandler) UpdateTopic(ctx context.Context, req *forumv1.UpdateTopic
 		PostsCount:     existing.PostsCount,
 		ViewsCount:     existing.ViewsCount,
 		LastActivity:   time.Now().UTC(), // обновляем активность
+		Version:        mergeVersion(expected, existing.Version),
 	}
 
 	// 4) Апдейт
-	updated, err := h.topicUC.UpdateTopic(ctx, topic)
+	updated, err := h.topicUC.UpdateTopic(ctx, topic, GetViewerFromCtx(ctx))
 	if err != nil {
 		h.logger.Error("update topic failed", "error", err)
-		return nil, err
+		return nil, toStatusError(err)
 	}
+	setVersionHeader(ctx, updated.Version)
 
 	return &forumv1.TopicResponse{
 		Topic:     toProtoTopic(updated),
@@ -281,11 +376,14 @@ func (h *ForumHandler) ListTopics(ctx context.Context, req *forumv1.ListTopicsRe
 	}
 
 	// Вызываем юзкейс
-	topics, total, err := h.topicUC.List(ctx, categoryID, limit, offset, req.GetSorting())
+	topics, total, err := h.topicUC.List(ctx, categoryID, false, limit, offset, req.GetSorting())
 	if err != nil {
 		h.logger.Error("failed to list topics", "error", err)
 		return nil, err
 	}
+	if err := h.readUC.FillReadState(ctx, GetUserIDFromCtx(ctx), topics); err != nil {
+		h.logger.Warn("failed to fill read state", "error", err)
+	}
 
 	// Конвертируем в proto
 	protoTopics := make([]*forumv1.Topic, len(topics))
@@ -302,7 +400,7 @@ func (h *ForumHandler) ListTopics(ctx context.Context, req *forumv1.ListTopicsRe
 func (h *ForumHandler) DeleteTopic(ctx context.Context, req *forumv1.DeleteTopicRequest) (*forumv1.Empty, error) {
 	h.logger.Info("deleting topic", "id", req.GetId())
 
-	err := h.topicUC.DeleteTopic(ctx, req.GetId())
+	err := h.topicUC.DeleteTopic(ctx, req.GetId(), GetUserIDFromCtx(ctx))
 	if err != nil {
 		if errors.Is(err, usecase.ErrTopicNotFound) {
 			return nil, status.Error(codes.NotFound, "topic not found")
@@ -316,11 +414,22 @@ func (h *ForumHandler) DeleteTopic(ctx context.Context, req *forumv1.DeleteTopic
 
 // ================== Post Handlers ==================
 func (h *ForumHandler) CreatePost(ctx context.Context, req *forumv1.CreatePostRequest) (*forumv1.PostResponse, error) {
+	return idempotent(ctx, h, "CreatePost", req, &forumv1.PostResponse{}, func() (*forumv1.PostResponse, error) {
+		return h.createPost(ctx, req)
+	})
+}
+
+func (h *ForumHandler) createPost(ctx context.Context, req *forumv1.CreatePostRequest) (*forumv1.PostResponse, error) {
 	h.logger.Info("creating post", "topic_id", req.GetTopicId())
 
+	authorID, err := GetAuthorIDFromCtx(ctx, req.GetAuthorId())
+	if err != nil {
+		return nil, err
+	}
+
 	post := &entity.Post{
 		TopicID:        req.GetTopicId(),
-		AuthorID:       req.GetAuthorId(),
+		AuthorID:       authorID,
 		AuthorNickname: GetUserNicknameFromCtx(ctx),
 		Title:          req.GetTitle(),
 		Content:        req.GetContent(),
@@ -338,22 +447,26 @@ func (h *ForumHandler) CreatePost(ctx context.Context, req *forumv1.CreatePostRe
 	id, err := h.postUC.CreatePost(ctx, post)
 	if err != nil {
 		h.logger.Error("failed to create post", "error", err)
-		return nil, err
+		return nil, toStatusError(err)
 	}
 	post.ID = id
 	return &forumv1.PostResponse{Post: toProtoPost(post)}, nil
 }
 
 func (h *ForumHandler) GetPost(ctx context.Context, req *forumv1.GetPostRequest) (*forumv1.PostResponse, error) {
-	post, err := h.postUC.GetPostByID(ctx, req.GetId())
+	viewer := GetViewerFromCtx(ctx)
+	post, err := h.postUC.GetPostByID(ctx, req.GetId(), viewer)
 	if err != nil {
 		h.logger.Error("failed to get post", "error", err)
 		return nil, err
 	}
-	userID := GetUserIDFromCtx(ctx)
-	if err := h.postUC.AddView(ctx, req.GetId(), userID); err != nil {
-		h.logger.Warn("failed to add post view", "error", err)
+	if post.Status == entity.StatusActive {
+		if err := h.postUC.AddView(ctx, req.GetId(), viewer); err != nil {
+			h.logger.Warn("failed to add post view", "error", err)
+		}
 	}
+	h.markRead(ctx, post.TopicID, post.ID)
+	setVersionHeader(ctx, post.Version)
 	return &forumv1.PostResponse{Post: toProtoPost(post)}, nil
 }
 
@@ -381,6 +494,13 @@ func (h *ForumHandler) ListPosts(ctx context.Context, req *forumv1.ListPostsRequ
 		h.logger.Error("failed to list posts", "error", err)
 		return nil, err
 	}
+	if topicID > 0 {
+		var lastID int64
+		for _, p := range posts {
+			lastID = max(lastID, p.ID)
+		}
+		h.markRead(ctx, topicID, lastID)
+	}
 
 	protoPosts := make([]*forumv1.Post, len(posts))
 	for i, p := range posts {
@@ -395,25 +515,30 @@ func (h *ForumHandler) ListPosts(ctx context.Context, req *forumv1.ListPostsRequ
 
 func (h *ForumHandler) UpdatePost(ctx context.Context, req *forumv1.UpdatePostRequest) (*forumv1.PostResponse, error) {
 	h.logger.Info("updating post", "id", req.GetId())
+	expected, err := GetExpectedVersionFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	post, err := h.postUC.UpdatePost(ctx, req)
+	post, err := h.postUC.UpdatePost(ctx, req, expected, GetViewerFromCtx(ctx), GetEditReasonFromCtx(ctx))
 	if err != nil {
 		if errors.Is(err, usecase.ErrPostNotFound) {
 			return nil, status.Error(codes.NotFound, "post not found")
 		}
-		return nil, status.Error(codes.Internal, "failed to update post")
+		return nil, toStatusError(err)
 	}
 
+	setVersionHeader(ctx, post.Version)
 	return &forumv1.PostResponse{Post: toProtoPost(post)}, nil
 }
 
 func (h *ForumHandler) DeletePost(ctx context.Context, req *forumv1.DeletePostRequest) (*forumv1.Empty, error) {
 	h.logger.Info("deleting post", "id", req.GetId())
 
-	err := h.postUC.DeletePost(ctx, req.GetId())
+	err := h.postUC.DeletePost(ctx, req.GetId(), GetViewerFromCtx(ctx))
 	if err != nil {
 		h.logger.Error("failed to delete post", "error", err)
-		return nil, err
+		return nil, toStatusError(err)
 	}
 
 	return &forumv1.Empty{}, nil
@@ -421,19 +546,30 @@ func (h *ForumHandler) DeletePost(ctx context.Context, req *forumv1.DeletePostRe
 
 // ================== Comment Handlers ==================
 func (h *ForumHandler) CreateComment(ctx context.Context, req *forumv1.CreateCommentRequest) (*forumv1.CommentResponse, error) {
+	return idempotent(ctx, h, "CreateComment", req, &forumv1.CommentResponse{}, func() (*forumv1.CommentResponse, error) {
+		return h.createComment(ctx, req)
+	})
+}
+
+func (h *ForumHandler) createComment(ctx context.Context, req *forumv1.CreateCommentRequest) (*forumv1.CommentResponse, error) {
 	h.logger.Info("creating comment", "post_id", req.GetPostId())
 
+	authorID, err := GetAuthorIDFromCtx(ctx, req.GetAuthorId())
+	if err != nil {
+		return nil, err
+	}
+
 	comment := &entity.Comment{
 		PostID:         req.GetPostId(),
-		AuthorID:       req.GetAuthorId(),
+		AuthorID:       authorID,
 		AuthorNickname: GetUserNicknameFromCtx(ctx),
 		Content:        req.GetContent(),
 	}
 
-	id, err := h.commentUC.CreateComment(ctx, comment)
+	id, err := h.commentUC.CreateComment(ctx, comment, GetViewerFromCtx(ctx))
 	if err != nil {
 		h.logger.Error("failed to create comment", "error", err)
-		return nil, err
+		return nil, toStatusError(err)
 	}
 	comment.ID = id
 
@@ -441,11 +577,12 @@ func (h *ForumHandler) CreateComment(ctx context.Context, req *forumv1.CreateCom
 }
 
 func (h *ForumHandler) GetComment(ctx context.Context, req *forumv1.GetCommentRequest) (*forumv1.CommentResponse, error) {
-	comment, err := h.commentUC.GetCommentByID(ctx, req.GetId())
+	comment, err := h.commentUC.GetCommentByID(ctx, req.GetId(), GetViewerFromCtx(ctx))
 	if err != nil {
 		h.logger.Error("failed to get comment", "error", err)
 		return nil, err
 	}
+	setVersionHeader(ctx, comment.Version)
 	return &forumv1.CommentResponse{Comment: toProtoComment(comment)}, nil
 }
 
@@ -476,9 +613,13 @@ func (h *ForumHandler) ListComments(ctx context.Context, req *forumv1.ListCommen
 }
 func (h *ForumHandler) UpdateComment(ctx context.Context, req *forumv1.UpdateCommentRequest) (*forumv1.CommentResponse, error) {
 	h.logger.Info("updating comment", "comment_id", req.GetId())
+	expected, err := GetExpectedVersionFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	// Fetch the existing comment (assuming you have a GetComment method in your use case)
-	existingComment, err := h.commentUC.GetCommentByID(ctx, req.GetId())
+	existingComment, err := h.commentUC.GetCommentByID(ctx, req.GetId(), GetViewerFromCtx(ctx))
 	if err != nil {
 		h.logger.Error("failed to fetch comment", "error", err)
 		return nil, err
@@ -486,22 +627,24 @@ func (h *ForumHandler) UpdateComment(ctx context.Context, req *forumv1.UpdateCom
 
 	// Update only the content
 	existingComment.Content = req.GetContent()
+	existingComment.Version = mergeVersion(expected, existingComment.Version)
 
 	// Persist the update (assuming you have an UpdateComment method in your use case)
-	err = h.commentUC.UpdateComment(ctx, existingComment)
+	err = h.commentUC.UpdateComment(ctx, existingComment, GetViewerFromCtx(ctx), GetEditReasonFromCtx(ctx))
 	if err != nil {
 		h.logger.Error("failed to update comment", "error", err)
-		return nil, err
+		return nil, toStatusError(err)
 	}
 
+	setVersionHeader(ctx, existingComment.Version)
 	return &forumv1.CommentResponse{Comment: toProtoComment(existingComment)}, nil
 }
 func (h *ForumHandler) DeleteComment(ctx context.Context, req *forumv1.DeleteCommentRequest) (*forumv1.Empty, error) {
 	h.logger.Info("deleting comment", "comment_id", req.GetId())
-	err := h.commentUC.DeleteComment(ctx, req.GetId())
+	err := h.commentUC.DeleteComment(ctx, req.GetId(), GetViewerFromCtx(ctx))
 	if err != nil {
 		h.logger.Error("failed to delete comment", "error", err)
-		return nil, err
+		return nil, toStatusError(err)
 	}
 	return &forumv1.Empty{}, nil
 }
@@ -514,10 +657,10 @@ func (h *ForumHandler) CreateTag(ctx context.Context, req *forumv1.CreateTagRequ
 		Name: req.GetName(),
 	}
 
-	err := h.tagUC.CreateTag(ctx, tag)
+	err := h.tagUC.CreateTag(ctx, tag, GetViewerFromCtx(ctx))
 	if err != nil {
 		h.logger.Error("failed to create tag", "error", err)
-		return nil, err
+		return nil, toStatusError(err)
 	}
 	return &forumv1.TagResponse{Tag: toProtoTag(tag)}, nil
 }
@@ -540,6 +683,7 @@ func (h *ForumHandler) GetTag(ctx context.Context, req *forumv1.GetTagRequest) (
 		h.logger.Error("failed to get tag", "error", err)
 		return nil, err
 	}
+	setVersionHeader(ctx, tag.Version)
 	return &forumv1.TagResponse{Tag: toProtoTag(tag)}, nil
 }
 
@@ -591,9 +735,9 @@ func (h *ForumHandler) ListTagsByPost(ctx context.Context, req *forumv1.ListTags
 func (h *ForumHandler) AddTagToPost(ctx context.Context, req *forumv1.AddTagToPostRequest) (*forumv1.Empty, error) {
 	h.logger.Info("adding tag to post", "post_id", req.GetPostId(), "tag_id", req.GetTagId())
 
-	if err := h.tagUC.AddTagToPost(ctx, req.GetPostId(), req.GetTagId()); err != nil {
+	if err := h.tagUC.AddTagToPost(ctx, req.GetPostId(), req.GetTagId(), GetViewerFromCtx(ctx)); err != nil {
 		h.logger.Error("failed to add tag to post", "error", err)
-		return nil, err
+		return nil, toStatusError(err)
 	}
 
 	return &forumv1.Empty{}, nil
@@ -602,9 +746,9 @@ func (h *ForumHandler) AddTagToPost(ctx context.Context, req *forumv1.AddTagToPo
 func (h *ForumHandler) RemoveTagFromPost(ctx context.Context, req *forumv1.RemoveTagFromPostRequest) (*forumv1.Empty, error) {
 	h.logger.Info("removing tag from post", "post_id", req.GetPostId(), "tag_id", req.GetTagId())
 
-	if err := h.tagUC.RemoveTagFromPost(ctx, req.GetPostId(), req.GetTagId()); err != nil {
+	if err := h.tagUC.RemoveTagFromPost(ctx, req.GetPostId(), req.GetTagId(), GetViewerFromCtx(ctx)); err != nil {
 		h.logger.Error("failed to remove tag from post", "error", err)
-		return nil, err
+		return nil, toStatusError(err)
 	}
 
 	return &forumv1.Empty{}, nil
@@ -643,6 +787,9 @@ func (h *ForumHandler) Search(ctx context.Context, req *forumv1.SearchRequest) (
 		limit = int(req.Pagination.GetLimit())
 		offset = int(req.Pagination.GetOffset())
 	}
+	if err := h.limiter.Allow(ctx, entity.RateSearch, usecase.ViewerSubject(GetViewerFromCtx(ctx))); err != nil {
+		return nil, toStatusError(err)
+	}
 
 	posts, totalPosts, err := h.postUC.SearchPosts(ctx, req.GetQuery(), limit, offset)
 	if err != nil {
@@ -744,3 +891,10 @@ func toProtoTag(t *entity.Tag) *forumv1.Tag {
 		Slug: t.Slug,
 	}
 }
+
+// markRead сдвигает маркер прочитанного для текущего пользователя; ошибки не прерывают чтение.
+func (h *ForumHandler) markRead(ctx context.Context, topicID, postID int64) {
+	if err := h.readUC.MarkRead(ctx, GetUserIDFromCtx(ctx), topicID, postID); err != nil {
+		h.logger.Warn("failed to mark topic read", "topic_id", topicID, "error", err)
+	}
+}
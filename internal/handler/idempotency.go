@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// idempotent выполняет create один раз на ключ из metadata idempotency-key; без ключа просто вызывает create.
+// Повтор получает сохранённый ответ и заголовок idempotent-replayed: true. В resp разбирается сохранённый ответ.
+func idempotent[T proto.Message](ctx context.Context, h *ForumHandler, method string, req proto.Message, resp T, create func() (T, error)) (T, error) {
+	key := firstMetadataValue(ctx, "idempotency-key")
+	if key == "" {
+		return create()
+	}
+
+	var zero T
+	// Deterministic: одинаковые запросы должны давать одинаковые байты, иначе повтор не совпадёт по хэшу
+	request, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return zero, err
+	}
+
+	var created T
+	data, replayed, err := h.idempotencyUC.Do(ctx, GetUserIDFromCtx(ctx), key, method, request, func() ([]byte, error) {
+		var err error
+		if created, err = create(); err != nil {
+			return nil, err
+		}
+		return proto.Marshal(created)
+	})
+	if err != nil {
+		return zero, toStatusError(err)
+	}
+	if !replayed {
+		return created, nil
+	}
+
+	if err := proto.Unmarshal(data, resp); err != nil {
+		return zero, err
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs("idempotent-replayed", "true"))
+	return resp, nil
+}
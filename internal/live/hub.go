@@ -0,0 +1,213 @@
+// Package live раздаёт изменения постов и комментариев подписчикам server-streaming RPC.
+// Hub — потребитель events.Listener: события приходят из outbox по курсору после сигнала
+// Postgres NOTIFY, поэтому каждая реплика видит изменения, сделанные на любой другой,
+// а после разрыва соединения слушатель дочитывает всё пропущенное.
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/events"
+)
+
+const (
+	UpdateHeartbeat = "heartbeat"
+	// UpdateResync — часть изменений пропущена из-за переполнения буфера;
+	// клиенту нужно перечитать топик через ListPosts/ListComments.
+	UpdateResync = "resync"
+)
+
+var ErrInvalidFilter = errors.New("exactly one of topic_id or post_id must be set")
+
+// Filter — на что подписан клиент: на топик (посты и комментарии в нём) или на комментарии поста.
+type Filter struct {
+	TopicID int64
+	PostID  int64
+}
+
+type Update struct {
+	Type      string // тип события (post.created, comment.deleted, ...), heartbeat или resync
+	EventID   int64
+	TopicID   int64
+	PostID    int64
+	CommentID int64
+	Payload   json.RawMessage
+	At        time.Time
+}
+
+type subscriber struct {
+	ch      chan Update
+	dropped atomic.Bool
+}
+
+var _ events.Consumer = (*Hub)(nil)
+
+type Hub struct {
+	buffer    int
+	heartbeat time.Duration
+	logger    *slog.Logger
+
+	mu      sync.RWMutex
+	byTopic map[int64]map[*subscriber]struct{}
+	byPost  map[int64]map[*subscriber]struct{}
+}
+
+func NewHub(buffer int, heartbeat time.Duration, logger *slog.Logger) *Hub {
+	if buffer <= 0 {
+		buffer = 64
+	}
+	return &Hub{
+		buffer:    buffer,
+		heartbeat: heartbeat,
+		logger:    logger,
+		byTopic:   make(map[int64]map[*subscriber]struct{}),
+		byPost:    make(map[int64]map[*subscriber]struct{}),
+	}
+}
+
+// Publish раздаёт события подходящим подписчикам; реализует events.Consumer. Не блокируется:
+// если буфер подписчика полон, событие отбрасывается, а подписчик получит resync.
+func (h *Hub) Publish(events []*entity.Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, e := range events {
+		u, ok := toUpdate(e)
+		if !ok {
+			continue
+		}
+		for sub := range h.byTopic[u.TopicID] {
+			sub.push(u)
+		}
+		if u.PostID != 0 {
+			for sub := range h.byPost[u.PostID] {
+				sub.push(u)
+			}
+		}
+	}
+}
+
+func (s *subscriber) push(u Update) {
+	select {
+	case s.ch <- u:
+	default:
+		s.dropped.Store(true)
+	}
+}
+
+func toUpdate(e *entity.Event) (Update, bool) {
+	var ref struct {
+		TopicID int64 `json:"topic_id"`
+		PostID  int64 `json:"post_id"`
+	}
+	if err := json.Unmarshal(e.Payload, &ref); err != nil {
+		return Update{}, false
+	}
+
+	u := Update{
+		Type:    string(e.Type),
+		EventID: e.ID,
+		TopicID: ref.TopicID,
+		Payload: e.Payload,
+		At:      e.OccurredAt,
+	}
+	switch e.AggregateType {
+	case "topic":
+		u.TopicID = e.AggregateID
+	case "post":
+		if e.Type == entity.EventTagAttached || e.Type == entity.EventTagDetached {
+			return Update{}, false
+		}
+		u.PostID = e.AggregateID
+	case "comment":
+		u.PostID = ref.PostID
+		u.CommentID = e.AggregateID
+	default:
+		return Update{}, false
+	}
+	return u, u.TopicID != 0
+}
+
+// Watch отправляет в send изменения по фильтру, пока не отменён ctx или send не вернёт ошибку.
+// Если изменений нет дольше heartbeat, отправляется heartbeat.
+func (h *Hub) Watch(ctx context.Context, f Filter, send func(Update) error) error {
+	if (f.TopicID == 0) == (f.PostID == 0) {
+		return ErrInvalidFilter
+	}
+
+	sub := &subscriber{ch: make(chan Update, h.buffer)}
+	h.subscribe(f, sub)
+	defer h.unsubscribe(f, sub)
+
+	heartbeat := h.heartbeat
+	if heartbeat <= 0 {
+		heartbeat = 30 * time.Second
+	}
+	timer := time.NewTimer(heartbeat)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case u := <-sub.ch:
+			if err := send(u); err != nil {
+				return err
+			}
+		case <-timer.C:
+			if err := send(Update{Type: UpdateHeartbeat, At: time.Now().UTC()}); err != nil {
+				return err
+			}
+		}
+
+		// Буфер разгребли после переполнения — сообщаем, что что-то пропущено
+		if len(sub.ch) == 0 && sub.dropped.Swap(false) {
+			if err := send(Update{Type: UpdateResync, TopicID: f.TopicID, PostID: f.PostID, At: time.Now().UTC()}); err != nil {
+				return err
+			}
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(heartbeat)
+	}
+}
+
+func (h *Hub) subscribe(f Filter, sub *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	index, key := h.index(f)
+	if index[key] == nil {
+		index[key] = make(map[*subscriber]struct{})
+	}
+	index[key][sub] = struct{}{}
+}
+
+func (h *Hub) unsubscribe(f Filter, sub *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	index, key := h.index(f)
+	delete(index[key], sub)
+	if len(index[key]) == 0 {
+		delete(index, key)
+	}
+}
+
+func (h *Hub) index(f Filter) (map[int64]map[*subscriber]struct{}, int64) {
+	if f.TopicID != 0 {
+		return h.byTopic, f.TopicID
+	}
+	return h.byPost, f.PostID
+}
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_topics_last_activity_unlocked;
+
+ALTER TABLE topics
+    DROP COLUMN IF EXISTS locked_at,
+    DROP COLUMN IF EXISTS locked_by,
+    DROP COLUMN IF EXISTS lock_reason;
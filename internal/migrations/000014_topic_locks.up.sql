@@ -0,0 +1,8 @@
+ALTER TABLE topics
+    ADD COLUMN locked_at TIMESTAMPTZ,
+    ADD COLUMN locked_by BIGINT,
+    ADD COLUMN lock_reason TEXT;
+
+CREATE INDEX idx_topics_last_activity_unlocked
+    ON topics (last_activity)
+    WHERE locked_at IS NULL;
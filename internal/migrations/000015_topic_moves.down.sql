@@ -0,0 +1,25 @@
+DROP TABLE IF EXISTS audit_log;
+
+DROP TRIGGER IF EXISTS trg_topics_count ON topics;
+
+CREATE OR REPLACE FUNCTION increment_category_topics_count()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE categories SET topics_count = topics_count + 1
+        WHERE id = NEW.category_id;
+    ELSIF TG_OP = 'DELETE' THEN
+        UPDATE categories SET topics_count = GREATEST(topics_count - 1, 0)
+        WHERE id = OLD.category_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_topics_count
+AFTER INSERT OR DELETE ON topics
+FOR EACH ROW
+EXECUTE FUNCTION increment_category_topics_count();
+
+ALTER TABLE topics DROP COLUMN IF EXISTS first_post_id;
+ALTER TABLE topics DROP COLUMN IF EXISTS moved_to_id;
@@ -0,0 +1,50 @@
+-- redirect-заглушка остаётся в старой категории и указывает на перенесённый топик
+ALTER TABLE topics
+    ADD COLUMN moved_to_id INTEGER REFERENCES topics(id) ON DELETE CASCADE;
+
+-- Первый пост хранится явно: после объединения в топике появляются посты старше его первого поста
+ALTER TABLE topics
+    ADD COLUMN first_post_id BIGINT REFERENCES posts(id) ON DELETE SET NULL;
+
+UPDATE topics t
+SET first_post_id = (
+    SELECT p.id FROM posts p WHERE p.topic_id = t.id ORDER BY p.created_at, p.id LIMIT 1
+);
+
+CREATE INDEX idx_topics_first_post ON topics (first_post_id) WHERE first_post_id IS NOT NULL;
+
+-- topics_count учитывает перенос между категориями и не считает заглушки
+CREATE OR REPLACE FUNCTION increment_category_topics_count()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.moved_to_id IS NULL THEN
+        UPDATE categories SET topics_count = GREATEST(topics_count - 1, 0)
+        WHERE id = OLD.category_id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.moved_to_id IS NULL THEN
+        UPDATE categories SET topics_count = topics_count + 1
+        WHERE id = NEW.category_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_topics_count ON topics;
+
+CREATE TRIGGER trg_topics_count
+AFTER INSERT OR DELETE OR UPDATE OF category_id, moved_to_id ON topics
+FOR EACH ROW
+EXECUTE FUNCTION increment_category_topics_count();
+
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id BIGINT NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    target_type VARCHAR(32) NOT NULL,
+    target_id BIGINT NOT NULL,
+    reason TEXT,
+    details JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_audit_log_target ON audit_log (target_type, target_id);
@@ -0,0 +1,7 @@
+ALTER TABLE categories
+    ADD COLUMN parent_id INTEGER REFERENCES categories(id) ON DELETE RESTRICT; -- непустую категорию не удалить вместе с поддеревом
+
+ALTER TABLE categories
+    ADD CONSTRAINT categories_parent_not_self CHECK (parent_id <> id);
+
+CREATE INDEX idx_categories_parent_id ON categories (parent_id);
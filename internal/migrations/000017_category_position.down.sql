@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_topics_category_last_activity;
+DROP INDEX IF EXISTS idx_categories_parent_position;
+
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS position;
@@ -0,0 +1,16 @@
+ALTER TABLE categories
+    ADD COLUMN position INT NOT NULL DEFAULT 0;
+
+-- сохраняем текущий порядок (created_at DESC) внутри каждого родителя
+UPDATE categories c
+SET position = o.rn
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY parent_id ORDER BY created_at DESC) AS rn
+    FROM categories
+) o
+WHERE c.id = o.id;
+
+CREATE INDEX idx_categories_parent_position ON categories (parent_id, position);
+
+-- последняя активность в категории для статистики
+CREATE INDEX idx_topics_category_last_activity ON topics (category_id, last_activity DESC);
@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS post_view_daily;
+
+DROP INDEX IF EXISTS idx_post_views_viewed_at;
+DROP INDEX IF EXISTS idx_post_views_dedup;
+
+ALTER TABLE post_views
+    DROP COLUMN IF EXISTS bucket,
+    DROP COLUMN IF EXISTS viewer_hash;
+
+DELETE FROM post_views a
+USING post_views b
+WHERE a.post_id = b.post_id AND a.user_id = b.user_id AND a.id > b.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_post_views_unique
+    ON post_views (post_id, user_id);
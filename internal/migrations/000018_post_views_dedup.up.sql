@@ -0,0 +1,21 @@
+-- уникальность (post_id, user_id) склеивала всех анонимов в одного зрителя
+DROP INDEX IF EXISTS idx_post_views_unique;
+
+-- bucket — номер окна дедупликации (секунды эпохи / длина окна); старые строки его не имеют
+ALTER TABLE post_views
+    ADD COLUMN viewer_hash VARCHAR(64),
+    ADD COLUMN bucket BIGINT;
+
+UPDATE post_views SET user_id = NULL WHERE user_id = 0;
+
+-- Один просмотр на зрителя в окне; параллельные вставки разрешает ON CONFLICT
+CREATE UNIQUE INDEX idx_post_views_dedup ON post_views (post_id, viewer_hash, bucket);
+CREATE INDEX idx_post_views_viewed_at ON post_views (viewed_at);
+
+-- старые сырые просмотры сворачиваются сюда по дням
+CREATE TABLE post_view_daily (
+    post_id BIGINT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    views BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (post_id, day)
+);
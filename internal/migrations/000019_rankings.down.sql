@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_posts_topic_created;
+DROP INDEX IF EXISTS idx_comments_post_created;
+
+DROP TABLE IF EXISTS post_scores;
+DROP TABLE IF EXISTS topic_scores;
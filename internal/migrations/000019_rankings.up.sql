@@ -0,0 +1,34 @@
+CREATE TABLE topic_scores (
+    topic_id INTEGER PRIMARY KEY REFERENCES topics(id) ON DELETE CASCADE,
+    hot DOUBLE PRECISION NOT NULL DEFAULT 0,
+    day DOUBLE PRECISION NOT NULL DEFAULT 0,
+    week DOUBLE PRECISION NOT NULL DEFAULT 0,
+    month DOUBLE PRECISION NOT NULL DEFAULT 0,
+    all_time DOUBLE PRECISION NOT NULL DEFAULT 0,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_topic_scores_hot ON topic_scores (hot DESC);
+CREATE INDEX idx_topic_scores_day ON topic_scores (day DESC);
+CREATE INDEX idx_topic_scores_week ON topic_scores (week DESC);
+CREATE INDEX idx_topic_scores_month ON topic_scores (month DESC);
+CREATE INDEX idx_topic_scores_all_time ON topic_scores (all_time DESC);
+
+CREATE TABLE post_scores (
+    post_id INTEGER PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+    hot DOUBLE PRECISION NOT NULL DEFAULT 0,
+    day DOUBLE PRECISION NOT NULL DEFAULT 0,
+    week DOUBLE PRECISION NOT NULL DEFAULT 0,
+    month DOUBLE PRECISION NOT NULL DEFAULT 0,
+    all_time DOUBLE PRECISION NOT NULL DEFAULT 0,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_post_scores_hot ON post_scores (hot DESC);
+CREATE INDEX idx_post_scores_day ON post_scores (day DESC);
+CREATE INDEX idx_post_scores_week ON post_scores (week DESC);
+CREATE INDEX idx_post_scores_month ON post_scores (month DESC);
+CREATE INDEX idx_post_scores_all_time ON post_scores (all_time DESC);
+
+CREATE INDEX idx_comments_post_created ON comments (post_id, created_at);
+CREATE INDEX idx_posts_topic_created ON posts (topic_id, created_at);
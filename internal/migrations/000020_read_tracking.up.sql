@@ -0,0 +1,19 @@
+-- Маркер прочитанного: всё с posts.id <= last_read_post_id считается прочитанным.
+CREATE TABLE topic_reads (
+    user_id BIGINT NOT NULL,
+    topic_id INTEGER NOT NULL REFERENCES topics(id) ON DELETE CASCADE,
+    last_read_post_id INTEGER NOT NULL DEFAULT 0,
+    read_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, topic_id)
+);
+
+CREATE INDEX idx_topic_reads_topic ON topic_reads (topic_id);
+
+-- "Отметить всё прочитанным" в категории: одна строка вместо строки на каждый топик.
+CREATE TABLE category_reads (
+    user_id BIGINT NOT NULL,
+    category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    last_read_post_id INTEGER NOT NULL DEFAULT 0,
+    read_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, category_id)
+);
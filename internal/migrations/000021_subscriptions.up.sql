@@ -0,0 +1,26 @@
+CREATE TABLE subscriptions (
+    user_id BIGINT NOT NULL,
+    target_type VARCHAR(16) NOT NULL CHECK (target_type IN ('topic', 'category', 'tag')),
+    target_id BIGINT NOT NULL,
+    muted BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, target_type, target_id)
+);
+
+CREATE INDEX idx_subscriptions_target ON subscriptions (target_type, target_id);
+
+CREATE TABLE notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    actor_id BIGINT NOT NULL,
+    actor_nickname VARCHAR(255) NOT NULL DEFAULT '',
+    topic_id INTEGER REFERENCES topics(id) ON DELETE CASCADE,
+    post_id INTEGER REFERENCES posts(id) ON DELETE CASCADE,
+    comment_id INTEGER REFERENCES comments(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    read_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_notifications_user ON notifications (user_id, id DESC);
+CREATE INDEX idx_notifications_unread ON notifications (user_id) WHERE read_at IS NULL;
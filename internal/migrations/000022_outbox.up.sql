@@ -0,0 +1,20 @@
+-- События пишутся в одной транзакции с изменением. Курсор для подписчиков — (txid, id):
+-- id выдаётся до коммита, и событие с меньшим id может стать видимым позже большего.
+-- Читатели берут только события транзакций старше самой старой незавершённой, поэтому позже
+-- появиться может только событие с большим курсором.
+CREATE TABLE outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(64) NOT NULL,
+    version SMALLINT NOT NULL,
+    aggregate_type VARCHAR(32) NOT NULL,
+    aggregate_id BIGINT NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    dispatched_at TIMESTAMPTZ,
+    txid BIGINT NOT NULL DEFAULT pg_current_xact_id()::text::bigint
+);
+
+CREATE INDEX idx_outbox_events_cursor ON outbox_events (txid, id);
+
+CREATE INDEX idx_outbox_events_pending ON outbox_events (id) WHERE dispatched_at IS NULL;
+CREATE INDEX idx_outbox_events_dispatched ON outbox_events (dispatched_at) WHERE dispatched_at IS NOT NULL;
@@ -0,0 +1,12 @@
+-- NOTIFY отправляется при коммите, поэтому слушатели видят только закоммиченные события.
+-- В payload только id: тело события может не влезть в лимит NOTIFY (8000 байт).
+CREATE OR REPLACE FUNCTION notify_outbox_event() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('forum_events', NEW.id::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_outbox_events_notify
+AFTER INSERT ON outbox_events
+FOR EACH ROW EXECUTE FUNCTION notify_outbox_event();
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS reports;
+DROP TABLE IF EXISTS moderation_cases;
+
+ALTER TABLE notifications DROP COLUMN IF EXISTS text;
+ALTER TABLE comments DROP COLUMN IF EXISTS status;
+ALTER TABLE posts DROP COLUMN IF EXISTS status;
+ALTER TABLE topics ALTER COLUMN status DROP NOT NULL;
@@ -0,0 +1,45 @@
+UPDATE topics SET status = 1 WHERE status IS NULL;
+ALTER TABLE topics ALTER COLUMN status SET NOT NULL;
+
+ALTER TABLE posts ADD COLUMN status INT NOT NULL DEFAULT 1;
+ALTER TABLE comments ADD COLUMN status INT NOT NULL DEFAULT 1;
+
+-- Текст предупреждения модератора; у уведомлений о новом контенте пустой
+ALTER TABLE notifications ADD COLUMN text TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE moderation_cases (
+    id BIGSERIAL PRIMARY KEY,
+    target_type VARCHAR(16) NOT NULL CHECK (target_type IN ('topic', 'post', 'comment')),
+    target_id BIGINT NOT NULL,
+    category_id INTEGER, -- для фильтра очереди по категории
+    author_id BIGINT NOT NULL,
+    reports_count INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(16) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'claimed', 'resolved')),
+    auto_hidden BOOLEAN NOT NULL DEFAULT false, -- контент скрыт по порогу жалоб, dismiss вернёт его
+    claimed_by BIGINT,
+    claimed_at TIMESTAMPTZ,
+    resolved_by BIGINT,
+    resolved_at TIMESTAMPTZ,
+    resolution VARCHAR(16) CHECK (resolution IN ('dismiss', 'hide', 'delete', 'warn')),
+    resolution_note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- На один объект — не больше одного незакрытого кейса
+CREATE UNIQUE INDEX idx_moderation_cases_open ON moderation_cases (target_type, target_id) WHERE status <> 'resolved';
+CREATE INDEX idx_moderation_cases_queue ON moderation_cases (status, reports_count DESC, id);
+
+CREATE TABLE reports (
+    id BIGSERIAL PRIMARY KEY,
+    case_id BIGINT NOT NULL REFERENCES moderation_cases(id) ON DELETE CASCADE,
+    target_type VARCHAR(16) NOT NULL,
+    target_id BIGINT NOT NULL,
+    reporter_id BIGINT NOT NULL,
+    reason VARCHAR(16) NOT NULL CHECK (reason IN ('spam', 'abuse', 'off_topic', 'illegal', 'other')),
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (case_id, reporter_id)
+);
+
+CREATE INDEX idx_reports_case_reason ON reports (case_id, reason);
@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS trg_audit_log_append_only ON audit_log;
+DROP FUNCTION IF EXISTS forbid_audit_log_update();
+
+DROP INDEX IF EXISTS idx_audit_log_created;
+DROP INDEX IF EXISTS idx_audit_log_action;
+DROP INDEX IF EXISTS idx_audit_log_actor;
+
+ALTER TABLE audit_log
+    DROP COLUMN IF EXISTS after,
+    DROP COLUMN IF EXISTS before;
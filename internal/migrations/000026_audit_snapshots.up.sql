@@ -0,0 +1,20 @@
+ALTER TABLE audit_log
+    ADD COLUMN before JSONB,
+    ADD COLUMN after JSONB;
+
+CREATE INDEX idx_audit_log_actor ON audit_log (actor_id, id DESC);
+CREATE INDEX idx_audit_log_action ON audit_log (action, id DESC);
+CREATE INDEX idx_audit_log_created ON audit_log (created_at);
+
+-- Журнал только дописывается; удаление разрешено — им пользуется чистка по сроку хранения
+CREATE OR REPLACE FUNCTION forbid_audit_log_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_audit_log_append_only
+BEFORE UPDATE ON audit_log
+FOR EACH ROW
+EXECUTE FUNCTION forbid_audit_log_update();
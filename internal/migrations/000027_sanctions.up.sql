@@ -0,0 +1,15 @@
+CREATE TABLE sanctions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    kind VARCHAR(8) NOT NULL CHECK (kind IN ('ban', 'mute')),
+    category_id INTEGER REFERENCES categories(id) ON DELETE CASCADE, -- NULL — на весь форум
+    reason TEXT NOT NULL,
+    issued_by BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ, -- NULL — бессрочно
+    lifted_at TIMESTAMPTZ,
+    lifted_by BIGINT,
+    CHECK (kind = 'ban' OR expires_at IS NOT NULL)
+);
+
+CREATE INDEX idx_sanctions_user_active ON sanctions (user_id) WHERE lifted_at IS NULL;
@@ -0,0 +1,6 @@
+-- Корзины восстанавливаются сами, поэтому WAL для них не нужен
+CREATE UNLOGGED TABLE rate_limit_buckets (
+    key VARCHAR(255) PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
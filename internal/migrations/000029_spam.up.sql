@@ -0,0 +1,26 @@
+CREATE TABLE content_fingerprints (
+    id BIGSERIAL PRIMARY KEY,
+    author_id BIGINT NOT NULL,
+    content_type VARCHAR(16) NOT NULL CHECK (content_type IN ('post', 'comment')),
+    content_id BIGINT NOT NULL,
+    hash CHAR(64) NOT NULL,
+    simhash BIGINT NOT NULL, -- uint64 в двоичном виде
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_content_fingerprints_author ON content_fingerprints (author_id, created_at DESC);
+CREATE INDEX idx_content_fingerprints_created ON content_fingerprints (created_at);
+
+-- Наивный байес: частоты слов в текстах, помеченных модераторами как спам и как нормальные
+CREATE TABLE spam_tokens (
+    token TEXT PRIMARY KEY,
+    spam_count BIGINT NOT NULL DEFAULT 0,
+    ham_count BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE spam_training (
+    class VARCHAR(4) PRIMARY KEY CHECK (class IN ('spam', 'ham')),
+    docs BIGINT NOT NULL DEFAULT 0
+);
+
+INSERT INTO spam_training (class, docs) VALUES ('spam', 0), ('ham', 0);
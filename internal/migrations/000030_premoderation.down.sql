@@ -0,0 +1,78 @@
+DROP TRIGGER IF EXISTS trg_posts_count ON posts;
+DROP TRIGGER IF EXISTS trg_topics_count ON topics;
+DROP TRIGGER IF EXISTS trg_comments_count ON comments;
+
+CREATE OR REPLACE FUNCTION increment_topic_posts_count()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE topics SET posts_count = posts_count + 1, last_activity = NOW()
+        WHERE id = NEW.topic_id;
+    ELSIF TG_OP = 'DELETE' THEN
+        UPDATE topics SET posts_count = GREATEST(posts_count - 1, 0), last_activity = NOW()
+        WHERE id = OLD.topic_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_posts_count
+AFTER INSERT OR DELETE ON posts
+FOR EACH ROW
+EXECUTE FUNCTION increment_topic_posts_count();
+
+
+-- Версия из 000015
+CREATE OR REPLACE FUNCTION increment_category_topics_count()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.moved_to_id IS NULL THEN
+        UPDATE categories SET topics_count = GREATEST(topics_count - 1, 0)
+        WHERE id = OLD.category_id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.moved_to_id IS NULL THEN
+        UPDATE categories SET topics_count = topics_count + 1
+        WHERE id = NEW.category_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_topics_count
+AFTER INSERT OR DELETE OR UPDATE OF category_id, moved_to_id ON topics
+FOR EACH ROW
+EXECUTE FUNCTION increment_category_topics_count();
+
+
+CREATE OR REPLACE FUNCTION increment_post_comments_count()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE posts SET comments_count = comments_count + 1
+        WHERE id = NEW.post_id;
+    ELSIF TG_OP = 'DELETE' THEN
+        UPDATE posts SET comments_count = GREATEST(comments_count - 1, 0)
+        WHERE id = OLD.post_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_comments_count
+AFTER INSERT OR DELETE ON comments
+FOR EACH ROW
+EXECUTE FUNCTION increment_post_comments_count();
+
+DROP FUNCTION IF EXISTS is_published;
+
+UPDATE moderation_cases SET resolution = 'hide' WHERE resolution = 'reject';
+ALTER TABLE moderation_cases DROP CONSTRAINT moderation_cases_resolution_check;
+ALTER TABLE moderation_cases ADD CONSTRAINT moderation_cases_resolution_check
+    CHECK (resolution IN ('dismiss', 'hide', 'delete', 'warn'));
+
+DELETE FROM reports WHERE reason = 'premoderation';
+ALTER TABLE reports DROP CONSTRAINT reports_reason_check;
+ALTER TABLE reports ADD CONSTRAINT reports_reason_check
+    CHECK (reason IN ('spam', 'abuse', 'off_topic', 'illegal', 'other'));
+
+ALTER TABLE categories DROP COLUMN IF EXISTS premoderated;
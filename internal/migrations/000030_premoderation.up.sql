@@ -0,0 +1,80 @@
+ALTER TABLE categories ADD COLUMN premoderated BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE reports DROP CONSTRAINT reports_reason_check;
+ALTER TABLE reports ADD CONSTRAINT reports_reason_check
+    CHECK (reason IN ('spam', 'abuse', 'off_topic', 'illegal', 'other', 'premoderation'));
+
+ALTER TABLE moderation_cases DROP CONSTRAINT moderation_cases_resolution_check;
+ALTER TABLE moderation_cases ADD CONSTRAINT moderation_cases_resolution_check
+    CHECK (resolution IN ('dismiss', 'hide', 'delete', 'warn', 'reject'));
+
+-- Контент, ждущий одобрения (4) или отклонённый (5), не учитывается в счётчиках и last_activity
+CREATE OR REPLACE FUNCTION is_published(status INT) RETURNS BOOLEAN AS $$
+    SELECT status NOT IN (4, 5);
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION increment_topic_posts_count()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' AND is_published(NEW.status)
+        OR TG_OP = 'UPDATE' AND is_published(NEW.status) AND NOT is_published(OLD.status) THEN
+        UPDATE topics SET posts_count = posts_count + 1, last_activity = NOW()
+        WHERE id = NEW.topic_id;
+    ELSIF TG_OP = 'DELETE' AND is_published(OLD.status)
+        OR TG_OP = 'UPDATE' AND is_published(OLD.status) AND NOT is_published(NEW.status) THEN
+        UPDATE topics SET posts_count = GREATEST(posts_count - 1, 0), last_activity = NOW()
+        WHERE id = OLD.topic_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER trg_posts_count ON posts;
+CREATE TRIGGER trg_posts_count
+AFTER INSERT OR DELETE OR UPDATE OF status ON posts
+FOR EACH ROW
+EXECUTE FUNCTION increment_topic_posts_count();
+
+-- Как в 000015: перенос между категориями и redirect-заглушки, плюс неопубликованные топики не считаются
+CREATE OR REPLACE FUNCTION increment_category_topics_count()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.moved_to_id IS NULL AND is_published(OLD.status) THEN
+        UPDATE categories SET topics_count = GREATEST(topics_count - 1, 0)
+        WHERE id = OLD.category_id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.moved_to_id IS NULL AND is_published(NEW.status) THEN
+        UPDATE categories SET topics_count = topics_count + 1
+        WHERE id = NEW.category_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER trg_topics_count ON topics;
+CREATE TRIGGER trg_topics_count
+AFTER INSERT OR DELETE OR UPDATE OF category_id, moved_to_id, status ON topics
+FOR EACH ROW
+EXECUTE FUNCTION increment_category_topics_count();
+
+CREATE OR REPLACE FUNCTION increment_post_comments_count()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' AND is_published(NEW.status)
+        OR TG_OP = 'UPDATE' AND is_published(NEW.status) AND NOT is_published(OLD.status) THEN
+        UPDATE posts SET comments_count = comments_count + 1
+        WHERE id = NEW.post_id;
+    ELSIF TG_OP = 'DELETE' AND is_published(OLD.status)
+        OR TG_OP = 'UPDATE' AND is_published(OLD.status) AND NOT is_published(NEW.status) THEN
+        UPDATE posts SET comments_count = GREATEST(comments_count - 1, 0)
+        WHERE id = OLD.post_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER trg_comments_count ON comments;
+CREATE TRIGGER trg_comments_count
+AFTER INSERT OR DELETE OR UPDATE OF status ON comments
+FOR EACH ROW
+EXECUTE FUNCTION increment_post_comments_count();
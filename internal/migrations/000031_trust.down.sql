@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_moderation_cases_author;
+DROP INDEX IF EXISTS idx_topics_accepted_post;
+DROP INDEX IF EXISTS idx_comments_author;
+DROP INDEX IF EXISTS idx_posts_author;
+
+DROP TABLE IF EXISTS trust_overrides;
+
+ALTER TABLE moderation_cases DROP COLUMN IF EXISTS report_weight;
+ALTER TABLE reports DROP COLUMN IF EXISTS weight;
+ALTER TABLE posts DROP COLUMN IF EXISTS wiki;
+ALTER TABLE topics DROP COLUMN IF EXISTS accepted_post_id;
@@ -0,0 +1,24 @@
+-- Ответ, принятый автором топика
+ALTER TABLE topics ADD COLUMN accepted_post_id BIGINT REFERENCES posts(id) ON DELETE SET NULL;
+
+-- Wiki-пост могут править не только автор, но и пользователи с привилегией edit_wiki
+ALTER TABLE posts ADD COLUMN wiki BOOLEAN NOT NULL DEFAULT false;
+
+-- Вес жалобы зависит от уровня доверия жалующегося; порог автоскрытия считается по сумме весов
+ALTER TABLE reports ADD COLUMN weight INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE moderation_cases ADD COLUMN report_weight INTEGER NOT NULL DEFAULT 0;
+UPDATE moderation_cases SET report_weight = reports_count;
+
+CREATE TABLE trust_overrides (
+    user_id BIGINT PRIMARY KEY,
+    level SMALLINT NOT NULL CHECK (level BETWEEN 0 AND 4),
+    reason TEXT NOT NULL,
+    set_by BIGINT NOT NULL,
+    set_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Репутация считается по автору
+CREATE INDEX IF NOT EXISTS idx_posts_author ON posts (author_id);
+CREATE INDEX IF NOT EXISTS idx_comments_author ON comments (author_id);
+CREATE INDEX idx_topics_accepted_post ON topics (accepted_post_id) WHERE accepted_post_id IS NOT NULL;
+CREATE INDEX idx_moderation_cases_author ON moderation_cases (author_id) WHERE status = 'resolved';
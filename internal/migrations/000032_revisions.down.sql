@@ -0,0 +1,12 @@
+DROP TRIGGER IF EXISTS trg_comments_revisions ON comments;
+DROP TRIGGER IF EXISTS trg_posts_revisions ON posts;
+DROP FUNCTION IF EXISTS delete_revisions();
+
+ALTER TABLE comments
+    DROP COLUMN IF EXISTS edited_at,
+    DROP COLUMN IF EXISTS edited_by;
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS edited_at,
+    DROP COLUMN IF EXISTS edited_by;
+
+DROP TABLE IF EXISTS revisions;
@@ -0,0 +1,40 @@
+-- Неизменяемая история правок постов и комментариев.
+-- Версия 1 — исходный текст, сохраняется при первой правке; дальше — текст после каждой правки.
+CREATE TABLE revisions (
+    id BIGSERIAL PRIMARY KEY,
+    target_type VARCHAR(16) NOT NULL CHECK (target_type IN ('post', 'comment')),
+    target_id BIGINT NOT NULL,
+    number INTEGER NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL,
+    editor_id BIGINT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    diff TEXT NOT NULL DEFAULT '', -- относительно предыдущей версии
+    restored_from INTEGER, -- откат модератором к этой версии
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (target_type, target_id, number)
+);
+
+-- Кто и когда правил последним, для пометки «изменено»
+ALTER TABLE posts
+    ADD COLUMN edited_by BIGINT,
+    ADD COLUMN edited_at TIMESTAMPTZ;
+ALTER TABLE comments
+    ADD COLUMN edited_by BIGINT,
+    ADD COLUMN edited_at TIMESTAMPTZ;
+
+-- История уходит вместе с объектом; комментарии удаляются каскадом вместе с постом
+CREATE OR REPLACE FUNCTION delete_revisions() RETURNS trigger AS $$
+BEGIN
+    DELETE FROM revisions WHERE target_type = TG_ARGV[0] AND target_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_posts_revisions
+AFTER DELETE ON posts
+FOR EACH ROW EXECUTE FUNCTION delete_revisions('post');
+
+CREATE TRIGGER trg_comments_revisions
+AFTER DELETE ON comments
+FOR EACH ROW EXECUTE FUNCTION delete_revisions('comment');
@@ -0,0 +1,6 @@
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS chk_comments_status;
+ALTER TABLE comments ALTER COLUMN created_at DROP NOT NULL;
+
+ALTER TABLE comments
+    DROP COLUMN IF EXISTS edit_count,
+    DROP COLUMN IF EXISTS updated_at;
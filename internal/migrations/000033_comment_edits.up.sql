@@ -0,0 +1,31 @@
+-- Время и число правок комментариев; edited_by и edited_at добавлены в 000032
+ALTER TABLE comments
+    ADD COLUMN updated_at TIMESTAMPTZ,
+    ADD COLUMN edit_count INTEGER NOT NULL DEFAULT 0;
+
+-- Версия 1 в revisions — исходный текст, остальные — правки
+UPDATE comments c
+SET edit_count = r.n - 1,
+    edited_by = COALESCE(c.edited_by, r.last_editor),
+    edited_at = COALESCE(c.edited_at, r.last_at)
+FROM (
+    SELECT DISTINCT ON (target_id) target_id,
+        COUNT(*) OVER (PARTITION BY target_id) AS n,
+        editor_id AS last_editor,
+        created_at AS last_at
+    FROM revisions
+    WHERE target_type = 'comment'
+    ORDER BY target_id, number DESC
+) r
+WHERE r.target_id = c.id AND r.n > 1;
+
+UPDATE comments SET updated_at = COALESCE(edited_at, created_at, now());
+UPDATE comments SET created_at = updated_at WHERE created_at IS NULL;
+
+ALTER TABLE comments
+    ALTER COLUMN updated_at SET NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT now(),
+    ALTER COLUMN created_at SET NOT NULL;
+
+-- Те же статусы, что у топиков и постов: active, deleted, hidden, pending, rejected (entity.Status)
+ALTER TABLE comments ADD CONSTRAINT chk_comments_status CHECK (status BETWEEN 1 AND 5);
@@ -0,0 +1,5 @@
+ALTER TABLE tags DROP COLUMN IF EXISTS version;
+ALTER TABLE comments DROP COLUMN IF EXISTS version;
+ALTER TABLE posts DROP COLUMN IF EXISTS version;
+ALTER TABLE topics DROP COLUMN IF EXISTS version;
+ALTER TABLE categories DROP COLUMN IF EXISTS version;
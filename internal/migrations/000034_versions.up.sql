@@ -0,0 +1,7 @@
+-- Версия для оптимистичной блокировки правок. Увеличивается явно в запросах правки,
+-- а не триггером: счётчики и last_activity меняются постоянно и не должны ломать чужие правки.
+ALTER TABLE categories ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE topics ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE posts ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE comments ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE tags ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
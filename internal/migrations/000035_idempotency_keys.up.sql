@@ -0,0 +1,14 @@
+-- Ключи идемпотентности create-RPC. Ключ принадлежит пользователю; в request_hash — метод и тело запроса,
+-- так что тот же ключ с другим запросом или в другом методе — конфликт.
+CREATE TABLE idempotency_keys (
+    user_id BIGINT NOT NULL,
+    key TEXT NOT NULL,
+    method TEXT NOT NULL,
+    request_hash BYTEA NOT NULL,
+    response BYTEA, -- NULL, пока первый запрос выполняется
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires ON idempotency_keys (expires_at);
@@ -0,0 +1,23 @@
+-- Черновики топиков, постов и комментариев. Видны только автору; публикуются через обычные CreateTopic/CreatePost/CreateComment
+CREATE TABLE drafts (
+    id BIGSERIAL PRIMARY KEY,
+    author_id BIGINT NOT NULL,
+    author_nickname TEXT NOT NULL DEFAULT '',
+    kind TEXT NOT NULL CHECK (kind IN ('topic', 'post', 'comment')),
+    category_id BIGINT NOT NULL DEFAULT 0, -- для топика
+    topic_id BIGINT NOT NULL DEFAULT 0,    -- для поста
+    post_id BIGINT NOT NULL DEFAULT 0,     -- для комментария
+    title TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL DEFAULT '',
+    images TEXT[] NOT NULL DEFAULT '{}',
+    tag_ids BIGINT[] NOT NULL DEFAULT '{}',
+    publish_at TIMESTAMPTZ,    -- NULL — публикация не запланирована
+    locked_until TIMESTAMPTZ,  -- черновик публикуется: планировщик или автор уже взяли его
+    last_error TEXT NOT NULL DEFAULT '', -- почему не удалась запланированная публикация
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Родителей не связываем внешними ключами: черновик переживает удаление топика и покажет ошибку при публикации
+CREATE INDEX idx_drafts_author ON drafts (author_id, updated_at DESC);
+CREATE INDEX idx_drafts_publish_at ON drafts (publish_at) WHERE publish_at IS NOT NULL;
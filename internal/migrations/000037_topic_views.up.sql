@@ -0,0 +1,10 @@
+-- Отметки просмотров топиков: по одной на зрителя в окне дедупликации, общие для всех реплик.
+-- Нужны только последние окна — старые удаляет flush счётчика просмотров.
+CREATE TABLE topic_views (
+    topic_id BIGINT NOT NULL,
+    viewer_hash VARCHAR(64) NOT NULL,
+    bucket BIGINT NOT NULL,
+    PRIMARY KEY (topic_id, viewer_hash, bucket)
+);
+
+CREATE INDEX idx_topic_views_bucket ON topic_views (bucket);
@@ -0,0 +1,74 @@
+// Package ratelimit содержит token bucket'ы в памяти процесса.
+// Лимиты считаются для каждой реплики отдельно; для нескольких реплик
+// используется postgres.NewRateLimitRepository.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+var _ repository.RateLimitRepository = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit entity.RateLimit) (time.Duration, error) {
+	capacity, rate := limit.Capacity(), limit.TokensPerSecond()
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updatedAt: now}
+		s.buckets[key] = b
+	}
+	elapsed := max(now.Sub(b.updatedAt).Seconds(), 0)
+	b.tokens = min(capacity, b.tokens+elapsed*rate)
+	b.updatedAt = now
+
+	if b.tokens < 1 {
+		wait := time.Duration(math.Ceil((1 - b.tokens) / rate * float64(time.Second)))
+		return max(wait, time.Millisecond), nil
+	}
+	b.tokens--
+	return 0, nil
+}
+
+func (s *MemoryStore) Cleanup(_ context.Context, idle time.Duration) (int64, error) {
+	before := s.now().Add(-idle)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var removed int64
+	for key, b := range s.buckets {
+		if b.updatedAt.Before(before) {
+			delete(s.buckets, key)
+			removed++
+		}
+	}
+	return removed, nil
+}
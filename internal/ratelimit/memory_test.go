@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+// clock — управляемое время для MemoryStore.now.
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func TestMemoryStoreTake(t *testing.T) {
+	type step struct {
+		advance time.Duration // сдвиг часов перед попыткой
+		key     string        // пусто — "k"
+		want    time.Duration // 0 — попытка проходит
+	}
+	tests := []struct {
+		name  string
+		limit entity.RateLimit
+		steps []step
+	}{
+		{
+			name:  "burst then wait",
+			limit: entity.RateLimit{Events: 1, Per: time.Minute, Burst: 2},
+			steps: []step{
+				{want: 0},
+				{want: 0},
+				{want: time.Minute},
+				{advance: 30 * time.Second, want: 30 * time.Second},
+				{advance: 30 * time.Second, want: 0},
+				{want: time.Minute},
+			},
+		},
+		{
+			name:  "refill is capped by capacity",
+			limit: entity.RateLimit{Events: 2, Per: time.Second},
+			steps: []step{
+				{want: 0},
+				{want: 0},
+				{want: 500 * time.Millisecond},
+				{advance: time.Hour, want: 0},
+				{want: 0},
+				{want: 500 * time.Millisecond},
+			},
+		},
+		{
+			name:  "keys are independent",
+			limit: entity.RateLimit{Events: 1, Per: time.Minute},
+			steps: []step{
+				{want: 0},
+				{want: time.Minute},
+				{key: "other", want: 0},
+				{key: "other", want: time.Minute},
+			},
+		},
+		{
+			name:  "clock going backwards does not refill",
+			limit: entity.RateLimit{Events: 1, Per: time.Minute},
+			steps: []step{
+				{want: 0},
+				{advance: -time.Hour, want: time.Minute},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &clock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+			s := NewMemoryStore()
+			s.now = c.now
+
+			for i, st := range tt.steps {
+				c.t = c.t.Add(st.advance)
+				key := st.key
+				if key == "" {
+					key = "k"
+				}
+				got, err := s.Take(context.Background(), key, tt.limit)
+				if err != nil {
+					t.Fatalf("step %d: Take() error = %v", i, err)
+				}
+				if got != st.want {
+					t.Fatalf("step %d: Take() = %v, want %v", i, got, st.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryStoreCleanup(t *testing.T) {
+	c := &clock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := NewMemoryStore()
+	s.now = c.now
+	limit := entity.RateLimit{Events: 1, Per: time.Minute}
+	ctx := context.Background()
+
+	s.Take(ctx, "idle", limit)
+	c.t = c.t.Add(time.Hour)
+	s.Take(ctx, "active", limit)
+
+	removed, err := s.Cleanup(ctx, 30*time.Minute)
+	if err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if removed != 1 {
+		t.Fatalf("Cleanup() removed %d buckets, want 1", removed)
+	}
+	if _, ok := s.buckets["active"]; !ok {
+		t.Fatal("active bucket was removed")
+	}
+	// Корзина удалена — ключ снова начинает с полной
+	if wait, _ := s.Take(ctx, "idle", limit); wait != 0 {
+		t.Fatalf("Take() after cleanup = %v, want 0", wait)
+	}
+}
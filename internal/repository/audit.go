@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+type AuditFilter struct {
+	ActorID    int64
+	Action     entity.AuditAction
+	TargetType entity.AuditTargetType
+	TargetID   int64
+	From, To   time.Time // нулевое значение — без границы
+}
+
+type AuditRepository interface {
+	Create(ctx context.Context, entry *entity.AuditEntry) error
+	List(ctx context.Context, filter AuditFilter, limit, offset int) ([]*entity.AuditEntry, int64, error)
+	// DeleteBefore удаляет не больше limit записей старше before. Возвращает число удалённых.
+	DeleteBefore(ctx context.Context, before time.Time, limit int) (int64, error)
+}
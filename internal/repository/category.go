@@ -8,7 +8,10 @@ import (
 )
 
 var (
-	ErrNotFound = errors.New("entity not found")
+	ErrNotFound      = errors.New("entity not found")
+	ErrInvalidOrder  = errors.New("order must list every sibling exactly once")
+	ErrAlreadyExists = errors.New("entity already exists")
+	ErrConflict      = errors.New("entity was changed concurrently")
 )
 
 type CategoryRepository interface {
@@ -17,6 +20,14 @@ type CategoryRepository interface {
 	GetBySlug(ctx context.Context, slug string) (*entity.Category, error)
 	List(ctx context.Context, limit, offset int) ([]*entity.Category, error)
 	Count(ctx context.Context) (int64, error)
+	// Update применяет правку, только если category.Version совпадает с текущей (0 — без проверки), иначе ErrConflict.
 	Update(ctx context.Context, category *entity.Category) (*entity.Category, error)
+	// Delete возвращает ErrConflict, если у категории есть подкатегории.
 	Delete(ctx context.Context, id int64) error
+	ListAll(ctx context.Context) ([]*entity.Category, error)
+	ListByParent(ctx context.Context, parentID int64, limit, offset int) ([]*entity.Category, int64, error)
+	Ancestors(ctx context.Context, id int64) ([]*entity.Category, error)
+	Descendants(ctx context.Context, id int64) ([]*entity.Category, error)
+	Reorder(ctx context.Context, parentID int64, orderedIDs []int64) error
+	SetPremoderated(ctx context.Context, id int64, premoderated bool) error
 }
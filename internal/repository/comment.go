@@ -10,6 +10,7 @@ type CommentRepository interface {
 	Create(ctx context.Context, comment *entity.Comment) (int64, error)
 	GetByID(ctx context.Context, id int64) (*entity.Comment, error)
 	ListByPost(ctx context.Context, postID int64, limit, offset int) ([]*entity.Comment, int64, error)
+	// Update — с той же проверкой comment.Version, что и у постов.
 	Update(ctx context.Context, comment *entity.Comment) error
 	Delete(ctx context.Context, commentID int64) error
 }
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+type CounterRepository interface {
+	// FindDrift проверяет не более limit строк с id > afterID и возвращает расхождения,
+	// число проверенных строк и последний проверенный id.
+	FindDrift(ctx context.Context, counter entity.Counter, afterID int64, limit int) ([]entity.CounterDrift, int, int64, error)
+	// Fix пересчитывает счётчик для ids по исходной таблице.
+	Fix(ctx context.Context, counter entity.Counter, ids []int64) error
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+type DraftRepository interface {
+	Create(ctx context.Context, d *entity.Draft) error
+	GetByID(ctx context.Context, id int64) (*entity.Draft, error)
+	// Update меняет текст и место публикации; расписание не трогает.
+	Update(ctx context.Context, d *entity.Draft) error
+	Delete(ctx context.Context, id int64) error
+	ListByAuthor(ctx context.Context, authorID int64, limit, offset int) ([]*entity.Draft, int64, error)
+	CountByAuthor(ctx context.Context, authorID int64) (int64, error)
+	// SetSchedule назначает или снимает (nil) публикацию и сбрасывает last_error.
+	SetSchedule(ctx context.Context, id int64, publishAt *time.Time) error
+	// Lock берёт черновик на публикацию до until. Если его уже публикуют — ErrConflict.
+	Lock(ctx context.Context, id int64, now, until time.Time) error
+	Unlock(ctx context.Context, id int64) error
+	// LockDue берёт до limit черновиков, время публикации которых пришло; остальные реплики их пропускают.
+	LockDue(ctx context.Context, now, until time.Time, limit int) ([]*entity.Draft, error)
+	// MarkFailed снимает расписание и блокировку и запоминает причину для автора.
+	MarkFailed(ctx context.Context, id int64, reason string) error
+}
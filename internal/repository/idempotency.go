@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+type IdempotencyRepository interface {
+	// Reserve занимает ключ под новый запрос. Истёкший ключ и брошенный незавершённым раньше staleBefore
+	// занимаются заново. Если ключ занят, возвращает false — запись читается через Get.
+	Reserve(ctx context.Context, k *entity.IdempotencyKey, staleBefore time.Time) (bool, error)
+	Get(ctx context.Context, userID int64, key string) (*entity.IdempotencyKey, error)
+	// Complete сохраняет ответ; до этого повторы получают «запрос ещё выполняется».
+	Complete(ctx context.Context, userID int64, key string, response []byte) error
+	// Release освобождает незавершённый ключ, если запрос упал, чтобы его можно было повторить.
+	Release(ctx context.Context, userID int64, key string) error
+	Cleanup(ctx context.Context, before time.Time) (int64, error)
+}
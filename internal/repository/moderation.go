@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+type CaseFilter struct {
+	Status     entity.CaseStatus   // пусто — все незакрытые (open и claimed)
+	CategoryID int64               // 0 — все категории
+	Reason     entity.ReportReason // пусто — любые причины
+	TargetType entity.ContentType  // пусто — топики, посты и комментарии
+}
+
+type ModerationRepository interface {
+	// ContentInfo возвращает автора, топик, категорию и статус объекта жалобы.
+	ContentInfo(ctx context.Context, targetType entity.ContentType, targetID int64) (*entity.ContentRef, error)
+	// AddReport находит или открывает кейс по объекту и добавляет в него жалобу.
+	// Повторная жалоба того же пользователя в незакрытый кейс — ErrAlreadyExists.
+	AddReport(ctx context.Context, ref *entity.ContentRef, report *entity.Report) (*entity.ModerationCase, error)
+	GetCase(ctx context.Context, id int64) (*entity.ModerationCase, error)
+	// OpenCase возвращает незакрытый кейс по объекту или ErrNotFound.
+	OpenCase(ctx context.Context, targetType entity.ContentType, targetID int64) (*entity.ModerationCase, error)
+	ListCases(ctx context.Context, filter CaseFilter, limit, offset int) ([]*entity.ModerationCase, int64, error)
+	ListReports(ctx context.Context, caseID int64) ([]*entity.Report, error)
+	// ClaimCase закрепляет кейс за модератором. Чужой захват старше claimTTL считается брошенным.
+	// Кейс уже разобран или занят другим — ErrConflict.
+	ClaimCase(ctx context.Context, id, moderatorID int64, claimTTL time.Duration, at time.Time) (*entity.ModerationCase, error)
+	// ResolveCase закрывает кейс, если он не разобран и не занят другим модератором, иначе ErrConflict.
+	ResolveCase(ctx context.Context, c *entity.ModerationCase) error
+	MarkAutoHidden(ctx context.Context, caseID int64) error
+	// SetContentStatus меняет статус топика, поста или комментария.
+	SetContentStatus(ctx context.Context, targetType entity.ContentType, targetID int64, status entity.Status) error
+	// SetPendingPostsStatus меняет статус ждущих одобрения постов топика — вместе с самим топиком.
+	SetPendingPostsStatus(ctx context.Context, topicID int64, status entity.Status) error
+}
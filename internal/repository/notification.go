@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+type NotificationRepository interface {
+	// CreateForSubscribers создаёт копию n для каждого подписчика топика, категорий из categoryIDs
+	// или тегов поста, кроме самого автора. Возвращает число созданных уведомлений.
+	CreateForSubscribers(ctx context.Context, n *entity.Notification, categoryIDs []int64) (int64, error)
+	// Create создаёт одно адресное уведомление, например предупреждение модератора.
+	Create(ctx context.Context, n *entity.Notification) error
+	ListByUser(ctx context.Context, userID int64, unreadOnly bool, limit, offset int) ([]*entity.Notification, int64, error)
+	// MarkRead отмечает прочитанными уведомления из ids, а при пустом ids — все уведомления пользователя.
+	MarkRead(ctx context.Context, userID int64, ids []int64, at time.Time) (int64, error)
+	UnreadCount(ctx context.Context, userID int64) (int64, error)
+}
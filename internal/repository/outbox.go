@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+type OutboxRepository interface {
+	// Add пишет события в outbox; вызывается внутри транзакции изменения.
+	Add(ctx context.Context, events ...*entity.Event) error
+	// ListPending забирает ещё не доставленные события по возрастанию id. Вызывается в транзакции:
+	// строки заблокированы до её конца, и диспетчеры других реплик берут другие события.
+	ListPending(ctx context.Context, limit int) ([]*entity.Event, error)
+	// ListAfter возвращает события после курсора по возрастанию курсора независимо от доставки.
+	// События транзакций, начатых после самой старой незавершённой, придерживаются, пока та не закончится:
+	// иначе подписчик ушёл бы вперёд и пропустил событие, которое закоммитят позже.
+	ListAfter(ctx context.Context, after entity.EventCursor, limit int) ([]*entity.Event, error)
+	// Head — курсор последнего события, которое уже отдаёт ListAfter; нулевой, если outbox пуст.
+	Head(ctx context.Context) (entity.EventCursor, error)
+	// MarkDispatched вызывается в той же транзакции, что и ListPending.
+	MarkDispatched(ctx context.Context, ids []int64, at time.Time) error
+	// DeleteDispatched удаляет до limit доставленных событий старше before.
+	DeleteDispatched(ctx context.Context, before time.Time, limit int) (int64, error)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/VaneZ444/forum-service/internal/entity"
 )
@@ -11,9 +12,14 @@ type PostRepository interface {
 	GetByID(ctx context.Context, id int64) (*entity.Post, error)
 	ListByTopic(ctx context.Context, topicID int64, limit int, offset int) ([]*entity.Post, int64, error)
 	List(ctx context.Context, topicID, tagID int64, limit, offset int) ([]*entity.Post, int64, error)
+	// Update заменяет текст и теги, только если post.Version совпадает с текущей (0 — без проверки), иначе ErrConflict.
+	// После правки в post.Version — новая версия.
 	Update(ctx context.Context, post *entity.Post) error
 	Delete(ctx context.Context, id int64) error
 	ListByTag(ctx context.Context, tagID int64, limit, offset int) ([]*entity.Post, int64, error)
-	AddView(ctx context.Context, postID, userID int64) error
+	AddView(ctx context.Context, postID, userID int64, viewerHash string, window time.Duration) error
+	RollupViews(ctx context.Context, before time.Time, limit int) (int64, error)
+	ListTop(ctx context.Context, period entity.RankingPeriod, topicID int64, limit, offset int) ([]*entity.Post, int64, error)
 	Search(ctx context.Context, query string, limit, offset int) ([]*entity.Post, int64, error)
+	SetWiki(ctx context.Context, id int64, wiki bool) error
 }
@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+type auditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) repository.AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Create(ctx context.Context, entry *entity.AuditEntry) error {
+	const query = `
+		INSERT INTO audit_log (actor_id, action, target_type, target_id, reason, details, before, after, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+	details, err := json.Marshal(entry.Details)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit details: %w", err)
+	}
+	before, err := marshalSnapshot(entry.Before)
+	if err != nil {
+		return err
+	}
+	after, err := marshalSnapshot(entry.After)
+	if err != nil {
+		return err
+	}
+
+	err = conn(ctx, r.db).QueryRowContext(ctx, query,
+		entry.ActorID,
+		entry.Action,
+		entry.TargetType,
+		entry.TargetID,
+		entry.Reason,
+		details,
+		before,
+		after,
+		entry.CreatedAt,
+	).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create audit entry: %w", err)
+	}
+	return nil
+}
+
+// marshalSnapshot превращает nil-снимок в NULL, а не в JSON null.
+func marshalSnapshot(snapshot map[string]any) ([]byte, error) {
+	if snapshot == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit snapshot: %w", err)
+	}
+	return data, nil
+}
+
+func (r *auditRepository) List(ctx context.Context, filter repository.AuditFilter, limit, offset int) ([]*entity.AuditEntry, int64, error) {
+	where := ` WHERE 1=1`
+	args := []any{}
+	if filter.ActorID > 0 {
+		args = append(args, filter.ActorID)
+		where += fmt.Sprintf(` AND actor_id = $%d`, len(args))
+	}
+	if filter.Action != "" {
+		args = append(args, filter.Action)
+		where += fmt.Sprintf(` AND action = $%d`, len(args))
+	}
+	if filter.TargetType != "" {
+		args = append(args, filter.TargetType)
+		where += fmt.Sprintf(` AND target_type = $%d`, len(args))
+	}
+	if filter.TargetID > 0 {
+		args = append(args, filter.TargetID)
+		where += fmt.Sprintf(` AND target_id = $%d`, len(args))
+	}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		where += fmt.Sprintf(` AND created_at >= $%d`, len(args))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		where += fmt.Sprintf(` AND created_at < $%d`, len(args))
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_log`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+
+	query := `
+		SELECT id, actor_id, action, target_type, target_id, COALESCE(reason, ''), details, before, after, created_at
+		FROM audit_log` + where +
+		fmt.Sprintf(` ORDER BY id DESC LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*entity.AuditEntry{}
+	for rows.Next() {
+		e := &entity.AuditEntry{}
+		var details, before, after []byte
+		if err := rows.Scan(
+			&e.ID, &e.ActorID, &e.Action, &e.TargetType, &e.TargetID, &e.Reason, &details, &before, &after, &e.CreatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		for _, f := range []struct {
+			data []byte
+			dst  *map[string]any
+		}{{details, &e.Details}, {before, &e.Before}, {after, &e.After}} {
+			if len(f.data) == 0 {
+				continue
+			}
+			if err := json.Unmarshal(f.data, f.dst); err != nil {
+				return nil, 0, fmt.Errorf("failed to unmarshal audit entry %d: %w", e.ID, err)
+			}
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows error: %w", err)
+	}
+	return entries, total, nil
+}
+
+func (r *auditRepository) DeleteBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	const query = `
+		DELETE FROM audit_log
+		WHERE id IN (SELECT id FROM audit_log WHERE created_at < $1 ORDER BY id LIMIT $2)
+	`
+	result, err := r.db.ExecContext(ctx, query, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old audit entries: %w", err)
+	}
+	return result.RowsAffected()
+}
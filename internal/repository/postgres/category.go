@@ -8,8 +8,38 @@ import (
 
 	"github.com/VaneZ444/forum-service/internal/entity"
 	"github.com/VaneZ444/forum-service/internal/repository"
+	"github.com/lib/pq"
 )
 
+// categoryStatsQuery выбирает категорию вместе со счётчиками и последней активностью.
+// Последний пост берётся из последнего активного топика, redirect-заглушки и неопубликованное не учитываются.
+const categoryStatsQuery = `
+	SELECT c.id, COALESCE(c.parent_id, 0), c.title, c.slug, c.description, c.position,
+		c.created_at, c.updated_at, c.premoderated, c.version, c.topics_count, COALESCE(s.posts_count, 0),
+		lt.id, lt.title, lt.author_id, lt.author_nickname, lt.last_activity,
+		lp.id, lp.title, lp.author_id, lp.author_nickname, lp.created_at
+	FROM categories c
+	LEFT JOIN LATERAL (
+		SELECT SUM(t.posts_count) AS posts_count
+		FROM topics t
+		WHERE t.category_id = c.id AND t.moved_to_id IS NULL
+	) s ON true
+	LEFT JOIN LATERAL (
+		SELECT t.id, t.title, t.author_id, t.author_nickname, t.last_activity
+		FROM topics t
+		WHERE t.category_id = c.id AND t.moved_to_id IS NULL AND t.status = 1
+		ORDER BY t.last_activity DESC
+		LIMIT 1
+	) lt ON true
+	LEFT JOIN LATERAL (
+		SELECT p.id, p.title, p.author_id, p.author_nickname, p.created_at
+		FROM posts p
+		WHERE p.topic_id = lt.id AND p.status = 1
+		ORDER BY p.created_at DESC
+		LIMIT 1
+	) lp ON true
+`
+
 type categoryRepository struct {
 	db *sql.DB
 }
@@ -20,22 +50,30 @@ func NewCategoryRepository(db *sql.DB) repository.CategoryRepository {
 
 func (r *categoryRepository) Create(ctx context.Context, category *entity.Category) (*entity.Category, error) {
 	const query = `
-        INSERT INTO categories (title, slug, description)
-        VALUES ($1, $2, $3)
-        RETURNING id, title, slug, description, created_at, updated_at
+        INSERT INTO categories (title, slug, description, parent_id, position)
+        VALUES ($1, $2, $3, NULLIF($4, 0), CASE WHEN $5 > 0 THEN $5 ELSE (
+            SELECT COALESCE(MAX(position), 0) + 1 FROM categories WHERE COALESCE(parent_id, 0) = $4
+        ) END)
+        RETURNING id, COALESCE(parent_id, 0), title, slug, description, position, created_at, updated_at, premoderated, version
     `
 	newCategory := &entity.Category{}
-	err := r.db.QueryRowContext(ctx, query,
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
 		category.Title,
 		category.Slug,
 		category.Description,
+		category.ParentID,
+		category.Position,
 	).Scan(
 		&newCategory.ID,
+		&newCategory.ParentID,
 		&newCategory.Title,
 		&newCategory.Slug,
 		&newCategory.Description,
+		&newCategory.Position,
 		&newCategory.CreatedAt,
 		&newCategory.UpdatedAt,
+		&newCategory.Premoderated,
+		&newCategory.Version,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create category: %w", err)
@@ -44,24 +82,9 @@ func (r *categoryRepository) Create(ctx context.Context, category *entity.Catego
 }
 
 func (r *categoryRepository) GetByID(ctx context.Context, id int64) (*entity.Category, error) {
-	const query = `
-		SELECT id, title, slug, description, created_at, updated_at 
-		FROM categories 
-		WHERE id = $1
-	`
-
-	row := r.db.QueryRowContext(ctx, query, id)
-	category := &entity.Category{}
-
-	err := row.Scan(
-		&category.ID,
-		&category.Title,
-		&category.Slug,
-		&category.Description,
-		&category.CreatedAt,
-		&category.UpdatedAt,
-	)
+	row := r.db.QueryRowContext(ctx, categoryStatsQuery+` WHERE c.id = $1`, id)
 
+	category, err := scanCategoryWithStats(row)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, repository.ErrNotFound
@@ -73,7 +96,7 @@ func (r *categoryRepository) GetByID(ctx context.Context, id int64) (*entity.Cat
 
 func (r *categoryRepository) GetBySlug(ctx context.Context, slug string) (*entity.Category, error) {
 	const query = `
-		SELECT id, title, slug, description, created_at, updated_at 
+		SELECT id, COALESCE(parent_id, 0), title, slug, description, position, created_at, updated_at, premoderated, version
 		FROM categories 
 		WHERE slug = $1
 	`
@@ -83,11 +106,15 @@ func (r *categoryRepository) GetBySlug(ctx context.Context, slug string) (*entit
 
 	err := row.Scan(
 		&category.ID,
+		&category.ParentID,
 		&category.Title,
 		&category.Slug,
 		&category.Description,
+		&category.Position,
 		&category.CreatedAt,
 		&category.UpdatedAt,
+		&category.Premoderated,
+		&category.Version,
 	)
 
 	if err != nil {
@@ -100,10 +127,8 @@ func (r *categoryRepository) GetBySlug(ctx context.Context, slug string) (*entit
 }
 
 func (r *categoryRepository) List(ctx context.Context, limit, offset int) ([]*entity.Category, error) {
-	const query = `
-		SELECT id, title, slug, description, created_at, updated_at 
-		FROM categories 
-		ORDER BY created_at DESC
+	query := categoryStatsQuery + `
+		ORDER BY c.position ASC, c.created_at DESC
 		LIMIT $1 OFFSET $2
 	`
 
@@ -111,29 +136,7 @@ func (r *categoryRepository) List(ctx context.Context, limit, offset int) ([]*en
 	if err != nil {
 		return nil, fmt.Errorf("failed to list categories: %w", err)
 	}
-	defer rows.Close()
-
-	categories := []*entity.Category{}
-	for rows.Next() {
-		var c entity.Category
-		if err := rows.Scan(
-			&c.ID,
-			&c.Title,
-			&c.Slug,
-			&c.Description,
-			&c.CreatedAt,
-			&c.UpdatedAt,
-		); err != nil {
-			return nil, fmt.Errorf("failed to scan category: %w", err)
-		}
-		categories = append(categories, &c)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows error: %w", err)
-	}
-
-	return categories, nil
+	return scanCategoriesWithStats(rows)
 }
 
 func (r *categoryRepository) Count(ctx context.Context) (int64, error) {
@@ -149,30 +152,38 @@ func (r *categoryRepository) Count(ctx context.Context) (int64, error) {
 func (r *categoryRepository) Update(ctx context.Context, category *entity.Category) (*entity.Category, error) {
 	const query = `
 		UPDATE categories 
-		SET title = $1, slug = $2, description = $3, updated_at = $4
-		WHERE id = $5
-		RETURNING id, title, slug, description, created_at, updated_at
+		SET title = $1, slug = $2, description = $3, updated_at = $4, parent_id = NULLIF($5, 0), position = $6,
+			version = version + 1
+		WHERE id = $7 AND ($8 = 0 OR version = $8)
+		RETURNING id, COALESCE(parent_id, 0), title, slug, description, position, created_at, updated_at, premoderated, version
 	`
 
 	updatedCategory := &entity.Category{}
-	err := r.db.QueryRowContext(ctx, query,
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
 		category.Title,
 		category.Slug,
 		category.Description,
 		category.UpdatedAt,
+		category.ParentID,
+		category.Position,
 		category.ID,
+		category.Version,
 	).Scan(
 		&updatedCategory.ID,
+		&updatedCategory.ParentID,
 		&updatedCategory.Title,
 		&updatedCategory.Slug,
 		&updatedCategory.Description,
+		&updatedCategory.Position,
 		&updatedCategory.CreatedAt,
 		&updatedCategory.UpdatedAt,
+		&updatedCategory.Premoderated,
+		&updatedCategory.Version,
 	)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, repository.ErrNotFound
+			return nil, versionMissError(ctx, r.db, "categories", category.ID)
 		}
 		return nil, fmt.Errorf("failed to update category: %w", err)
 	}
@@ -182,7 +193,11 @@ func (r *categoryRepository) Update(ctx context.Context, category *entity.Catego
 
 func (r *categoryRepository) Delete(ctx context.Context, id int64) error {
 	const query = `DELETE FROM categories WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return repository.ErrConflict // parent_id подкатегорий ссылается на неё с ON DELETE RESTRICT
+	}
 	if err != nil {
 		return fmt.Errorf("failed to delete category: %w", err)
 	}
@@ -198,3 +213,229 @@ func (r *categoryRepository) Delete(ctx context.Context, id int64) error {
 
 	return nil
 }
+
+func (r *categoryRepository) ListAll(ctx context.Context) ([]*entity.Category, error) {
+	query := categoryStatsQuery + ` ORDER BY c.position ASC, c.title`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+	return scanCategoriesWithStats(rows)
+}
+
+// ListByParent возвращает один уровень дерева; parentID = 0 — корневые категории.
+func (r *categoryRepository) ListByParent(ctx context.Context, parentID int64, limit, offset int) ([]*entity.Category, int64, error) {
+	const countQuery = `SELECT COUNT(*) FROM categories WHERE COALESCE(parent_id, 0) = $1`
+	var total int64
+	if err := r.db.QueryRowContext(ctx, countQuery, parentID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count categories: %w", err)
+	}
+
+	query := categoryStatsQuery + `
+		WHERE COALESCE(c.parent_id, 0) = $1
+		ORDER BY c.position ASC, c.title
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, parentID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list categories by parent: %w", err)
+	}
+	categories, err := scanCategoriesWithStats(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return categories, total, nil
+}
+
+// Reorder расставляет позиции детей parentID в порядке orderedIDs.
+// Список должен содержать ровно всех детей parentID, иначе изменения откатываются.
+func (r *categoryRepository) Reorder(ctx context.Context, parentID int64, orderedIDs []int64) error {
+	positions := make([]int64, len(orderedIDs))
+	for i := range orderedIDs {
+		positions[i] = int64(i + 1)
+	}
+
+	return inTx(ctx, r.db, func(tx querier) error {
+		var total int
+		const countQuery = `SELECT COUNT(*) FROM categories WHERE COALESCE(parent_id, 0) = $1`
+		if err := tx.QueryRowContext(ctx, countQuery, parentID).Scan(&total); err != nil {
+			return fmt.Errorf("failed to count categories: %w", err)
+		}
+
+		const query = `
+			UPDATE categories c
+			SET position = v.position, updated_at = now(), version = c.version + 1
+			FROM unnest($1::bigint[], $2::bigint[]) AS v(id, position)
+			WHERE c.id = v.id AND COALESCE(c.parent_id, 0) = $3
+		`
+		result, err := tx.ExecContext(ctx, query, pq.Array(orderedIDs), pq.Array(positions), parentID)
+		if err != nil {
+			return fmt.Errorf("failed to reorder categories: %w", err)
+		}
+		updated, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if int(updated) != len(orderedIDs) || total != len(orderedIDs) {
+			return repository.ErrInvalidOrder
+		}
+		return nil
+	})
+}
+
+// Ancestors возвращает путь от корня до категории id включительно.
+func (r *categoryRepository) Ancestors(ctx context.Context, id int64) ([]*entity.Category, error) {
+	const query = `
+		WITH RECURSIVE path AS (
+			SELECT c.*, 0 AS lvl FROM categories c WHERE c.id = $1
+			UNION ALL
+			SELECT c.*, p.lvl + 1 FROM categories c JOIN path p ON c.id = p.parent_id
+		)
+		SELECT id, COALESCE(parent_id, 0), title, slug, description, position, created_at, updated_at, premoderated
+		FROM path
+		ORDER BY lvl DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category ancestors: %w", err)
+	}
+	categories, err := scanCategories(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(categories) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return categories, nil
+}
+
+// Descendants возвращает всё поддерево категории id, не включая её саму.
+func (r *categoryRepository) Descendants(ctx context.Context, id int64) ([]*entity.Category, error) {
+	const query = `
+		WITH RECURSIVE tree AS (
+			SELECT c.* FROM categories c WHERE c.parent_id = $1
+			UNION ALL
+			SELECT c.* FROM categories c JOIN tree t ON c.parent_id = t.id
+		)
+		SELECT id, COALESCE(parent_id, 0), title, slug, description, position, created_at, updated_at, premoderated
+		FROM tree
+	`
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category descendants: %w", err)
+	}
+	return scanCategories(rows)
+}
+
+// SetPremoderated включает или выключает премодерацию в категории.
+func (r *categoryRepository) SetPremoderated(ctx context.Context, id int64, premoderated bool) error {
+	const query = `UPDATE categories SET premoderated = $2, updated_at = now(), version = version + 1 WHERE id = $1`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, premoderated)
+	if err != nil {
+		return fmt.Errorf("failed to set category premoderation: %w", err)
+	}
+	return expectAffected(result)
+}
+
+func scanCategories(rows *sql.Rows) ([]*entity.Category, error) {
+	defer rows.Close()
+
+	categories := []*entity.Category{}
+	for rows.Next() {
+		c := &entity.Category{}
+		if err := rows.Scan(
+			&c.ID,
+			&c.ParentID,
+			&c.Title,
+			&c.Slug,
+			&c.Description,
+			&c.Position,
+			&c.CreatedAt,
+			&c.UpdatedAt,
+			&c.Premoderated,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		categories = append(categories, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return categories, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// nullActivityRef принимает колонки LEFT JOIN, которые пусты у категории без топиков.
+type nullActivityRef struct {
+	id             sql.NullInt64
+	title          sql.NullString
+	authorID       sql.NullInt64
+	authorNickname sql.NullString
+	at             sql.NullTime
+}
+
+func (n *nullActivityRef) dest() []any {
+	return []any{&n.id, &n.title, &n.authorID, &n.authorNickname, &n.at}
+}
+
+func (n *nullActivityRef) ref() *entity.ActivityRef {
+	if !n.id.Valid {
+		return nil
+	}
+	return &entity.ActivityRef{
+		ID:             n.id.Int64,
+		Title:          n.title.String,
+		AuthorID:       n.authorID.Int64,
+		AuthorNickname: n.authorNickname.String,
+		At:             n.at.Time,
+	}
+}
+
+func scanCategoryWithStats(row rowScanner) (*entity.Category, error) {
+	c := &entity.Category{}
+	var lastTopic, lastPost nullActivityRef
+
+	dest := []any{
+		&c.ID,
+		&c.ParentID,
+		&c.Title,
+		&c.Slug,
+		&c.Description,
+		&c.Position,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+		&c.Premoderated,
+		&c.Version,
+		&c.TopicsCount,
+		&c.PostsCount,
+	}
+	dest = append(dest, lastTopic.dest()...)
+	dest = append(dest, lastPost.dest()...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	c.LastTopic = lastTopic.ref()
+	c.LastPost = lastPost.ref()
+	return c, nil
+}
+
+func scanCategoriesWithStats(rows *sql.Rows) ([]*entity.Category, error) {
+	defer rows.Close()
+
+	categories := []*entity.Category{}
+	for rows.Next() {
+		c, err := scanCategoryWithStats(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		categories = append(categories, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return categories, nil
+}
@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+func TestDeleteCategoryWithChildren(t *testing.T) {
+	ctx, db, _ := testTx(t)
+	categories := NewCategoryRepository(db)
+
+	suffix := time.Now().UnixNano()
+	parent, err := categories.Create(ctx, &entity.Category{Title: "parent", Slug: fmt.Sprintf("parent-%d", suffix)})
+	if err != nil {
+		t.Fatalf("create parent: %v", err)
+	}
+	if _, err := categories.Create(ctx, &entity.Category{Title: "child", Slug: fmt.Sprintf("child-%d", suffix), ParentID: parent.ID}); err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+
+	// После ошибки транзакция прервана, поэтому проверка последняя
+	if err := categories.Delete(ctx, parent.ID); !errors.Is(err, repository.ErrConflict) {
+		t.Fatalf("Delete() error = %v, want %v", err, repository.ErrConflict)
+	}
+}
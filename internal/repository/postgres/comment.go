@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/VaneZ444/forum-service/internal/entity"
@@ -17,29 +18,50 @@ func NewCommentRepository(db *sql.DB) repository.CommentRepository {
 	return &commentRepository{db: db}
 }
 
+const commentColumns = `id, post_id, content, author_id, author_nickname, created_at, updated_at, status,
+	edit_count, COALESCE(edited_by, 0), edited_at, version`
+
+func scanComment(row rowScanner) (*entity.Comment, error) {
+	c := &entity.Comment{}
+	err := row.Scan(
+		&c.ID, &c.PostID, &c.Content, &c.AuthorID, &c.AuthorNickname, &c.CreatedAt, &c.UpdatedAt, &c.Status,
+		&c.EditCount, &c.EditedBy, &c.EditedAt, &c.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
 func (r *commentRepository) Create(ctx context.Context, comment *entity.Comment) (int64, error) {
 	const query = `
-	INSERT INTO comments (post_id, content, author_id, author_nickname, created_at) 
-	VALUES ($1, $2, $3, $4, $5) 
+	INSERT INTO comments (post_id, content, author_id, author_nickname, created_at, updated_at, status) 
+	VALUES ($1, $2, $3, $4, $5, $5, $6) 
 	RETURNING id
 	`
+	if comment.Status == 0 {
+		comment.Status = entity.StatusActive
+	}
 
-	err := r.db.QueryRowContext(ctx, query,
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
 		comment.PostID,
 		comment.Content,
 		comment.AuthorID,
 		comment.AuthorNickname,
 		comment.CreatedAt,
+		comment.Status,
 	).Scan(&comment.ID)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create comment: %w", err)
 	}
+	comment.UpdatedAt = comment.CreatedAt
+	comment.Version = 1
 	return comment.ID, nil
 }
 
 func (r *commentRepository) Delete(ctx context.Context, commentID int64) error {
 	const query = `DELETE FROM comments WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, commentID)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, commentID)
 	if err != nil {
 		return fmt.Errorf("failed to delete comment: %w", err)
 	}
@@ -49,14 +71,23 @@ func (r *commentRepository) Delete(ctx context.Context, commentID int64) error {
 func (r *commentRepository) Update(ctx context.Context, comment *entity.Comment) error {
 	const query = `
 	UPDATE comments
-	SET content = $1, author_nickname = $2
-	WHERE id = $3
+	SET content = $1, author_nickname = $2, edited_by = NULLIF($3, 0), edited_at = $4,
+		updated_at = $5, edit_count = edit_count + 1, version = version + 1
+	WHERE id = $6 AND ($7 = 0 OR version = $7)
+	RETURNING edit_count, version
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
 		comment.Content,
 		comment.AuthorNickname,
+		comment.EditedBy,
+		comment.EditedAt,
+		comment.UpdatedAt,
 		comment.ID,
-	)
+		comment.Version,
+	).Scan(&comment.EditCount, &comment.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return versionMissError(ctx, r.db, "comments", comment.ID)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to update comment: %w", err)
 	}
@@ -64,32 +95,28 @@ func (r *commentRepository) Update(ctx context.Context, comment *entity.Comment)
 }
 
 func (r *commentRepository) GetByID(ctx context.Context, id int64) (*entity.Comment, error) {
-	const query = `SELECT id, post_id, content, author_id, author_nickname, created_at 
-               FROM comments WHERE id = $1`
+	const query = `SELECT ` + commentColumns + ` FROM comments WHERE id = $1`
 
-	var c entity.Comment
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&c.ID, &c.PostID, &c.Content, &c.AuthorID, &c.AuthorNickname, &c.CreatedAt,
-	)
+	c, err := scanComment(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("comment not found: %w", err)
 		}
 		return nil, fmt.Errorf("failed to get comment: %w", err)
 	}
-	return &c, nil
+	return c, nil
 }
 
 func (r *commentRepository) ListByPost(ctx context.Context, postID int64, limit, offset int) ([]*entity.Comment, int64, error) {
-	const countQ = `SELECT COUNT(*) FROM comments WHERE post_id = $1`
+	const countQ = `SELECT COUNT(*) FROM comments WHERE post_id = $1 AND status = 1`
 	var total int64
 	if err := r.db.QueryRowContext(ctx, countQ, postID).Scan(&total); err != nil {
 		return nil, 0, fmt.Errorf("failed to count comments: %w", err)
 	}
 
-	const q = `SELECT id, post_id, content, author_id, author_nickname, created_at
+	const q = `SELECT ` + commentColumns + `
            FROM comments 
-           WHERE post_id = $1
+           WHERE post_id = $1 AND status = 1
            ORDER BY created_at ASC
            LIMIT $2 OFFSET $3`
 	rows, err := r.db.QueryContext(ctx, q, postID, limit, offset)
@@ -100,8 +127,8 @@ func (r *commentRepository) ListByPost(ctx context.Context, postID int64, limit,
 
 	var items []*entity.Comment
 	for rows.Next() {
-		c := new(entity.Comment)
-		if err := rows.Scan(&c.ID, &c.PostID, &c.Content, &c.AuthorID, &c.AuthorNickname, &c.CreatedAt); err != nil {
+		c, err := scanComment(rows)
+		if err != nil {
 			return nil, 0, err
 		}
 		items = append(items, c)
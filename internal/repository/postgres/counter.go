@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+	"github.com/lib/pq"
+)
+
+// counterSource описывает, где хранится счётчик и как посчитать его фактическое значение.
+// В actual строка текущей таблицы доступна под алиасом x.
+type counterSource struct {
+	table  string
+	column string
+	actual string
+}
+
+var counterSources = map[entity.Counter]counterSource{
+	entity.CounterCategoryTopics: {
+		table:  "categories",
+		column: "topics_count",
+		actual: `(SELECT COUNT(*) FROM topics t WHERE t.category_id = x.id AND t.moved_to_id IS NULL AND is_published(t.status))`,
+	},
+	entity.CounterTopicPosts: {
+		table:  "topics",
+		column: "posts_count",
+		actual: `(SELECT COUNT(*) FROM posts p WHERE p.topic_id = x.id AND is_published(p.status))`,
+	},
+	entity.CounterPostComments: {
+		table:  "posts",
+		column: "comments_count",
+		actual: `(SELECT COUNT(*) FROM comments c WHERE c.post_id = x.id AND is_published(c.status))`,
+	},
+	entity.CounterPostViews: {
+		table:  "posts",
+		column: "views_count",
+		actual: `((SELECT COUNT(*) FROM post_views v WHERE v.post_id = x.id) +
+			(SELECT COALESCE(SUM(d.views), 0) FROM post_view_daily d WHERE d.post_id = x.id))`,
+	},
+}
+
+type counterRepository struct {
+	db *sql.DB
+}
+
+func NewCounterRepository(db *sql.DB) repository.CounterRepository {
+	return &counterRepository{db: db}
+}
+
+func (r *counterRepository) FindDrift(ctx context.Context, counter entity.Counter, afterID int64, limit int) ([]entity.CounterDrift, int, int64, error) {
+	src, ok := counterSources[counter]
+	if !ok {
+		return nil, 0, 0, fmt.Errorf("unknown counter %q", counter)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT x.id, COALESCE(x.%[2]s, 0), %[3]s
+		FROM (SELECT id, %[2]s FROM %[1]s WHERE id > $1 ORDER BY id LIMIT $2) x
+		ORDER BY x.id
+	`, src.table, src.column, src.actual)
+
+	rows, err := r.db.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to check %s: %w", counter, err)
+	}
+	defer rows.Close()
+
+	var (
+		drift   []entity.CounterDrift
+		scanned int
+		lastID  = afterID
+	)
+	for rows.Next() {
+		d := entity.CounterDrift{Counter: counter}
+		if err := rows.Scan(&d.EntityID, &d.Stored, &d.Actual); err != nil {
+			return nil, 0, 0, fmt.Errorf("failed to scan %s: %w", counter, err)
+		}
+		scanned++
+		lastID = d.EntityID
+		if d.Stored != d.Actual {
+			drift = append(drift, d)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, 0, fmt.Errorf("rows error: %w", err)
+	}
+
+	return drift, scanned, lastID, nil
+}
+
+func (r *counterRepository) Fix(ctx context.Context, counter entity.Counter, ids []int64) error {
+	src, ok := counterSources[counter]
+	if !ok {
+		return fmt.Errorf("unknown counter %q", counter)
+	}
+
+	// Значение пересчитывается в момент UPDATE, а не берётся из FindDrift:
+	// между проверкой и исправлением триггеры могли изменить счётчик.
+	query := fmt.Sprintf(`UPDATE %[1]s x SET %[2]s = %[3]s WHERE x.id = ANY($1)`,
+		src.table, src.column, src.actual)
+	if _, err := r.db.ExecContext(ctx, query, pq.Array(ids)); err != nil {
+		return fmt.Errorf("failed to fix %s: %w", counter, err)
+	}
+	return nil
+}
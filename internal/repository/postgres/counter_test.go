@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+func TestCategoryTopicsCountFollowsMovesAndRedirects(t *testing.T) {
+	ctx, db, tx := testTx(t)
+	categories := NewCategoryRepository(db)
+	topics := NewTopicRepository(db).(*TopicRepository)
+
+	suffix := time.Now().UnixNano()
+	newCategory := func(name string) int64 {
+		c, err := categories.Create(ctx, &entity.Category{Title: name, Slug: fmt.Sprintf("%s-%d", name, suffix)})
+		if err != nil {
+			t.Fatalf("create category: %v", err)
+		}
+		return c.ID
+	}
+	a, b := newCategory("a"), newCategory("b")
+
+	newTopic := func(categoryID int64, status entity.Status) *entity.Topic {
+		topic := &entity.Topic{Title: "t", AuthorID: 1, CategoryID: categoryID, CreatedAt: time.Now().UTC(), Status: status}
+		if err := topics.CreateWithPost(ctx, topic, &entity.Post{Title: "t", Content: "c", AuthorID: 1, Status: status}); err != nil {
+			t.Fatalf("create topic: %v", err)
+		}
+		return topic
+	}
+	expect := func(step string, wantA, wantB int64) {
+		t.Helper()
+		if gotA, gotB := topicsCount(t, ctx, tx, a), topicsCount(t, ctx, tx, b); gotA != wantA || gotB != wantB {
+			t.Fatalf("%s: topics_count = (%d, %d), want (%d, %d)", step, gotA, gotB, wantA, wantB)
+		}
+	}
+
+	moved := newTopic(a, entity.StatusActive)
+	expect("create", 1, 0)
+
+	if err := topics.Move(ctx, moved.ID, b); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	expect("move", 0, 1)
+
+	if _, err := topics.CreateRedirect(ctx, moved, moved.ID, time.Now().UTC()); err != nil {
+		t.Fatalf("create redirect: %v", err)
+	}
+	expect("redirect stub", 0, 1)
+
+	merged := newTopic(a, entity.StatusActive)
+	expect("second topic", 1, 1)
+	if err := topics.MarkRedirect(ctx, merged.ID, moved.ID, time.Now().UTC()); err != nil {
+		t.Fatalf("mark redirect: %v", err)
+	}
+	expect("mark redirect", 0, 1)
+
+	pending := newTopic(b, entity.StatusPending)
+	expect("pending topic", 0, 1)
+	if _, err := tx.ExecContext(ctx, `UPDATE topics SET status = $1 WHERE id = $2`, entity.StatusActive, pending.ID); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	expect("approved topic", 0, 2)
+
+	if err := topics.Delete(ctx, pending.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	expect("delete", 0, 1)
+}
+
+func topicsCount(t *testing.T, ctx context.Context, tx *sql.Tx, categoryID int64) int64 {
+	t.Helper()
+	var n int64
+	if err := tx.QueryRowContext(ctx, `SELECT topics_count FROM categories WHERE id = $1`, categoryID).Scan(&n); err != nil {
+		t.Fatalf("read topics_count: %v", err)
+	}
+	return n
+}
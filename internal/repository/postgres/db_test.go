@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratepg "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	_ "github.com/lib/pq"
+)
+
+// Тесты репозиториев ходят в настоящий Postgres: FORUM_TEST_DSN=postgres://... go test ./...
+// Без переменной они пропускаются.
+var (
+	migrateOnce sync.Once
+	migrateErr  error
+)
+
+// testTx открывает транзакцию на тестовой базе и кладёт её в ctx, как это делает Transactor:
+// репозитории пишут в неё, а после теста всё откатывается.
+func testTx(t *testing.T) (context.Context, *sql.DB, *sql.Tx) {
+	t.Helper()
+	dsn := os.Getenv("FORUM_TEST_DSN")
+	if dsn == "" {
+		t.Skip("FORUM_TEST_DSN is not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrateOnce.Do(func() {
+		driver, err := migratepg.WithInstance(db, &migratepg.Config{})
+		if err != nil {
+			migrateErr = err
+			return
+		}
+		m, err := migrate.NewWithDatabaseInstance("file://../../migrations", "postgres", driver)
+		if err != nil {
+			migrateErr = err
+			return
+		}
+		if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			migrateErr = err
+		}
+	})
+	if migrateErr != nil {
+		t.Fatalf("migrate: %v", migrateErr)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	t.Cleanup(func() { tx.Rollback() })
+	return context.WithValue(context.Background(), txKey{}, tx), db, tx
+}
@@ -0,0 +1,205 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+	"github.com/lib/pq"
+)
+
+type draftRepository struct {
+	db *sql.DB
+}
+
+func NewDraftRepository(db *sql.DB) repository.DraftRepository {
+	return &draftRepository{db: db}
+}
+
+const draftColumns = `id, author_id, author_nickname, kind, category_id, topic_id, post_id, title, content,
+	images, tag_ids, publish_at, last_error, created_at, updated_at`
+
+func scanDraft(row rowScanner) (*entity.Draft, error) {
+	d := &entity.Draft{}
+	var tagIDs pq.Int64Array
+	err := row.Scan(
+		&d.ID, &d.AuthorID, &d.AuthorNickname, &d.Kind, &d.CategoryID, &d.TopicID, &d.PostID, &d.Title, &d.Content,
+		pq.Array(&d.Images), &tagIDs, &d.PublishAt, &d.LastError, &d.CreatedAt, &d.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	d.TagIDs = tagIDs
+	return d, nil
+}
+
+func scanDrafts(rows *sql.Rows) ([]*entity.Draft, error) {
+	defer rows.Close()
+
+	var drafts []*entity.Draft
+	for rows.Next() {
+		d, err := scanDraft(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan draft: %w", err)
+		}
+		drafts = append(drafts, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return drafts, nil
+}
+
+func (r *draftRepository) Create(ctx context.Context, d *entity.Draft) error {
+	const query = `
+		INSERT INTO drafts (author_id, author_nickname, kind, category_id, topic_id, post_id, title, content,
+			images, tag_ids, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $11)
+		RETURNING id
+	`
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		d.AuthorID, d.AuthorNickname, d.Kind, d.CategoryID, d.TopicID, d.PostID, d.Title, d.Content,
+		pq.Array(nonNilStrings(d.Images)), pq.Array(nonNilInt64s(d.TagIDs)), d.CreatedAt,
+	).Scan(&d.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create draft: %w", err)
+	}
+	d.UpdatedAt = d.CreatedAt
+	return nil
+}
+
+func (r *draftRepository) GetByID(ctx context.Context, id int64) (*entity.Draft, error) {
+	d, err := scanDraft(conn(ctx, r.db).QueryRowContext(ctx, `SELECT `+draftColumns+` FROM drafts WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get draft: %w", err)
+	}
+	return d, nil
+}
+
+func (r *draftRepository) Update(ctx context.Context, d *entity.Draft) error {
+	const query = `
+		UPDATE drafts
+		SET author_nickname = $2, kind = $3, category_id = $4, topic_id = $5, post_id = $6, title = $7, content = $8,
+			images = $9, tag_ids = $10, updated_at = $11
+		WHERE id = $1
+	`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
+		d.ID, d.AuthorNickname, d.Kind, d.CategoryID, d.TopicID, d.PostID, d.Title, d.Content,
+		pq.Array(nonNilStrings(d.Images)), pq.Array(nonNilInt64s(d.TagIDs)), d.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update draft: %w", err)
+	}
+	return expectAffected(result)
+}
+
+func (r *draftRepository) Delete(ctx context.Context, id int64) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM drafts WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete draft: %w", err)
+	}
+	return expectAffected(result)
+}
+
+func (r *draftRepository) ListByAuthor(ctx context.Context, authorID int64, limit, offset int) ([]*entity.Draft, int64, error) {
+	total, err := r.CountByAuthor(ctx, authorID)
+	if err != nil {
+		return nil, 0, err
+	}
+	const query = `SELECT ` + draftColumns + ` FROM drafts WHERE author_id = $1 ORDER BY updated_at DESC, id DESC LIMIT $2 OFFSET $3`
+	rows, err := r.db.QueryContext(ctx, query, authorID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list drafts: %w", err)
+	}
+	drafts, err := scanDrafts(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return drafts, total, nil
+}
+
+func (r *draftRepository) CountByAuthor(ctx context.Context, authorID int64) (int64, error) {
+	var total int64
+	if err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT COUNT(*) FROM drafts WHERE author_id = $1`, authorID).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to count drafts: %w", err)
+	}
+	return total, nil
+}
+
+func (r *draftRepository) SetSchedule(ctx context.Context, id int64, publishAt *time.Time) error {
+	const query = `UPDATE drafts SET publish_at = $2, last_error = '', updated_at = now() WHERE id = $1`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, publishAt)
+	if err != nil {
+		return fmt.Errorf("failed to schedule draft: %w", err)
+	}
+	return expectAffected(result)
+}
+
+func (r *draftRepository) Lock(ctx context.Context, id int64, now, until time.Time) error {
+	const query = `UPDATE drafts SET locked_until = $3 WHERE id = $1 AND (locked_until IS NULL OR locked_until < $2)`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, now, until)
+	if err != nil {
+		return fmt.Errorf("failed to lock draft: %w", err)
+	}
+	if err := expectAffected(result); errors.Is(err, repository.ErrNotFound) {
+		return versionMissError(ctx, r.db, "drafts", id)
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *draftRepository) Unlock(ctx context.Context, id int64) error {
+	if _, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE drafts SET locked_until = NULL WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to unlock draft: %w", err)
+	}
+	return nil
+}
+
+func (r *draftRepository) LockDue(ctx context.Context, now, until time.Time, limit int) ([]*entity.Draft, error) {
+	const query = `
+		UPDATE drafts SET locked_until = $2
+		WHERE id IN (
+			SELECT id FROM drafts
+			WHERE publish_at <= $1 AND (locked_until IS NULL OR locked_until < $1)
+			ORDER BY publish_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + draftColumns
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, now, until, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock due drafts: %w", err)
+	}
+	return scanDrafts(rows)
+}
+
+func (r *draftRepository) MarkFailed(ctx context.Context, id int64, reason string) error {
+	const query = `UPDATE drafts SET publish_at = NULL, locked_until = NULL, last_error = $2 WHERE id = $1`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, id, reason); err != nil {
+		return fmt.Errorf("failed to mark draft as failed: %w", err)
+	}
+	return nil
+}
+
+// nonNilStrings и nonNilInt64s: pq.Array(nil) пишет NULL, а колонки массивов NOT NULL.
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func nonNilInt64s(s []int64) []int64 {
+	if s == nil {
+		return []int64{}
+	}
+	return s
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+type idempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) repository.IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+func (r *idempotencyRepository) Reserve(ctx context.Context, k *entity.IdempotencyKey, staleBefore time.Time) (bool, error) {
+	const query = `
+		INSERT INTO idempotency_keys AS i (user_id, key, method, request_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, key) DO UPDATE SET
+			method = EXCLUDED.method,
+			request_hash = EXCLUDED.request_hash,
+			response = NULL,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE i.expires_at <= EXCLUDED.created_at OR (i.response IS NULL AND i.created_at < $7)
+		RETURNING true
+	`
+	var reserved bool
+	err := r.db.QueryRowContext(ctx, query,
+		k.UserID, k.Key, k.Method, k.RequestHash, k.CreatedAt, k.ExpiresAt, staleBefore,
+	).Scan(&reserved)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	return reserved, nil
+}
+
+func (r *idempotencyRepository) Get(ctx context.Context, userID int64, key string) (*entity.IdempotencyKey, error) {
+	const query = `
+		SELECT user_id, key, method, request_hash, response, created_at, expires_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND key = $2
+	`
+	k := &entity.IdempotencyKey{}
+	err := r.db.QueryRowContext(ctx, query, userID, key).Scan(
+		&k.UserID, &k.Key, &k.Method, &k.RequestHash, &k.Response, &k.CreatedAt, &k.ExpiresAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	return k, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, userID int64, key string, response []byte) error {
+	const query = `UPDATE idempotency_keys SET response = $3 WHERE user_id = $1 AND key = $2 AND response IS NULL`
+	result, err := r.db.ExecContext(ctx, query, userID, key, response)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+	return expectAffected(result)
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, userID int64, key string) error {
+	const query = `DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2 AND response IS NULL`
+	if _, err := r.db.ExecContext(ctx, query, userID, key); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) Cleanup(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clean up idempotency keys: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,301 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+type moderationRepository struct {
+	db *sql.DB
+}
+
+func NewModerationRepository(db *sql.DB) repository.ModerationRepository {
+	return &moderationRepository{db: db}
+}
+
+const caseColumns = `id, target_type, target_id, COALESCE(category_id, 0), author_id, reports_count, report_weight, status,
+	auto_hidden, COALESCE(claimed_by, 0), claimed_at, COALESCE(resolved_by, 0), resolved_at,
+	COALESCE(resolution, ''), resolution_note, created_at, updated_at`
+
+func scanCase(row rowScanner) (*entity.ModerationCase, error) {
+	c := &entity.ModerationCase{}
+	err := row.Scan(
+		&c.ID, &c.TargetType, &c.TargetID, &c.CategoryID, &c.AuthorID, &c.ReportsCount, &c.ReportWeight, &c.Status,
+		&c.AutoHidden, &c.ClaimedBy, &c.ClaimedAt, &c.ResolvedBy, &c.ResolvedAt,
+		&c.Resolution, &c.ResolutionNote, &c.CreatedAt, &c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (r *moderationRepository) ContentInfo(ctx context.Context, targetType entity.ContentType, targetID int64) (*entity.ContentRef, error) {
+	var query string
+	switch targetType {
+	case entity.ContentTopic:
+		query = `
+			SELECT t.author_id, t.id, 0, t.category_id, t.status
+			FROM topics t
+			WHERE t.id = $1`
+	case entity.ContentPost:
+		query = `
+			SELECT p.author_id, p.topic_id, p.id, t.category_id, p.status
+			FROM posts p
+			JOIN topics t ON t.id = p.topic_id
+			WHERE p.id = $1`
+	case entity.ContentComment:
+		query = `
+			SELECT c.author_id, p.topic_id, p.id, t.category_id, c.status
+			FROM comments c
+			JOIN posts p ON p.id = c.post_id
+			JOIN topics t ON t.id = p.topic_id
+			WHERE c.id = $1`
+	default:
+		return nil, fmt.Errorf("unknown content type %q", targetType)
+	}
+
+	ref := &entity.ContentRef{Type: targetType, ID: targetID}
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, targetID).Scan(
+		&ref.AuthorID, &ref.TopicID, &ref.PostID, &ref.CategoryID, &ref.Status,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", targetType, err)
+	}
+	return ref, nil
+}
+
+func (r *moderationRepository) AddReport(ctx context.Context, ref *entity.ContentRef, report *entity.Report) (*entity.ModerationCase, error) {
+	var c *entity.ModerationCase
+	err := inTx(ctx, r.db, func(q querier) error {
+		const upsertCase = `
+			INSERT INTO moderation_cases (target_type, target_id, category_id, author_id, created_at, updated_at)
+			VALUES ($1, $2, NULLIF($3, 0), $4, $5, $5)
+			ON CONFLICT (target_type, target_id) WHERE status <> 'resolved'
+			DO UPDATE SET updated_at = EXCLUDED.updated_at
+			RETURNING id
+		`
+		if err := q.QueryRowContext(ctx, upsertCase,
+			ref.Type, ref.ID, ref.CategoryID, ref.AuthorID, report.CreatedAt,
+		).Scan(&report.CaseID); err != nil {
+			return fmt.Errorf("failed to open moderation case: %w", err)
+		}
+
+		const insertReport = `
+			INSERT INTO reports (case_id, target_type, target_id, reporter_id, reason, comment, weight, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (case_id, reporter_id) DO NOTHING
+			RETURNING id
+		`
+		err := q.QueryRowContext(ctx, insertReport,
+			report.CaseID, ref.Type, ref.ID, report.ReporterID, report.Reason, report.Comment, report.Weight, report.CreatedAt,
+		).Scan(&report.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.ErrAlreadyExists
+		}
+		if err != nil {
+			return fmt.Errorf("failed to add report: %w", err)
+		}
+
+		c, err = scanCase(q.QueryRowContext(ctx, `
+			UPDATE moderation_cases SET reports_count = reports_count + 1, report_weight = report_weight + $2
+			WHERE id = $1
+			RETURNING `+caseColumns, report.CaseID, report.Weight))
+		if err != nil {
+			return fmt.Errorf("failed to update moderation case: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (r *moderationRepository) GetCase(ctx context.Context, id int64) (*entity.ModerationCase, error) {
+	c, err := scanCase(conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT `+caseColumns+` FROM moderation_cases WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moderation case: %w", err)
+	}
+	return c, nil
+}
+
+func (r *moderationRepository) OpenCase(ctx context.Context, targetType entity.ContentType, targetID int64) (*entity.ModerationCase, error) {
+	c, err := scanCase(conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT `+caseColumns+` FROM moderation_cases WHERE target_type = $1 AND target_id = $2 AND status <> 'resolved'`,
+		targetType, targetID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open moderation case: %w", err)
+	}
+	return c, nil
+}
+
+func (r *moderationRepository) ListCases(ctx context.Context, filter repository.CaseFilter, limit, offset int) ([]*entity.ModerationCase, int64, error) {
+	where := ` WHERE status <> 'resolved'`
+	args := []any{}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where = fmt.Sprintf(` WHERE status = $%d`, len(args))
+	}
+	if filter.CategoryID > 0 {
+		args = append(args, filter.CategoryID)
+		where += fmt.Sprintf(` AND category_id = $%d`, len(args))
+	}
+	if filter.TargetType != "" {
+		args = append(args, filter.TargetType)
+		where += fmt.Sprintf(` AND target_type = $%d`, len(args))
+	}
+	if filter.Reason != "" {
+		args = append(args, filter.Reason)
+		where += fmt.Sprintf(` AND id IN (SELECT case_id FROM reports WHERE reason = $%d)`, len(args))
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM moderation_cases`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count moderation cases: %w", err)
+	}
+
+	// Сверху — объекты с наибольшим числом жалоб, при равенстве — самые старые
+	query := `SELECT ` + caseColumns + ` FROM moderation_cases` + where +
+		fmt.Sprintf(` ORDER BY reports_count DESC, id LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list moderation cases: %w", err)
+	}
+	defer rows.Close()
+
+	cases := []*entity.ModerationCase{}
+	for rows.Next() {
+		c, err := scanCase(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan moderation case: %w", err)
+		}
+		cases = append(cases, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows error: %w", err)
+	}
+	return cases, total, nil
+}
+
+func (r *moderationRepository) ListReports(ctx context.Context, caseID int64) ([]*entity.Report, error) {
+	const query = `
+		SELECT id, case_id, target_type, target_id, reporter_id, reason, comment, weight, created_at
+		FROM reports
+		WHERE case_id = $1
+		ORDER BY id
+	`
+	rows, err := r.db.QueryContext(ctx, query, caseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []*entity.Report{}
+	for rows.Next() {
+		rep := &entity.Report{}
+		if err := rows.Scan(
+			&rep.ID, &rep.CaseID, &rep.TargetType, &rep.TargetID, &rep.ReporterID, &rep.Reason, &rep.Comment, &rep.Weight, &rep.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan report: %w", err)
+		}
+		reports = append(reports, rep)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return reports, nil
+}
+
+func (r *moderationRepository) ClaimCase(ctx context.Context, id, moderatorID int64, claimTTL time.Duration, at time.Time) (*entity.ModerationCase, error) {
+	query := `
+		UPDATE moderation_cases
+		SET status = 'claimed', claimed_by = $2, claimed_at = $3, updated_at = $3
+		WHERE id = $1
+			AND (status = 'open' OR (status = 'claimed' AND (claimed_by = $2 OR claimed_at < $4)))
+		RETURNING ` + caseColumns
+	c, err := scanCase(conn(ctx, r.db).QueryRowContext(ctx, query, id, moderatorID, at, at.Add(-claimTTL)))
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, getErr := r.GetCase(ctx, id); getErr != nil {
+			return nil, getErr
+		}
+		return nil, repository.ErrConflict
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim moderation case: %w", err)
+	}
+	return c, nil
+}
+
+func (r *moderationRepository) ResolveCase(ctx context.Context, c *entity.ModerationCase) error {
+	const query = `
+		UPDATE moderation_cases
+		SET status = 'resolved', resolved_by = $2, resolved_at = $3, resolution = $4, resolution_note = $5, updated_at = $3
+		WHERE id = $1 AND status <> 'resolved' AND (status = 'open' OR claimed_by = $2)
+	`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, c.ID, c.ResolvedBy, c.ResolvedAt, c.Resolution, c.ResolutionNote)
+	if err != nil {
+		return fmt.Errorf("failed to resolve moderation case: %w", err)
+	}
+	if err := expectAffected(result); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return repository.ErrConflict
+		}
+		return err
+	}
+	c.Status = entity.CaseResolved
+	return nil
+}
+
+func (r *moderationRepository) MarkAutoHidden(ctx context.Context, caseID int64) error {
+	const query = `UPDATE moderation_cases SET auto_hidden = true WHERE id = $1`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, caseID)
+	if err != nil {
+		return fmt.Errorf("failed to mark case auto-hidden: %w", err)
+	}
+	return expectAffected(result)
+}
+
+func (r *moderationRepository) SetContentStatus(ctx context.Context, targetType entity.ContentType, targetID int64, status entity.Status) error {
+	var table string
+	switch targetType {
+	case entity.ContentTopic:
+		table = "topics"
+	case entity.ContentPost:
+		table = "posts"
+	case entity.ContentComment:
+		table = "comments"
+	default:
+		return fmt.Errorf("unknown content type %q", targetType)
+	}
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE `+table+` SET status = $2 WHERE id = $1`, targetID, status)
+	if err != nil {
+		return fmt.Errorf("failed to set %s status: %w", targetType, err)
+	}
+	return expectAffected(result)
+}
+
+func (r *moderationRepository) SetPendingPostsStatus(ctx context.Context, topicID int64, status entity.Status) error {
+	const query = `UPDATE posts SET status = $2 WHERE topic_id = $1 AND status = $3`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, topicID, status, entity.StatusPending); err != nil {
+		return fmt.Errorf("failed to set pending posts status: %w", err)
+	}
+	return nil
+}
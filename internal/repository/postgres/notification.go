@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+	"github.com/lib/pq"
+)
+
+type notificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) repository.NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// CreateForSubscribers раскладывает уведомление по подписчикам одним запросом.
+// Если у пользователя несколько подходящих подписок, решает самая конкретная:
+// топик, затем тег, затем категория — mute топика глушит подписку на категорию и наоборот.
+func (r *notificationRepository) CreateForSubscribers(ctx context.Context, n *entity.Notification, categoryIDs []int64) (int64, error) {
+	const query = `
+		INSERT INTO notifications (user_id, kind, actor_id, actor_nickname, topic_id, post_id, comment_id, created_at)
+		SELECT s.user_id, $1, $2, $3, $4, $5, NULLIF($6, 0), $7
+		FROM subscriptions s
+		WHERE s.user_id <> $2
+			AND (
+				(s.target_type = 'topic' AND s.target_id = $4)
+				OR (s.target_type = 'category' AND s.target_id = ANY($8))
+				OR (s.target_type = 'tag' AND s.target_id IN (SELECT tag_id FROM post_tags WHERE post_id = $5))
+			)
+		GROUP BY s.user_id
+		HAVING NOT COALESCE(
+			bool_or(s.muted) FILTER (WHERE s.target_type = 'topic'),
+			bool_or(s.muted) FILTER (WHERE s.target_type = 'tag'),
+			bool_or(s.muted)
+		)
+	`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
+		n.Kind,
+		n.ActorID,
+		n.ActorNickname,
+		n.TopicID,
+		n.PostID,
+		n.CommentID,
+		n.CreatedAt,
+		pq.Array(categoryIDs),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create notifications: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func (r *notificationRepository) Create(ctx context.Context, n *entity.Notification) error {
+	const query = `
+		INSERT INTO notifications (user_id, kind, actor_id, actor_nickname, topic_id, post_id, comment_id, text, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, 0), NULLIF($6, 0), NULLIF($7, 0), $8, $9)
+		RETURNING id
+	`
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		n.UserID,
+		n.Kind,
+		n.ActorID,
+		n.ActorNickname,
+		n.TopicID,
+		n.PostID,
+		n.CommentID,
+		n.Text,
+		n.CreatedAt,
+	).Scan(&n.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+	return nil
+}
+
+func (r *notificationRepository) ListByUser(ctx context.Context, userID int64, unreadOnly bool, limit, offset int) ([]*entity.Notification, int64, error) {
+	filter := `WHERE user_id = $1`
+	if unreadOnly {
+		filter += ` AND read_at IS NULL`
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM notifications `+filter, userID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	query := `
+		SELECT id, user_id, kind, actor_id, actor_nickname,
+			COALESCE(topic_id, 0), COALESCE(post_id, 0), COALESCE(comment_id, 0), text, created_at, read_at
+		FROM notifications ` + filter + `
+		ORDER BY id DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []*entity.Notification{}
+	for rows.Next() {
+		n := &entity.Notification{}
+		if err := rows.Scan(
+			&n.ID, &n.UserID, &n.Kind, &n.ActorID, &n.ActorNickname,
+			&n.TopicID, &n.PostID, &n.CommentID, &n.Text, &n.CreatedAt, &n.ReadAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows error: %w", err)
+	}
+	return notifications, total, nil
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, userID int64, ids []int64, at time.Time) (int64, error) {
+	query := `UPDATE notifications SET read_at = $2 WHERE user_id = $1 AND read_at IS NULL`
+	args := []any{userID, at}
+	if len(ids) > 0 {
+		query += ` AND id = ANY($3)`
+		args = append(args, pq.Array(ids))
+	}
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func (r *notificationRepository) UnreadCount(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	const query = `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+	"github.com/lib/pq"
+)
+
+type outboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) repository.OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) Add(ctx context.Context, events ...*entity.Event) error {
+	const query = `
+		INSERT INTO outbox_events (type, version, aggregate_type, aggregate_id, payload, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, txid
+	`
+	q := conn(ctx, r.db)
+	for _, e := range events {
+		err := q.QueryRowContext(ctx, query,
+			e.Type,
+			e.Version,
+			e.AggregateType,
+			e.AggregateID,
+			[]byte(e.Payload),
+			e.OccurredAt,
+		).Scan(&e.ID, &e.TxID)
+		if err != nil {
+			return fmt.Errorf("failed to add outbox event: %w", err)
+		}
+	}
+	return nil
+}
+
+const outboxSelect = `
+	SELECT id, txid, type, version, aggregate_type, aggregate_id, payload, occurred_at
+	FROM outbox_events
+`
+
+// outboxSettled — события транзакций старше самой старой незавершённой: новых с меньшим курсором уже не появится.
+const outboxSettled = `txid < pg_snapshot_xmin(pg_current_snapshot())::text::bigint`
+
+func (r *outboxRepository) ListPending(ctx context.Context, limit int) ([]*entity.Event, error) {
+	return r.list(ctx, outboxSelect+` WHERE dispatched_at IS NULL ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED`, limit)
+}
+
+func (r *outboxRepository) ListAfter(ctx context.Context, after entity.EventCursor, limit int) ([]*entity.Event, error) {
+	return r.list(ctx, outboxSelect+` WHERE (txid, id) > ($1, $2) AND `+outboxSettled+` ORDER BY txid, id LIMIT $3`,
+		after.TxID, after.ID, limit)
+}
+
+func (r *outboxRepository) Head(ctx context.Context) (entity.EventCursor, error) {
+	var c entity.EventCursor
+	err := r.db.QueryRowContext(ctx, `
+		SELECT txid, id FROM outbox_events
+		WHERE `+outboxSettled+`
+		ORDER BY txid DESC, id DESC
+		LIMIT 1
+	`).Scan(&c.TxID, &c.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.EventCursor{}, nil
+	}
+	if err != nil {
+		return entity.EventCursor{}, fmt.Errorf("failed to get outbox head: %w", err)
+	}
+	return c, nil
+}
+
+func (r *outboxRepository) list(ctx context.Context, query string, args ...any) ([]*entity.Event, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []*entity.Event{}
+	for rows.Next() {
+		e := &entity.Event{}
+		var payload []byte
+		if err := rows.Scan(&e.ID, &e.TxID, &e.Type, &e.Version, &e.AggregateType, &e.AggregateID, &payload, &e.OccurredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		e.Payload = payload
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return events, nil
+}
+
+func (r *outboxRepository) MarkDispatched(ctx context.Context, ids []int64, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	const query = `UPDATE outbox_events SET dispatched_at = $2 WHERE id = ANY($1)`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, pq.Array(ids), at); err != nil {
+		return fmt.Errorf("failed to mark outbox events dispatched: %w", err)
+	}
+	return nil
+}
+
+func (r *outboxRepository) DeleteDispatched(ctx context.Context, before time.Time, limit int) (int64, error) {
+	const query = `
+		DELETE FROM outbox_events
+		WHERE id IN (
+			SELECT id FROM outbox_events
+			WHERE dispatched_at IS NOT NULL AND dispatched_at < $1
+			ORDER BY id
+			LIMIT $2
+		)
+	`
+	result, err := r.db.ExecContext(ctx, query, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete dispatched outbox events: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -21,34 +22,41 @@ func NewPostRepository(db *sql.DB) repository.PostRepository {
 
 func (r *postRepository) Create(ctx context.Context, post *entity.Post) (int64, error) {
 	const query = `
-	INSERT INTO posts (topic_id, title, content, author_id, author_nickname, created_at) 
-	VALUES ($1, $2, $3, $4, $5, $6) 
+	INSERT INTO posts (topic_id, title, content, author_id, author_nickname, created_at, status) 
+	VALUES ($1, $2, $3, $4, $5, $6, $7) 
 	RETURNING id
 	`
+	if post.Status == 0 {
+		post.Status = entity.StatusActive
+	}
 
-	err := r.db.QueryRowContext(ctx, query,
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
 		post.TopicID,
 		post.Title,
 		post.Content,
 		post.AuthorID,
 		post.AuthorNickname, // добавлено
 		post.CreatedAt,
+		post.Status,
 	).Scan(&post.ID)
 
 	if err != nil {
 		return 0, fmt.Errorf("failed to create post: %w", err)
 	}
+	post.Version = 1
 
 	return post.ID, nil
 }
 
 func (r *postRepository) GetByID(ctx context.Context, id int64) (*entity.Post, error) {
-	const query = `SELECT id, topic_id, title, content, author_id, author_nickname, created_at, updated_at 
+	const query = `SELECT id, topic_id, title, content, author_id, author_nickname, created_at, updated_at, status, wiki,
+               COALESCE(edited_by, 0), edited_at, version
                FROM posts WHERE id = $1`
 
 	row := r.db.QueryRowContext(ctx, query, id)
 	var post entity.Post
-	if err := row.Scan(&post.ID, &post.TopicID, &post.Title, &post.Content, &post.AuthorID, &post.AuthorNickname, &post.CreatedAt, &post.UpdatedAt); err != nil {
+	if err := row.Scan(&post.ID, &post.TopicID, &post.Title, &post.Content, &post.AuthorID, &post.AuthorNickname, &post.CreatedAt, &post.UpdatedAt, &post.Status, &post.Wiki,
+		&post.EditedBy, &post.EditedAt, &post.Version); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("post not found: %w", err)
 		}
@@ -58,30 +66,38 @@ func (r *postRepository) GetByID(ctx context.Context, id int64) (*entity.Post, e
 }
 
 func (r *postRepository) Update(ctx context.Context, post *entity.Post) error {
+	return inTx(ctx, r.db, func(tx querier) error {
+		return r.update(ctx, tx, post)
+	})
+}
+
+func (r *postRepository) update(ctx context.Context, tx querier, post *entity.Post) error {
 	// Обновляем пост
 	query := `
 		UPDATE posts
-		SET title = $1, content = $2, author_nickname = $3, updated_at = $4
-		WHERE id = $5
+		SET title = $1, content = $2, author_nickname = $3, updated_at = $4, edited_by = NULLIF($5, 0), edited_at = $6,
+			version = version + 1
+		WHERE id = $7 AND ($8 = 0 OR version = $8)
+		RETURNING version
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	err := tx.QueryRowContext(ctx, query,
 		post.Title,
 		post.Content,
 		post.AuthorNickname, // добавлено
 		time.Now().UTC(),
+		post.EditedBy,
+		post.EditedAt,
 		post.ID,
-	)
+		post.Version,
+	).Scan(&post.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return versionMissError(ctx, r.db, "posts", post.ID)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to update post: %w", err)
 	}
 
 	// Обновляем связи с тегами (полная замена)
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin tx: %w", err)
-	}
-	defer tx.Rollback()
-
 	// Удаляем старые теги
 	_, err = tx.ExecContext(ctx, "DELETE FROM post_tags WHERE post_id = $1", post.ID)
 	if err != nil {
@@ -105,16 +121,12 @@ func (r *postRepository) Update(ctx context.Context, post *entity.Post) error {
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit tx: %w", err)
-	}
-
 	return nil
 }
 
 func (r *postRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM posts WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete post: %w", err)
 	}
@@ -131,20 +143,32 @@ func (r *postRepository) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
+func (r *postRepository) SetWiki(ctx context.Context, id int64, wiki bool) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE posts SET wiki = $1, version = version + 1 WHERE id = $2`, wiki, id)
+	if err != nil {
+		return fmt.Errorf("failed to set post wiki: %w", err)
+	}
+	return expectAffected(result)
+}
+
 func (r *postRepository) ListByTag(ctx context.Context, tagID int64, limit, offset int) ([]*entity.Post, int64, error) {
 	// 1) Получаем общее количество
 	var total int64
-	countQuery := `SELECT COUNT(*) FROM post_tags WHERE tag_id = $1`
+	countQuery := `
+		SELECT COUNT(*) FROM post_tags pt
+		JOIN posts p ON p.id = pt.post_id
+		WHERE pt.tag_id = $1 AND p.status = 1
+	`
 	if err := r.db.QueryRowContext(ctx, countQuery, tagID).Scan(&total); err != nil {
 		return nil, 0, fmt.Errorf("failed to count posts by tag: %w", err)
 	}
 
 	// 2) Получаем сами посты
 	query := `
-		SELECT p.id, p.topic_id, p.title, p.content, p.author_id, p.author_nickname, p.created_at
+		SELECT p.id, p.topic_id, p.title, p.content, p.author_id, p.author_nickname, p.created_at, p.updated_at
 		FROM posts p
 		JOIN post_tags pt ON p.id = pt.post_id
-		WHERE pt.tag_id = $1
+		WHERE pt.tag_id = $1 AND p.status = 1
 		ORDER BY p.created_at DESC
 		LIMIT $2 OFFSET $3
 	`
@@ -171,7 +195,7 @@ func (r *postRepository) ListByTag(ctx context.Context, tagID int64, limit, offs
 func (r *postRepository) ListByTopic(ctx context.Context, topicID int64, limit, offset int) ([]*entity.Post, int64, error) {
 	// 1) Get total count
 	var total int64
-	countQuery := `SELECT COUNT(*) FROM posts WHERE topic_id = $1`
+	countQuery := `SELECT COUNT(*) FROM posts WHERE topic_id = $1 AND status = 1`
 	if err := r.db.QueryRowContext(ctx, countQuery, topicID).Scan(&total); err != nil {
 		return nil, 0, fmt.Errorf("failed to count posts by topic: %w", err)
 	}
@@ -180,7 +204,7 @@ func (r *postRepository) ListByTopic(ctx context.Context, topicID int64, limit,
 	query := `
 		SELECT id, topic_id, title, content, author_id, author_nickname, created_at, updated_at
 		FROM posts
-		WHERE topic_id = $1
+		WHERE topic_id = $1 AND status = 1
 		ORDER BY created_at ASC
 		LIMIT $2 OFFSET $3
 	`
@@ -204,20 +228,53 @@ func (r *postRepository) ListByTopic(ctx context.Context, topicID int64, limit,
 	return posts, total, nil
 }
 
-func (r *postRepository) AddView(ctx context.Context, postID, userID int64) error {
-	query := `INSERT INTO post_views (post_id, user_id)
-	 		VALUES ($1, $2)
-			ON CONFLICT (post_id, user_id) DO NOTHING`
-
-	_, err := r.db.ExecContext(ctx, query, postID, userID)
+// AddView записывает просмотр, если от этого зрителя ещё не было просмотра в текущем окне длиной window.
+// Окна фиксированные и считаются по часам базы, поэтому совпадают на всех репликах, а одновременные
+// просмотры одного зрителя упираются в уникальный индекс вместо гонки между проверкой и вставкой.
+func (r *postRepository) AddView(ctx context.Context, postID, userID int64, viewerHash string, window time.Duration) error {
+	const query = `
+		INSERT INTO post_views (post_id, user_id, viewer_hash, bucket)
+		VALUES ($1, NULLIF($2, 0), $3, floor(extract(epoch FROM now()) / GREATEST($4::bigint, 1))::bigint)
+		ON CONFLICT (post_id, viewer_hash, bucket) DO NOTHING
+	`
+	_, err := r.db.ExecContext(ctx, query, postID, userID, viewerHash, int64(window.Seconds()))
 	if err != nil {
 		return fmt.Errorf("failed to add view: %w", err)
 	}
 	return nil
 }
+
+// RollupViews переносит до limit сырых просмотров старше before в post_view_daily.
+// posts.views_count при этом не меняется: триггер срабатывает только на INSERT.
+func (r *postRepository) RollupViews(ctx context.Context, before time.Time, limit int) (int64, error) {
+	query := `
+		WITH moved AS (
+			DELETE FROM post_views
+			WHERE id IN (
+				SELECT id FROM post_views
+				WHERE viewed_at < $1
+				ORDER BY id
+				LIMIT $2
+			)
+			RETURNING post_id, viewed_at
+		), daily AS (
+			INSERT INTO post_view_daily (post_id, day, views)
+			SELECT post_id, (viewed_at AT TIME ZONE 'UTC')::date, COUNT(*)
+			FROM moved
+			GROUP BY 1, 2
+			ON CONFLICT (post_id, day) DO UPDATE SET views = post_view_daily.views + EXCLUDED.views
+		)
+		SELECT COUNT(*) FROM moved
+	`
+	var n int64
+	if err := r.db.QueryRowContext(ctx, query, before, limit).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to roll up post views: %w", err)
+	}
+	return n, nil
+}
 func (r *postRepository) List(ctx context.Context, topicID, tagID int64, limit, offset int) ([]*entity.Post, int64, error) {
 	query := `SELECT id, topic_id, title, content, author_id, author_nickname, created_at, updated_at
-		FROM posts WHERE 1=1`
+		FROM posts WHERE status = 1` // скрытые модерацией посты в списки не попадают
 	args := []interface{}{}
 	idx := 1
 
@@ -265,7 +322,7 @@ func (r *postRepository) Search(ctx context.Context, query string, limit, offset
 	tsquery := fmt.Sprintf("%s:*", strings.Join(strings.Fields(query), " & "))
 
 	var total int64
-	countQuery := `SELECT COUNT(*) FROM posts WHERE search_vector @@ to_tsquery('english', $1)`
+	countQuery := `SELECT COUNT(*) FROM posts WHERE status = 1 AND search_vector @@ to_tsquery('english', $1)`
 	if err := r.db.QueryRowContext(ctx, countQuery, tsquery).Scan(&total); err != nil {
 		return nil, 0, fmt.Errorf("failed to count posts: %w", err)
 	}
@@ -273,7 +330,7 @@ func (r *postRepository) Search(ctx context.Context, query string, limit, offset
 	searchQuery := `
 		SELECT id, topic_id, title, content, author_id, author_nickname, created_at, updated_at
 		FROM posts
-		WHERE search_vector @@ to_tsquery('english', $1)
+		WHERE status = 1 AND search_vector @@ to_tsquery('english', $1)
 		ORDER BY ts_rank_cd(search_vector, to_tsquery('english', $1)) DESC
 		LIMIT $2 OFFSET $3
 	`
@@ -296,3 +353,45 @@ func (r *postRepository) Search(ctx context.Context, query string, limit, offset
 
 	return posts, total, nil
 }
+
+// ListTop возвращает посты по убыванию рейтинга за период; topicID = 0 — по всему форуму.
+func (r *postRepository) ListTop(ctx context.Context, period entity.RankingPeriod, topicID int64, limit, offset int) ([]*entity.Post, int64, error) {
+	column, err := rankingColumn(period)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	from := ` FROM posts p JOIN post_scores s ON s.post_id = p.id WHERE p.status = 1`
+	args := []any{}
+	if topicID > 0 {
+		from += ` AND p.topic_id = $1`
+		args = append(args, topicID)
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*)`+from, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count top posts: %w", err)
+	}
+
+	query := `SELECT p.id, p.topic_id, p.title, p.content, p.author_id, p.author_nickname, p.created_at, p.updated_at` + from +
+		fmt.Sprintf(" ORDER BY s.%s DESC, p.id DESC LIMIT $%d OFFSET $%d", column, len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list top posts: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []*entity.Post
+	for rows.Next() {
+		p := new(entity.Post)
+		if err := rows.Scan(
+			&p.ID, &p.TopicID, &p.Title, &p.Content, &p.AuthorID, &p.AuthorNickname, &p.CreatedAt, &p.UpdatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan post: %w", err)
+		}
+		posts = append(posts, p)
+	}
+	return posts, total, nil
+}
@@ -0,0 +1,228 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+	"github.com/lib/pq"
+)
+
+type rankingRepository struct {
+	db *sql.DB
+}
+
+func NewRankingRepository(db *sql.DB) repository.RankingRepository {
+	return &rankingRepository{db: db}
+}
+
+// У лайков нет времени, поэтому в окно попадают лайки постов, созданных в этом окне.
+// Просмотры за всё время берутся из счётчиков: сырые post_views со временем сворачиваются.
+func (r *rankingRepository) TopicActivity(ctx context.Context, now time.Time, afterID int64, limit int) ([]*entity.ActivityStats, error) {
+	const query = `
+		SELECT t.id, t.created_at,
+			ps.day, ps.week, ps.month, ps.total,
+			cs.day, cs.week, cs.month, cs.total,
+			ps.likes_day, ps.likes_week, ps.likes_month, ps.likes_total,
+			vs.day, vs.week, vs.month, t.views_count
+		FROM topics t
+		LEFT JOIN LATERAL (
+			SELECT
+				COUNT(*) FILTER (WHERE p.created_at > $1) AS day,
+				COUNT(*) FILTER (WHERE p.created_at > $2) AS week,
+				COUNT(*) FILTER (WHERE p.created_at > $3) AS month,
+				COUNT(*) AS total,
+				COALESCE(SUM(p.likes_count) FILTER (WHERE p.created_at > $1), 0) AS likes_day,
+				COALESCE(SUM(p.likes_count) FILTER (WHERE p.created_at > $2), 0) AS likes_week,
+				COALESCE(SUM(p.likes_count) FILTER (WHERE p.created_at > $3), 0) AS likes_month,
+				COALESCE(SUM(p.likes_count), 0) AS likes_total
+			FROM posts p
+			WHERE p.topic_id = t.id AND p.status = 1
+		) ps ON true
+		LEFT JOIN LATERAL (
+			SELECT
+				COUNT(*) FILTER (WHERE c.created_at > $1) AS day,
+				COUNT(*) FILTER (WHERE c.created_at > $2) AS week,
+				COUNT(*) FILTER (WHERE c.created_at > $3) AS month,
+				COUNT(*) AS total
+			FROM comments c
+			JOIN posts p ON p.id = c.post_id
+			WHERE p.topic_id = t.id AND p.status = 1 AND c.status = 1
+		) cs ON true
+		LEFT JOIN LATERAL (
+			SELECT
+				COUNT(*) FILTER (WHERE v.viewed_at > $1) AS day,
+				COUNT(*) FILTER (WHERE v.viewed_at > $2) AS week,
+				COUNT(*) AS month
+			FROM post_views v
+			JOIN posts p ON p.id = v.post_id
+			WHERE p.topic_id = t.id AND v.viewed_at > $3
+		) vs ON true
+		WHERE t.id > $4 AND t.moved_to_id IS NULL AND t.status = 1
+		ORDER BY t.id
+		LIMIT $5
+	`
+	return r.queryActivity(ctx, query, now, afterID, limit)
+}
+
+func (r *rankingRepository) PostActivity(ctx context.Context, now time.Time, afterID int64, limit int) ([]*entity.ActivityStats, error) {
+	const query = `
+		SELECT p.id, p.created_at,
+			0, 0, 0, 0,
+			cs.day, cs.week, cs.month, cs.total,
+			CASE WHEN p.created_at > $1 THEN p.likes_count ELSE 0 END,
+			CASE WHEN p.created_at > $2 THEN p.likes_count ELSE 0 END,
+			CASE WHEN p.created_at > $3 THEN p.likes_count ELSE 0 END,
+			p.likes_count,
+			vs.day, vs.week, vs.month, p.views_count
+		FROM posts p
+		LEFT JOIN LATERAL (
+			SELECT
+				COUNT(*) FILTER (WHERE c.created_at > $1) AS day,
+				COUNT(*) FILTER (WHERE c.created_at > $2) AS week,
+				COUNT(*) FILTER (WHERE c.created_at > $3) AS month,
+				COUNT(*) AS total
+			FROM comments c
+			WHERE c.post_id = p.id AND c.status = 1
+		) cs ON true
+		LEFT JOIN LATERAL (
+			SELECT
+				COUNT(*) FILTER (WHERE v.viewed_at > $1) AS day,
+				COUNT(*) FILTER (WHERE v.viewed_at > $2) AS week,
+				COUNT(*) AS month
+			FROM post_views v
+			WHERE v.post_id = p.id AND v.viewed_at > $3
+		) vs ON true
+		WHERE p.id > $4 AND p.status = 1
+		ORDER BY p.id
+		LIMIT $5
+	`
+	return r.queryActivity(ctx, query, now, afterID, limit)
+}
+
+func (r *rankingRepository) queryActivity(ctx context.Context, query string, now time.Time, afterID int64, limit int) ([]*entity.ActivityStats, error) {
+	rows, err := r.db.QueryContext(ctx, query,
+		now.Add(-24*time.Hour),
+		now.Add(-7*24*time.Hour),
+		now.Add(-30*24*time.Hour),
+		afterID,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load activity: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []*entity.ActivityStats
+	for rows.Next() {
+		s := &entity.ActivityStats{}
+		if err := rows.Scan(
+			&s.ID, &s.CreatedAt,
+			&s.Day.Posts, &s.Week.Posts, &s.Month.Posts, &s.All.Posts,
+			&s.Day.Comments, &s.Week.Comments, &s.Month.Comments, &s.All.Comments,
+			&s.Day.Likes, &s.Week.Likes, &s.Month.Likes, &s.All.Likes,
+			&s.Day.Views, &s.Week.Views, &s.Month.Views, &s.All.Views,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan activity: %w", err)
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return stats, nil
+}
+
+func (r *rankingRepository) SaveTopicScores(ctx context.Context, scores []entity.Scores) error {
+	return r.saveScores(ctx, "topic_scores", "topic_id", scores)
+}
+
+func (r *rankingRepository) SavePostScores(ctx context.Context, scores []entity.Scores) error {
+	return r.saveScores(ctx, "post_scores", "post_id", scores)
+}
+
+func (r *rankingRepository) saveScores(ctx context.Context, table, idColumn string, scores []entity.Scores) error {
+	if len(scores) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(scores))
+	hot := make([]float64, len(scores))
+	day := make([]float64, len(scores))
+	week := make([]float64, len(scores))
+	month := make([]float64, len(scores))
+	all := make([]float64, len(scores))
+	for i, s := range scores {
+		ids[i], hot[i], day[i], week[i], month[i], all[i] = s.ID, s.Hot, s.Day, s.Week, s.Month, s.All
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %[1]s (%[2]s, hot, day, week, month, all_time, computed_at)
+		SELECT *, now()
+		FROM unnest($1::bigint[], $2::float8[], $3::float8[], $4::float8[], $5::float8[], $6::float8[])
+		ON CONFLICT (%[2]s) DO UPDATE SET
+			hot = EXCLUDED.hot,
+			day = EXCLUDED.day,
+			week = EXCLUDED.week,
+			month = EXCLUDED.month,
+			all_time = EXCLUDED.all_time,
+			computed_at = EXCLUDED.computed_at
+	`, table, idColumn)
+
+	_, err := r.db.ExecContext(ctx, query,
+		pq.Array(ids), pq.Array(hot), pq.Array(day), pq.Array(week), pq.Array(month), pq.Array(all),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save %s: %w", table, err)
+	}
+	return nil
+}
+
+// PruneTopicScores удаляет рейтинги топиков, которые перенесли, удалили, скрыли или отправили на модерацию:
+// пересчёт их больше не обновляет.
+func (r *rankingRepository) PruneTopicScores(ctx context.Context) (int64, error) {
+	const query = `
+		DELETE FROM topic_scores s
+		USING topics t
+		WHERE s.topic_id = t.id AND (t.moved_to_id IS NOT NULL OR t.status <> 1)
+	`
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune topic_scores: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func (r *rankingRepository) PrunePostScores(ctx context.Context) (int64, error) {
+	const query = `
+		DELETE FROM post_scores s
+		USING posts p
+		WHERE s.post_id = p.id AND p.status <> 1
+	`
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune post_scores: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// rankingColumn возвращает колонку topic_scores/post_scores для периода.
+func rankingColumn(period entity.RankingPeriod) (string, error) {
+	switch period {
+	case entity.RankingHot:
+		return "hot", nil
+	case entity.RankingDay:
+		return "day", nil
+	case entity.RankingWeek:
+		return "week", nil
+	case entity.RankingMonth:
+		return "month", nil
+	case entity.RankingAll:
+		return "all_time", nil
+	default:
+		return "", fmt.Errorf("unknown ranking period %q", period)
+	}
+}
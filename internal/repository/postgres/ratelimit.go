@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+type rateLimitRepository struct {
+	db *sql.DB
+}
+
+func NewRateLimitRepository(db *sql.DB) repository.RateLimitRepository {
+	return &rateLimitRepository{db: db}
+}
+
+// Take пополняет и списывает корзину одним UPSERT. Время берётся из базы,
+// чтобы расхождение часов между репликами не влияло на лимит.
+func (r *rateLimitRepository) Take(ctx context.Context, key string, limit entity.RateLimit) (time.Duration, error) {
+	const take = `
+		INSERT INTO rate_limit_buckets AS b (key, tokens, updated_at)
+		VALUES ($1, $2 - 1, clock_timestamp())
+		ON CONFLICT (key) DO UPDATE SET
+			tokens = LEAST($2, b.tokens + GREATEST(EXTRACT(EPOCH FROM clock_timestamp() - b.updated_at), 0) * $3) - 1,
+			updated_at = clock_timestamp()
+		WHERE LEAST($2, b.tokens + GREATEST(EXTRACT(EPOCH FROM clock_timestamp() - b.updated_at), 0) * $3) >= 1
+		RETURNING tokens
+	`
+	capacity, rate := limit.Capacity(), limit.TokensPerSecond()
+
+	var tokens float64
+	err := r.db.QueryRowContext(ctx, take, key, capacity, rate).Scan(&tokens)
+	if err == nil {
+		return 0, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+
+	// Токена нет — считаем, когда он появится
+	const current = `
+		SELECT LEAST($2, tokens + GREATEST(EXTRACT(EPOCH FROM clock_timestamp() - updated_at), 0) * $3)
+		FROM rate_limit_buckets
+		WHERE key = $1
+	`
+	if err := r.db.QueryRowContext(ctx, current, key, capacity, rate).Scan(&tokens); err != nil {
+		return 0, fmt.Errorf("failed to read rate limit bucket: %w", err)
+	}
+	return retryAfter(tokens, rate), nil
+}
+
+func (r *rateLimitRepository) Cleanup(ctx context.Context, idle time.Duration) (int64, error) {
+	const query = `DELETE FROM rate_limit_buckets WHERE updated_at < clock_timestamp() - $1 * interval '1 second'`
+	result, err := r.db.ExecContext(ctx, query, idle.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to clean up rate limit buckets: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// retryAfter — через сколько в корзине с tokens накопится целый токен.
+func retryAfter(tokens, rate float64) time.Duration {
+	wait := time.Duration(math.Ceil((1 - tokens) / rate * float64(time.Second)))
+	return max(wait, time.Millisecond)
+}
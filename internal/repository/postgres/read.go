@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+	"github.com/lib/pq"
+)
+
+type readRepository struct {
+	db *sql.DB
+}
+
+func NewReadRepository(db *sql.DB) repository.ReadRepository {
+	return &readRepository{db: db}
+}
+
+func (r *readRepository) MarkRead(ctx context.Context, userID, topicID, postID int64, at time.Time) error {
+	const query = `
+		INSERT INTO topic_reads (user_id, topic_id, last_read_post_id, read_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, topic_id) DO UPDATE
+		SET last_read_post_id = GREATEST(topic_reads.last_read_post_id, EXCLUDED.last_read_post_id),
+			read_at = EXCLUDED.read_at
+	`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, userID, topicID, postID, at); err != nil {
+		return fmt.Errorf("failed to mark topic read: %w", err)
+	}
+	return nil
+}
+
+func (r *readRepository) MarkTopicRead(ctx context.Context, userID, topicID int64, at time.Time) error {
+	const query = `
+		INSERT INTO topic_reads (user_id, topic_id, last_read_post_id, read_at)
+		SELECT $1, t.id, COALESCE((SELECT MAX(p.id) FROM posts p WHERE p.topic_id = t.id), 0), $3
+		FROM topics t
+		WHERE t.id = $2
+		ON CONFLICT (user_id, topic_id) DO UPDATE
+		SET last_read_post_id = GREATEST(topic_reads.last_read_post_id, EXCLUDED.last_read_post_id),
+			read_at = EXCLUDED.read_at
+	`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, userID, topicID, at)
+	if err != nil {
+		return fmt.Errorf("failed to mark topic read: %w", err)
+	}
+	return expectAffected(result)
+}
+
+func (r *readRepository) MarkCategoriesRead(ctx context.Context, userID int64, categoryIDs []int64, at time.Time) error {
+	if len(categoryIDs) == 0 {
+		return nil
+	}
+	return inTx(ctx, r.db, func(q querier) error {
+		// Маркер — последний пост самой категории, а не глобальный MAX(posts.id): посты, написанные после него
+		// в других категориях, останутся непрочитанными, если их топик перенесут сюда. Посты перенесённого топика
+		// с id меньше маркера всё же считаются прочитанными — время переноса не хранится.
+		const upsert = `
+			INSERT INTO category_reads (user_id, category_id, last_read_post_id, read_at)
+			SELECT $1, c.id, COALESCE((
+				SELECT MAX(p.id)
+				FROM posts p
+				JOIN topics t ON t.id = p.topic_id
+				WHERE t.category_id = c.id
+			), 0), $3
+			FROM categories c
+			WHERE c.id = ANY($2)
+			ON CONFLICT (user_id, category_id) DO UPDATE
+			SET last_read_post_id = GREATEST(category_reads.last_read_post_id, EXCLUDED.last_read_post_id),
+				read_at = EXCLUDED.read_at
+		`
+		if _, err := q.ExecContext(ctx, upsert, userID, pq.Array(categoryIDs), at); err != nil {
+			return fmt.Errorf("failed to mark categories read: %w", err)
+		}
+
+		// Маркеры топиков, которые не продвинулись дальше маркера категории, больше ничего не значат.
+		const prune = `
+			DELETE FROM topic_reads tr
+			USING topics t, category_reads cr
+			WHERE tr.topic_id = t.id
+				AND cr.category_id = t.category_id
+				AND cr.user_id = tr.user_id
+				AND tr.user_id = $1
+				AND t.category_id = ANY($2)
+				AND tr.last_read_post_id <= cr.last_read_post_id
+		`
+		if _, err := q.ExecContext(ctx, prune, userID, pq.Array(categoryIDs)); err != nil {
+			return fmt.Errorf("failed to prune topic reads: %w", err)
+		}
+		return nil
+	})
+}
+
+// ReadStates считает непрочитанное от большего из маркеров топика и категории.
+// Топик новый, если маркера топика нет и он создан после последнего "прочитать всё" в категории;
+// перенесённый в категорию топик сравнивается по дате создания, а не переноса.
+// Непрочитанными считаются только активные посты: удалённые, скрытые и ждущие модерации не в счёт.
+func (r *readRepository) ReadStates(ctx context.Context, userID int64, topicIDs []int64) (map[int64]*entity.ReadState, error) {
+	states := make(map[int64]*entity.ReadState, len(topicIDs))
+	if len(topicIDs) == 0 {
+		return states, nil
+	}
+
+	const query = `
+		SELECT t.id, m.marker,
+			tr.topic_id IS NULL AND (cr.read_at IS NULL OR t.created_at > cr.read_at),
+			u.unread
+		FROM topics t
+		LEFT JOIN topic_reads tr ON tr.topic_id = t.id AND tr.user_id = $1
+		LEFT JOIN category_reads cr ON cr.category_id = t.category_id AND cr.user_id = $1
+		CROSS JOIN LATERAL (
+			SELECT COALESCE(GREATEST(tr.last_read_post_id, cr.last_read_post_id), 0) AS marker
+		) m
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS unread
+			FROM posts p
+			WHERE p.topic_id = t.id AND p.id > m.marker AND p.author_id <> $1 AND p.status = 1
+		) u
+		WHERE t.id = ANY($2)
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID, pq.Array(topicIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get read states: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		s := &entity.ReadState{}
+		if err := rows.Scan(&s.TopicID, &s.LastReadPostID, &s.IsNew, &s.UnreadCount); err != nil {
+			return nil, fmt.Errorf("failed to scan read state: %w", err)
+		}
+		states[s.TopicID] = s
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return states, nil
+}
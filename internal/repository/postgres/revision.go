@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+	"github.com/lib/pq"
+)
+
+type revisionRepository struct {
+	db *sql.DB
+}
+
+func NewRevisionRepository(db *sql.DB) repository.RevisionRepository {
+	return &revisionRepository{db: db}
+}
+
+const revisionColumns = `id, target_type, target_id, number, title, content, editor_id, reason, diff,
+	COALESCE(restored_from, 0), created_at`
+
+func scanRevision(row rowScanner) (*entity.Revision, error) {
+	rev := &entity.Revision{}
+	err := row.Scan(
+		&rev.ID, &rev.TargetType, &rev.TargetID, &rev.Number, &rev.Title, &rev.Content, &rev.EditorID, &rev.Reason, &rev.Diff,
+		&rev.RestoredFrom, &rev.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
+
+func (r *revisionRepository) Add(ctx context.Context, rev *entity.Revision) error {
+	// Параллельная правка получит тот же номер и упадёт на UNIQUE — лучше, чем потерять версию
+	const query = `
+		INSERT INTO revisions (target_type, target_id, number, title, content, editor_id, reason, diff, restored_from, created_at)
+		SELECT $1, $2, COALESCE(MAX(number), 0) + 1, $3, $4, $5, $6, $7, NULLIF($8, 0), $9
+		FROM revisions
+		WHERE target_type = $1 AND target_id = $2
+		RETURNING id, number
+	`
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		rev.TargetType, rev.TargetID, rev.Title, rev.Content, rev.EditorID, rev.Reason, rev.Diff, rev.RestoredFrom, rev.CreatedAt,
+	).Scan(&rev.ID, &rev.Number)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return repository.ErrConflict
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add revision: %w", err)
+	}
+	return nil
+}
+
+func (r *revisionRepository) Latest(ctx context.Context, targetType entity.ContentType, targetID int64) (*entity.Revision, error) {
+	rev, err := scanRevision(conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT `+revisionColumns+` FROM revisions WHERE target_type = $1 AND target_id = $2 ORDER BY number DESC LIMIT 1`,
+		targetType, targetID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest revision: %w", err)
+	}
+	return rev, nil
+}
+
+func (r *revisionRepository) Get(ctx context.Context, targetType entity.ContentType, targetID int64, number int) (*entity.Revision, error) {
+	rev, err := scanRevision(conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT `+revisionColumns+` FROM revisions WHERE target_type = $1 AND target_id = $2 AND number = $3`,
+		targetType, targetID, number))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+	return rev, nil
+}
+
+func (r *revisionRepository) List(ctx context.Context, targetType entity.ContentType, targetID int64, limit, offset int) ([]*entity.Revision, int64, error) {
+	var total int64
+	if err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM revisions WHERE target_type = $1 AND target_id = $2`, targetType, targetID,
+	).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count revisions: %w", err)
+	}
+
+	const query = `
+		SELECT id, target_type, target_id, number, editor_id, reason, COALESCE(restored_from, 0), created_at
+		FROM revisions
+		WHERE target_type = $1 AND target_id = $2
+		ORDER BY number DESC
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.db.QueryContext(ctx, query, targetType, targetID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []*entity.Revision{}
+	for rows.Next() {
+		rev := &entity.Revision{}
+		if err := rows.Scan(
+			&rev.ID, &rev.TargetType, &rev.TargetID, &rev.Number, &rev.EditorID, &rev.Reason, &rev.RestoredFrom, &rev.CreatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows error: %w", err)
+	}
+	return revisions, total, nil
+}
@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+	"github.com/lib/pq"
+)
+
+type sanctionRepository struct {
+	db *sql.DB
+}
+
+func NewSanctionRepository(db *sql.DB) repository.SanctionRepository {
+	return &sanctionRepository{db: db}
+}
+
+const sanctionColumns = `id, user_id, kind, COALESCE(category_id, 0), reason, issued_by, created_at,
+	expires_at, lifted_at, COALESCE(lifted_by, 0)`
+
+func scanSanction(row rowScanner) (*entity.Sanction, error) {
+	s := &entity.Sanction{}
+	err := row.Scan(
+		&s.ID, &s.UserID, &s.Kind, &s.CategoryID, &s.Reason, &s.IssuedBy, &s.CreatedAt,
+		&s.ExpiresAt, &s.LiftedAt, &s.LiftedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *sanctionRepository) Create(ctx context.Context, s *entity.Sanction) error {
+	const query = `
+		INSERT INTO sanctions (user_id, kind, category_id, reason, issued_by, created_at, expires_at)
+		VALUES ($1, $2, NULLIF($3, 0), $4, $5, $6, $7)
+		RETURNING id
+	`
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		s.UserID, s.Kind, s.CategoryID, s.Reason, s.IssuedBy, s.CreatedAt, s.ExpiresAt,
+	).Scan(&s.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create sanction: %w", err)
+	}
+	return nil
+}
+
+func (r *sanctionRepository) GetByID(ctx context.Context, id int64) (*entity.Sanction, error) {
+	s, err := scanSanction(conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT `+sanctionColumns+` FROM sanctions WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sanction: %w", err)
+	}
+	return s, nil
+}
+
+func (r *sanctionRepository) ListActive(ctx context.Context, filter repository.SanctionFilter, at time.Time, limit, offset int) ([]*entity.Sanction, int64, error) {
+	where := ` WHERE lifted_at IS NULL AND (expires_at IS NULL OR expires_at > $1)`
+	args := []any{at}
+	if filter.UserID > 0 {
+		args = append(args, filter.UserID)
+		where += fmt.Sprintf(` AND user_id = $%d`, len(args))
+	}
+	if filter.CategoryID > 0 {
+		args = append(args, filter.CategoryID)
+		where += fmt.Sprintf(` AND category_id = $%d`, len(args))
+	}
+	if filter.Kind != "" {
+		args = append(args, filter.Kind)
+		where += fmt.Sprintf(` AND kind = $%d`, len(args))
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sanctions`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count sanctions: %w", err)
+	}
+
+	query := `SELECT ` + sanctionColumns + ` FROM sanctions` + where +
+		fmt.Sprintf(` ORDER BY id DESC LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+	sanctions, err := r.query(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return sanctions, total, nil
+}
+
+func (r *sanctionRepository) ActiveFor(ctx context.Context, userID int64, categoryIDs []int64, at time.Time) ([]*entity.Sanction, error) {
+	query := `
+		SELECT ` + sanctionColumns + `
+		FROM sanctions
+		WHERE user_id = $1
+			AND lifted_at IS NULL
+			AND (expires_at IS NULL OR expires_at > $2)
+			AND (category_id IS NULL OR category_id = ANY($3))
+		ORDER BY kind = 'ban' DESC, expires_at DESC NULLS FIRST
+	`
+	return r.query(ctx, query, userID, at, pq.Array(categoryIDs))
+}
+
+func (r *sanctionRepository) query(ctx context.Context, query string, args ...any) ([]*entity.Sanction, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sanctions: %w", err)
+	}
+	defer rows.Close()
+
+	sanctions := []*entity.Sanction{}
+	for rows.Next() {
+		s, err := scanSanction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sanction: %w", err)
+		}
+		sanctions = append(sanctions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return sanctions, nil
+}
+
+func (r *sanctionRepository) Lift(ctx context.Context, id, liftedBy int64, at time.Time) error {
+	const query = `UPDATE sanctions SET lifted_at = $2, lifted_by = $3 WHERE id = $1 AND lifted_at IS NULL`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, at, liftedBy)
+	if err != nil {
+		return fmt.Errorf("failed to lift sanction: %w", err)
+	}
+	return expectAffected(result)
+}
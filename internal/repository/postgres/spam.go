@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+	"github.com/lib/pq"
+)
+
+type spamRepository struct {
+	db *sql.DB
+}
+
+func NewSpamRepository(db *sql.DB) repository.SpamRepository {
+	return &spamRepository{db: db}
+}
+
+func (r *spamRepository) AddFingerprint(ctx context.Context, fp *entity.Fingerprint) error {
+	const query = `
+		INSERT INTO content_fingerprints (author_id, content_type, content_id, hash, simhash, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		fp.AuthorID, fp.ContentType, fp.ContentID, fp.Hash, int64(fp.SimHash), fp.CreatedAt,
+	).Scan(&fp.ID)
+	if err != nil {
+		return fmt.Errorf("failed to add content fingerprint: %w", err)
+	}
+	return nil
+}
+
+func (r *spamRepository) RecentFingerprints(ctx context.Context, authorID int64, since time.Time, limit int) ([]*entity.Fingerprint, error) {
+	const query = `
+		SELECT id, author_id, content_type, content_id, hash, simhash, created_at
+		FROM content_fingerprints
+		WHERE author_id = $1 AND created_at > $2
+		ORDER BY created_at DESC
+		LIMIT $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, authorID, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list content fingerprints: %w", err)
+	}
+	defer rows.Close()
+
+	var fps []*entity.Fingerprint
+	for rows.Next() {
+		var (
+			fp      entity.Fingerprint
+			simhash int64
+		)
+		if err := rows.Scan(&fp.ID, &fp.AuthorID, &fp.ContentType, &fp.ContentID, &fp.Hash, &simhash, &fp.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan content fingerprint: %w", err)
+		}
+		fp.SimHash = uint64(simhash)
+		fps = append(fps, &fp)
+	}
+	return fps, rows.Err()
+}
+
+func (r *spamRepository) DeleteFingerprintsBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	const query = `
+		DELETE FROM content_fingerprints
+		WHERE id IN (SELECT id FROM content_fingerprints WHERE created_at < $1 ORDER BY id LIMIT $2)
+	`
+	result, err := r.db.ExecContext(ctx, query, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old content fingerprints: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func (r *spamRepository) TokenStats(ctx context.Context, tokens []string) (map[string]entity.TokenStats, error) {
+	stats := make(map[string]entity.TokenStats, len(tokens))
+	if len(tokens) == 0 {
+		return stats, nil
+	}
+
+	const query = `SELECT token, spam_count, ham_count FROM spam_tokens WHERE token = ANY($1)`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(tokens))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spam token stats: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			token string
+			s     entity.TokenStats
+		)
+		if err := rows.Scan(&token, &s.Spam, &s.Ham); err != nil {
+			return nil, fmt.Errorf("failed to scan spam token stats: %w", err)
+		}
+		stats[token] = s
+	}
+	return stats, rows.Err()
+}
+
+func (r *spamRepository) TrainingDocs(ctx context.Context) (spam, ham int64, err error) {
+	const query = `
+		SELECT COALESCE(SUM(docs) FILTER (WHERE class = 'spam'), 0),
+			COALESCE(SUM(docs) FILTER (WHERE class = 'ham'), 0)
+		FROM spam_training
+	`
+	if err := r.db.QueryRowContext(ctx, query).Scan(&spam, &ham); err != nil {
+		return 0, 0, fmt.Errorf("failed to get spam training stats: %w", err)
+	}
+	return spam, ham, nil
+}
+
+func (r *spamRepository) Train(ctx context.Context, tokens []string, spam bool) error {
+	const upsertTokens = `
+		INSERT INTO spam_tokens AS t (token, spam_count, ham_count)
+		SELECT token, $2, $3 FROM unnest($1::text[]) AS token
+		ON CONFLICT (token) DO UPDATE SET
+			spam_count = t.spam_count + EXCLUDED.spam_count,
+			ham_count = t.ham_count + EXCLUDED.ham_count
+	`
+	const countDoc = `UPDATE spam_training SET docs = docs + 1 WHERE class = $1`
+
+	spamInc, hamInc, class := 0, 1, "ham"
+	if spam {
+		spamInc, hamInc, class = 1, 0, "spam"
+	}
+
+	return inTx(ctx, r.db, func(q querier) error {
+		if len(tokens) > 0 {
+			if _, err := q.ExecContext(ctx, upsertTokens, pq.Array(tokens), spamInc, hamInc); err != nil {
+				return fmt.Errorf("failed to train spam tokens: %w", err)
+			}
+		}
+		if _, err := q.ExecContext(ctx, countDoc, class); err != nil {
+			return fmt.Errorf("failed to count spam training doc: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+type subscriptionRepository struct {
+	db *sql.DB
+}
+
+func NewSubscriptionRepository(db *sql.DB) repository.SubscriptionRepository {
+	return &subscriptionRepository{db: db}
+}
+
+func (r *subscriptionRepository) Save(ctx context.Context, sub *entity.Subscription) error {
+	const query = `
+		INSERT INTO subscriptions (user_id, target_type, target_id, muted, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, target_type, target_id) DO UPDATE SET muted = EXCLUDED.muted
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, sub.UserID, sub.TargetType, sub.TargetID, sub.Muted, sub.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *subscriptionRepository) Ensure(ctx context.Context, sub *entity.Subscription) error {
+	const query = `
+		INSERT INTO subscriptions (user_id, target_type, target_id, muted, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, target_type, target_id) DO NOTHING
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, sub.UserID, sub.TargetType, sub.TargetID, sub.Muted, sub.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to ensure subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *subscriptionRepository) Delete(ctx context.Context, userID int64, targetType entity.SubscriptionTarget, targetID int64) error {
+	const query = `DELETE FROM subscriptions WHERE user_id = $1 AND target_type = $2 AND target_id = $3`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, userID, targetType, targetID)
+	if err != nil {
+		return fmt.Errorf("failed to delete subscription: %w", err)
+	}
+	return expectAffected(result)
+}
+
+func (r *subscriptionRepository) ListByUser(ctx context.Context, userID int64, limit, offset int) ([]*entity.Subscription, int64, error) {
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM subscriptions WHERE user_id = $1`, userID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count subscriptions: %w", err)
+	}
+
+	const query = `
+		SELECT user_id, target_type, target_id, muted, created_at
+		FROM subscriptions
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := []*entity.Subscription{}
+	for rows.Next() {
+		s := &entity.Subscription{}
+		if err := rows.Scan(&s.UserID, &s.TargetType, &s.TargetID, &s.Muted, &s.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		subs = append(subs, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows error: %w", err)
+	}
+	return subs, total, nil
+}
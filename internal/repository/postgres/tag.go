@@ -19,8 +19,8 @@ func NewTagRepo(db *sql.DB) *TagRepo {
 
 func (r *TagRepo) GetByID(ctx context.Context, id int64) (*entity.Tag, error) {
 	tag := &entity.Tag{}
-	err := r.db.QueryRowContext(ctx, "SELECT id, title, slug FROM tags WHERE id = $1", id).
-		Scan(&tag.ID, &tag.Name, &tag.Slug)
+	err := r.db.QueryRowContext(ctx, "SELECT id, title, slug, version FROM tags WHERE id = $1", id).
+		Scan(&tag.ID, &tag.Name, &tag.Slug, &tag.Version)
 	if err != nil {
 		return nil, err
 	}
@@ -29,8 +29,8 @@ func (r *TagRepo) GetByID(ctx context.Context, id int64) (*entity.Tag, error) {
 
 func (r *TagRepo) GetBySlug(ctx context.Context, slug string) (*entity.Tag, error) {
 	tag := &entity.Tag{}
-	err := r.db.QueryRowContext(ctx, "SELECT id, title, slug FROM tags WHERE slug = $1", slug).
-		Scan(&tag.ID, &tag.Name, &tag.Slug)
+	err := r.db.QueryRowContext(ctx, "SELECT id, title, slug, version FROM tags WHERE slug = $1", slug).
+		Scan(&tag.ID, &tag.Name, &tag.Slug, &tag.Version)
 	if err != nil {
 		return nil, err
 	}
@@ -114,9 +114,9 @@ func (r *TagRepo) ListByIDs(ctx context.Context, ids []int64) ([]*entity.Tag, er
 }
 
 func (r *TagRepo) Create(ctx context.Context, tag *entity.Tag) (int64, error) {
-	err := r.db.QueryRowContext(ctx,
-		"INSERT INTO tags (title, slug) VALUES ($1, $2) RETURNING id",
-		tag.Name, tag.Slug).Scan(&tag.ID)
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		"INSERT INTO tags (title, slug) VALUES ($1, $2) RETURNING id, version",
+		tag.Name, tag.Slug).Scan(&tag.ID, &tag.Version)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create tag: %w", err)
 	}
@@ -141,7 +141,7 @@ func (r *TagRepo) ListAll(ctx context.Context) ([]*entity.Tag, error) {
 	return tags, nil
 }
 func (r *TagRepo) AddToPost(ctx context.Context, postID int64, tagID int64) error {
-	_, err := r.db.ExecContext(ctx, `
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
         INSERT INTO post_tags (post_id, tag_id) 
         VALUES ($1, $2)`,
 		postID, tagID)
@@ -149,7 +149,7 @@ func (r *TagRepo) AddToPost(ctx context.Context, postID int64, tagID int64) erro
 }
 
 func (r *TagRepo) RemoveFromPost(ctx context.Context, postID int64, tagID int64) error {
-	_, err := r.db.ExecContext(ctx, `
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
         DELETE FROM post_tags 
         WHERE post_id = $1 AND tag_id = $2`,
 		postID, tagID)
@@ -1,17 +1,29 @@
 package postgres
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/VaneZ444/forum-service/internal/entity"
 	"github.com/VaneZ444/forum-service/internal/repository"
 	forumv1 "github.com/VaneZ444/golang-forum-protos/gen/go/forum"
+	"github.com/lib/pq"
 )
 
+// topicListQuery — общий SELECT для списков топиков, колонки в порядке scanTopicList.
+const topicListQuery = `
+	SELECT topics.id, title, author_id, author_nickname, category_id, created_at, 
+		posts_count, views_count, last_activity, status,
+		locked_at, COALESCE(locked_by, 0), COALESCE(lock_reason, ''), COALESCE(moved_to_id, 0)
+	FROM topics
+`
+
 type TopicRepository struct {
 	db *sql.DB
 }
@@ -21,16 +33,23 @@ func NewTopicRepository(db *sql.DB) repository.TopicRepository {
 }
 
 func (r *TopicRepository) CreateWithPost(ctx context.Context, topic *entity.Topic, post *entity.Post) error {
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+	return inTx(ctx, r.db, func(tx querier) error {
+		return r.createWithPost(ctx, tx, topic, post)
+	})
+}
+
+func (r *TopicRepository) createWithPost(ctx context.Context, tx querier, topic *entity.Topic, post *entity.Post) error {
+	if topic.Status == 0 {
+		topic.Status = entity.StatusActive
+	}
+	if post.Status == 0 {
+		post.Status = entity.StatusActive
 	}
-	defer tx.Rollback()
 
 	// Insert topic
 	topicQuery := `
-		INSERT INTO topics (title, author_id, author_nickname, category_id, created_at, last_activity)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO topics (title, author_id, author_nickname, category_id, created_at, last_activity, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id
 	`
 	if err := tx.QueryRowContext(ctx, topicQuery,
@@ -40,6 +59,7 @@ func (r *TopicRepository) CreateWithPost(ctx context.Context, topic *entity.Topi
 		topic.CategoryID,
 		topic.CreatedAt,
 		topic.CreatedAt,
+		topic.Status,
 	).Scan(&topic.ID); err != nil {
 		return fmt.Errorf("failed to create topic: %w", err)
 	}
@@ -47,8 +67,8 @@ func (r *TopicRepository) CreateWithPost(ctx context.Context, topic *entity.Topi
 	// Insert first post
 	post.TopicID = topic.ID
 	postQuery := `
-		INSERT INTO posts (topic_id, author_id, author_nickname, title, content, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO posts (topic_id, author_id, author_nickname, title, content, created_at, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id
 	`
 	if err := tx.QueryRowContext(ctx, postQuery,
@@ -58,28 +78,37 @@ func (r *TopicRepository) CreateWithPost(ctx context.Context, topic *entity.Topi
 		post.Title,
 		post.Content,
 		post.CreatedAt,
+		post.Status,
 	).Scan(&post.ID); err != nil {
 		return fmt.Errorf("failed to create first post: %w", err)
 	}
+	if _, err := tx.ExecContext(ctx, `UPDATE topics SET first_post_id = $1 WHERE id = $2`, post.ID, topic.ID); err != nil {
+		return fmt.Errorf("failed to set first post: %w", err)
+	}
+	topic.Version, post.Version = 1, 1
 
-	return tx.Commit()
+	return nil
 }
 
 func (r *TopicRepository) GetByID(ctx context.Context, id int64) (*entity.Topic, error) {
 	query := `
 		SELECT 
 			id, title, author_id, author_nickname, category_id, created_at, 
-			posts_count, views_count, last_activity, status
+			posts_count, views_count, last_activity, status,
+			locked_at, COALESCE(locked_by, 0), COALESCE(lock_reason, ''), COALESCE(moved_to_id, 0),
+			COALESCE(accepted_post_id, 0), version
 		FROM topics
 		WHERE id = $1
 	`
 
-	row := r.db.QueryRowContext(ctx, query, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, id)
 
 	topic := &entity.Topic{}
 	err := row.Scan(
 		&topic.ID, &topic.Title, &topic.AuthorID, &topic.AuthorNickname, &topic.CategoryID, &topic.CreatedAt,
 		&topic.PostsCount, &topic.ViewsCount, &topic.LastActivity, &topic.Status,
+		&topic.LockedAt, &topic.LockedBy, &topic.LockReason, &topic.MovedToID,
+		&topic.AcceptedPostID, &topic.Version,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -96,15 +125,15 @@ func (r *TopicRepository) GetByIDWithFirstPost(ctx context.Context, id int64) (*
 		SELECT 
 			t.id, t.title, t.author_id, t.author_nickname, t.category_id, t.created_at, 
 			t.posts_count, t.views_count, t.last_activity, t.status,
-			p.id, p.author_id, p.author_nickname, p.title, p.content, p.created_at
+			t.locked_at, COALESCE(t.locked_by, 0), COALESCE(t.lock_reason, ''), COALESCE(t.moved_to_id, 0),
+			COALESCE(t.accepted_post_id, 0), t.version,
+			p.id, p.author_id, p.author_nickname, p.title, p.content, p.created_at, p.status, p.wiki, p.version
 		FROM topics t
-		JOIN posts p ON t.id = p.topic_id
+		JOIN posts p ON p.id = t.first_post_id
 		WHERE t.id = $1
-		ORDER BY p.created_at ASC
-		LIMIT 1
 	`
 
-	row := r.db.QueryRowContext(ctx, query, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, id)
 
 	topic := &entity.Topic{}
 	post := &entity.Post{}
@@ -112,7 +141,9 @@ func (r *TopicRepository) GetByIDWithFirstPost(ctx context.Context, id int64) (*
 	err := row.Scan(
 		&topic.ID, &topic.Title, &topic.AuthorID, &topic.AuthorNickname, &topic.CategoryID, &topic.CreatedAt,
 		&topic.PostsCount, &topic.ViewsCount, &topic.LastActivity, &topic.Status,
-		&post.ID, &post.AuthorID, &post.AuthorNickname, &post.Title, &post.Content, &post.CreatedAt,
+		&topic.LockedAt, &topic.LockedBy, &topic.LockReason, &topic.MovedToID,
+		&topic.AcceptedPostID, &topic.Version,
+		&post.ID, &post.AuthorID, &post.AuthorNickname, &post.Title, &post.Content, &post.CreatedAt, &post.Status, &post.Wiki, &post.Version,
 	)
 
 	if err != nil {
@@ -126,21 +157,23 @@ func (r *TopicRepository) GetByIDWithFirstPost(ctx context.Context, id int64) (*
 	return topic, post, nil
 }
 
-func (r *TopicRepository) List(ctx context.Context, categoryID *int64, limit, offset int, sorting *forumv1.Sorting) ([]*entity.Topic, int64, error) {
-	query := `
-		SELECT id, title, author_id, author_nickname, category_id, created_at, 
-			posts_count, views_count, last_activity, status
-		FROM topics
+func (r *TopicRepository) List(ctx context.Context, categoryIDs []int64, limit, offset int, sorting *forumv1.Sorting) ([]*entity.Topic, int64, error) {
+	query := topicListQuery + `
+		LEFT JOIN topic_scores s ON s.topic_id = topics.id
 	`
 	countQuery := `SELECT COUNT(*) FROM topics`
 
+	// Скрытые модерацией топики в списки не попадают
+	query += " WHERE topics.status = 1"
+	countQuery += " WHERE topics.status = 1"
+
 	var args []any
 	argIndex := 1
 
-	if categoryID != nil {
-		query += fmt.Sprintf(" WHERE category_id = $%d", argIndex)
-		countQuery += fmt.Sprintf(" WHERE category_id = $%d", argIndex)
-		args = append(args, *categoryID)
+	if len(categoryIDs) > 0 {
+		query += fmt.Sprintf(" AND category_id = ANY($%d)", argIndex)
+		countQuery += fmt.Sprintf(" AND category_id = ANY($%d)", argIndex)
+		args = append(args, pq.Array(categoryIDs))
 		argIndex++
 	}
 
@@ -152,18 +185,9 @@ func (r *TopicRepository) List(ctx context.Context, categoryID *int64, limit, of
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list topics: %w", err)
 	}
-	defer rows.Close()
-
-	topics := []*entity.Topic{}
-	for rows.Next() {
-		t := &entity.Topic{}
-		if err := rows.Scan(
-			&t.ID, &t.Title, &t.AuthorID, &t.AuthorNickname, &t.CategoryID, &t.CreatedAt,
-			&t.PostsCount, &t.ViewsCount, &t.LastActivity, &t.Status,
-		); err != nil {
-			return nil, 0, fmt.Errorf("failed to scan topic: %w", err)
-		}
-		topics = append(topics, t)
+	topics, err := scanTopicList(rows)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	var total int64
@@ -175,21 +199,61 @@ func (r *TopicRepository) List(ctx context.Context, categoryID *int64, limit, of
 	return topics, total, nil
 }
 
+// ListTop возвращает топики по убыванию рейтинга за период. Топики без рассчитанного рейтинга,
+// перенесённые заглушки и неактивные топики не попадают.
+func (r *TopicRepository) ListTop(ctx context.Context, period entity.RankingPeriod, categoryIDs []int64, limit, offset int) ([]*entity.Topic, int64, error) {
+	column, err := rankingColumn(period)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	const where = ` JOIN topic_scores s ON s.topic_id = topics.id WHERE topics.moved_to_id IS NULL AND topics.status = 1`
+	query := topicListQuery + where
+	countQuery := `SELECT COUNT(*) FROM topics` + where
+	args := []any{}
+	if len(categoryIDs) > 0 {
+		query += ` AND category_id = ANY($1)`
+		countQuery += ` AND category_id = ANY($1)`
+		args = append(args, pq.Array(categoryIDs))
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count top topics: %w", err)
+	}
+
+	query += fmt.Sprintf(" ORDER BY s.%s DESC, topics.id DESC LIMIT $%d OFFSET $%d", column, len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list top topics: %w", err)
+	}
+	topics, err := scanTopicList(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return topics, total, nil
+}
+
 func (r *TopicRepository) Update(ctx context.Context, topic *entity.Topic) (*entity.Topic, error) {
 	const query = `
 		UPDATE topics
-		SET title = $1, author_nickname = $2, category_id = $3, last_activity = $4
-		WHERE id = $5
-		RETURNING id, title, author_id, author_nickname, category_id, created_at, status, posts_count, views_count, last_activity
+		SET title = $1, author_nickname = $2, category_id = $3, last_activity = $4, version = version + 1
+		WHERE id = $5 AND ($6 = 0 OR version = $6)
+		RETURNING id, title, author_id, author_nickname, category_id, created_at, status, posts_count, views_count, last_activity,
+			locked_at, COALESCE(locked_by, 0), COALESCE(lock_reason, ''), COALESCE(moved_to_id, 0),
+			COALESCE(accepted_post_id, 0), version
 	`
 
 	updatedTopic := &entity.Topic{}
-	err := r.db.QueryRowContext(ctx, query,
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
 		topic.Title,
 		topic.AuthorNickname, // добавлено
 		topic.CategoryID,
 		topic.LastActivity,
 		topic.ID,
+		topic.Version,
 	).Scan(
 		&updatedTopic.ID,
 		&updatedTopic.Title,
@@ -201,10 +265,16 @@ func (r *TopicRepository) Update(ctx context.Context, topic *entity.Topic) (*ent
 		&updatedTopic.PostsCount,
 		&updatedTopic.ViewsCount,
 		&updatedTopic.LastActivity,
+		&updatedTopic.LockedAt,
+		&updatedTopic.LockedBy,
+		&updatedTopic.LockReason,
+		&updatedTopic.MovedToID,
+		&updatedTopic.AcceptedPostID,
+		&updatedTopic.Version,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, repository.ErrNotFound
+			return nil, versionMissError(ctx, r.db, "topics", topic.ID)
 		}
 		return nil, fmt.Errorf("failed to update topic: %w", err)
 	}
@@ -213,50 +283,252 @@ func (r *TopicRepository) Update(ctx context.Context, topic *entity.Topic) (*ent
 }
 
 func (r *TopicRepository) Delete(ctx context.Context, id int64) error {
-	tx, err := r.db.BeginTx(ctx, nil)
+	// categories.topics_count уменьшает триггер trg_topics_count
+	deleteQuery := `DELETE FROM topics WHERE id = $1`
+	result, err := conn(ctx, r.db).ExecContext(ctx, deleteQuery, id)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return fmt.Errorf("failed to delete topic: %w", err)
 	}
-	defer tx.Rollback()
+	return expectAffected(result)
+}
 
-	// Get topic to update category count
-	topicQuery := `SELECT category_id FROM topics WHERE id = $1`
-	var categoryID int64
-	err = tx.QueryRowContext(ctx, topicQuery, id).Scan(&categoryID)
+func (r *TopicRepository) Lock(ctx context.Context, id, moderatorID int64, reason string, at time.Time) error {
+	const query = `
+		UPDATE topics
+		SET locked_at = $1, locked_by = $2, lock_reason = $3
+		WHERE id = $4
+	`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, at, moderatorID, reason, id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return repository.ErrNotFound
-		}
-		return fmt.Errorf("failed to get topic: %w", err)
+		return fmt.Errorf("failed to lock topic: %w", err)
 	}
+	return expectAffected(result)
+}
 
-	// Delete topic
-	deleteQuery := `DELETE FROM topics WHERE id = $1`
-	result, err := tx.ExecContext(ctx, deleteQuery, id)
+func (r *TopicRepository) Unlock(ctx context.Context, id int64) error {
+	const query = `
+		UPDATE topics
+		SET locked_at = NULL, locked_by = NULL, lock_reason = NULL
+		WHERE id = $1
+	`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
-		return fmt.Errorf("failed to delete topic: %w", err)
+		return fmt.Errorf("failed to unlock topic: %w", err)
 	}
+	return expectAffected(result)
+}
 
-	rowsAffected, err := result.RowsAffected()
+func (r *TopicRepository) SetAcceptedPost(ctx context.Context, topicID, postID int64) error {
+	const query = `UPDATE topics SET accepted_post_id = NULLIF($1, 0) WHERE id = $2`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, postID, topicID)
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return fmt.Errorf("failed to set accepted post: %w", err)
 	}
-	if rowsAffected == 0 {
-		return repository.ErrNotFound
+	return expectAffected(result)
+}
+
+// LockInactive закрывает открытые активные топики, в которых не было активности с before, и возвращает их.
+// Редиректы перенесённых топиков и скрытые топики не трогает.
+func (r *TopicRepository) LockInactive(ctx context.Context, before time.Time, reason string, at time.Time) ([]*entity.Topic, error) {
+	const query = `
+		UPDATE topics
+		SET locked_at = $1, locked_by = 0, lock_reason = $2
+		WHERE locked_at IS NULL AND last_activity < $3
+			AND moved_to_id IS NULL AND status = 1
+		RETURNING id, title, author_id, author_nickname, category_id, created_at,
+			posts_count, views_count, last_activity, status,
+			locked_at, COALESCE(locked_by, 0), COALESCE(lock_reason, ''), COALESCE(moved_to_id, 0)
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, at, reason, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock inactive topics: %w", err)
 	}
+	return scanTopicList(rows)
+}
 
-	// Update category topic count
-	updateCategoryQuery := `
-		UPDATE categories 
-		SET topics_count = topics_count - 1 
-		WHERE id = $1
+// Create создаёт топик без первого поста (используется при разделении топика);
+// первый пост назначает RefreshStats после переноса постов. Нулевой статус — активный.
+func (r *TopicRepository) Create(ctx context.Context, topic *entity.Topic) error {
+	if topic.Status == 0 {
+		topic.Status = entity.StatusActive
+	}
+	const query = `
+		INSERT INTO topics (title, author_id, author_nickname, category_id, created_at, last_activity, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		topic.Title,
+		topic.AuthorID,
+		topic.AuthorNickname,
+		topic.CategoryID,
+		topic.CreatedAt,
+		topic.LastActivity,
+		topic.Status,
+	).Scan(&topic.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create topic: %w", err)
+	}
+	topic.Version = 1
+	return nil
+}
+
+// HasPosts сообщает, остались ли в топике посты в любом статусе — posts_count считает только опубликованные.
+func (r *TopicRepository) HasPosts(ctx context.Context, id int64) (bool, error) {
+	const query = `SELECT EXISTS (SELECT 1 FROM posts WHERE topic_id = $1)`
+	var has bool
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(&has); err != nil {
+		return false, fmt.Errorf("failed to check topic posts: %w", err)
+	}
+	return has, nil
+}
+
+func (r *TopicRepository) Move(ctx context.Context, id, categoryID int64) error {
+	const query = `UPDATE topics SET category_id = $1, version = version + 1 WHERE id = $2`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, categoryID, id)
+	if err != nil {
+		return fmt.Errorf("failed to move topic: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// CreateRedirect оставляет закрытую заглушку с заголовком from в его категории, ведущую на targetID.
+func (r *TopicRepository) CreateRedirect(ctx context.Context, from *entity.Topic, targetID int64, at time.Time) (int64, error) {
+	const query = `
+		INSERT INTO topics (
+			title, author_id, author_nickname, category_id, created_at, last_activity,
+			moved_to_id, locked_at, locked_by, lock_reason
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 0, 'moved')
+		RETURNING id
 	`
-	_, err = tx.ExecContext(ctx, updateCategoryQuery, categoryID)
+	var id int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		from.Title,
+		from.AuthorID,
+		from.AuthorNickname,
+		from.CategoryID,
+		from.CreatedAt,
+		from.LastActivity,
+		targetID,
+		at,
+	).Scan(&id)
 	if err != nil {
-		return fmt.Errorf("failed to update category topic count: %w", err)
+		return 0, fmt.Errorf("failed to create redirect topic: %w", err)
+	}
+	return id, nil
+}
+
+// MarkRedirect превращает опустевший топик в заглушку, ведущую на targetID.
+func (r *TopicRepository) MarkRedirect(ctx context.Context, id, targetID int64, at time.Time) error {
+	const query = `
+		UPDATE topics
+		SET moved_to_id = $1, locked_at = $2, locked_by = 0, lock_reason = 'moved'
+		WHERE id = $3
+	`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, targetID, at, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark topic as redirect: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// MovePosts переносит посты fromID в toID. Если postIDs пуст, переносятся все посты.
+// Даты создания не меняются, поэтому хронология в целевом топике сохраняется.
+func (r *TopicRepository) MovePosts(ctx context.Context, fromID, toID int64, postIDs []int64) (int64, error) {
+	query := `UPDATE posts SET topic_id = $1 WHERE topic_id = $2`
+	args := []any{toID, fromID}
+	if len(postIDs) > 0 {
+		query += ` AND id = ANY($3)`
+		args = append(args, pq.Array(postIDs))
 	}
 
-	return tx.Commit()
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to move posts: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return n, nil
+}
+
+// RefreshStats пересчитывает posts_count и last_activity по фактическим постам.
+// Триггер trg_posts_count не срабатывает при смене topic_id, поэтому после переноса постов нужен явный пересчёт.
+// Если первый пост ушёл из топика (или его не было, как у выделенного топика), первым становится самый ранний;
+// посты, пришедшие при объединении, первый пост целевого топика не вытесняют.
+func (r *TopicRepository) RefreshStats(ctx context.Context, ids ...int64) error {
+	const query = `
+		UPDATE topics t
+		SET posts_count = (SELECT COUNT(*) FROM posts p WHERE p.topic_id = t.id AND is_published(p.status)),
+			last_activity = COALESCE(
+				(SELECT MAX(p.created_at) FROM posts p WHERE p.topic_id = t.id AND is_published(p.status)),
+				t.created_at
+			),
+			first_post_id = CASE
+				WHEN EXISTS (SELECT 1 FROM posts p WHERE p.id = t.first_post_id AND p.topic_id = t.id) THEN t.first_post_id
+				ELSE (SELECT p.id FROM posts p WHERE p.topic_id = t.id ORDER BY p.created_at, p.id LIMIT 1)
+			END
+		WHERE t.id = ANY($1)
+	`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, pq.Array(ids)); err != nil {
+		return fmt.Errorf("failed to refresh topic stats: %w", err)
+	}
+	return nil
+}
+
+// AddViews записывает отметки просмотров и одним запросом прибавляет к views_count только те,
+// что вставились: отметку того же зрителя в том же окне могла уже записать другая реплика.
+// Отметки сортируются, чтобы реплики захватывали блокировки в одном порядке.
+func (r *TopicRepository) AddViews(ctx context.Context, views []entity.TopicView) error {
+	views = slices.Clone(views)
+	slices.SortFunc(views, func(a, b entity.TopicView) int {
+		return cmp.Or(cmp.Compare(a.TopicID, b.TopicID), cmp.Compare(a.ViewerHash, b.ViewerHash), cmp.Compare(a.Bucket, b.Bucket))
+	})
+
+	topicIDs := make([]int64, len(views))
+	hashes := make([]string, len(views))
+	buckets := make([]int64, len(views))
+	for i, v := range views {
+		topicIDs[i], hashes[i], buckets[i] = v.TopicID, v.ViewerHash, v.Bucket
+	}
+
+	const query = `
+		WITH fresh AS (
+			INSERT INTO topic_views (topic_id, viewer_hash, bucket)
+			SELECT * FROM unnest($1::bigint[], $2::text[], $3::bigint[])
+			ON CONFLICT DO NOTHING
+			RETURNING topic_id
+		)
+		UPDATE topics t
+		SET views_count = t.views_count + v.n
+		FROM (SELECT topic_id, COUNT(*) AS n FROM fresh GROUP BY topic_id) AS v
+		WHERE t.id = v.topic_id
+	`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, pq.Array(topicIDs), pq.Array(hashes), pq.Array(buckets)); err != nil {
+		return fmt.Errorf("failed to add topic views: %w", err)
+	}
+	return nil
+}
+
+func (r *TopicRepository) DeleteViewsBefore(ctx context.Context, bucket int64) error {
+	const query = `DELETE FROM topic_views WHERE bucket < $1`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, bucket); err != nil {
+		return fmt.Errorf("failed to delete topic views: %w", err)
+	}
+	return nil
+}
+
+func expectAffected(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
 }
 
 func buildOrderBy(sorting *forumv1.Sorting) string {
@@ -273,7 +545,7 @@ func buildOrderBy(sorting *forumv1.Sorting) string {
 	case forumv1.SortField_SORT_FIELD_TITLE:
 		field = "title"
 	case forumv1.SortField_SORT_FIELD_POPULARITY:
-		field = "views_count" // можно по логике менять на posts_count
+		field = "COALESCE(s.hot, 0)" // hot-score из topic_scores, пересчитывается RankingUseCase
 	default:
 		field = "last_activity"
 	}
@@ -294,15 +566,16 @@ func (r *TopicRepository) Search(ctx context.Context, query string, limit, offse
 	tsquery := fmt.Sprintf("%s:*", strings.Join(strings.Fields(query), " & "))
 
 	var total int64
-	countQuery := `SELECT COUNT(*) FROM topics WHERE search_vector @@ to_tsquery('english', $1)`
+	countQuery := `SELECT COUNT(*) FROM topics WHERE status = 1 AND search_vector @@ to_tsquery('english', $1)`
 	if err := r.db.QueryRowContext(ctx, countQuery, tsquery).Scan(&total); err != nil {
 		return nil, 0, fmt.Errorf("failed to count topics: %w", err)
 	}
 
 	searchQuery := `
-		SELECT id, title, author_id, author_nickname, category_id, created_at, status, posts_count, views_count, last_activity
+		SELECT id, title, author_id, author_nickname, category_id, created_at, status, posts_count, views_count, last_activity,
+			locked_at, COALESCE(locked_by, 0), COALESCE(lock_reason, ''), COALESCE(moved_to_id, 0)
 		FROM topics
-		WHERE search_vector @@ to_tsquery('english', $1)
+		WHERE status = 1 AND search_vector @@ to_tsquery('english', $1)
 		ORDER BY ts_rank_cd(search_vector, to_tsquery('english', $1)) DESC
 		LIMIT $2 OFFSET $3
 	`
@@ -318,6 +591,7 @@ func (r *TopicRepository) Search(ctx context.Context, query string, limit, offse
 		if err := rows.Scan(
 			&t.ID, &t.Title, &t.AuthorID, &t.AuthorNickname, &t.CategoryID, &t.CreatedAt,
 			&t.Status, &t.PostsCount, &t.ViewsCount, &t.LastActivity,
+			&t.LockedAt, &t.LockedBy, &t.LockReason, &t.MovedToID,
 		); err != nil {
 			return nil, 0, fmt.Errorf("failed to scan topic: %w", err)
 		}
@@ -326,3 +600,24 @@ func (r *TopicRepository) Search(ctx context.Context, query string, limit, offse
 
 	return topics, total, nil
 }
+
+func scanTopicList(rows *sql.Rows) ([]*entity.Topic, error) {
+	defer rows.Close()
+
+	topics := []*entity.Topic{}
+	for rows.Next() {
+		t := &entity.Topic{}
+		if err := rows.Scan(
+			&t.ID, &t.Title, &t.AuthorID, &t.AuthorNickname, &t.CategoryID, &t.CreatedAt,
+			&t.PostsCount, &t.ViewsCount, &t.LastActivity, &t.Status,
+			&t.LockedAt, &t.LockedBy, &t.LockReason, &t.MovedToID,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan topic: %w", err)
+		}
+		topics = append(topics, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return topics, nil
+}
@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+func TestFirstPostSurvivesMergeAndSplit(t *testing.T) {
+	ctx, db, _ := testTx(t)
+	categories := NewCategoryRepository(db)
+	topics := NewTopicRepository(db).(*TopicRepository)
+
+	c, err := categories.Create(ctx, &entity.Category{Title: "c", Slug: fmt.Sprintf("c-%d", time.Now().UnixNano())})
+	if err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+	newTopic := func(createdAt time.Time) (*entity.Topic, *entity.Post) {
+		topic := &entity.Topic{Title: "t", AuthorID: 1, CategoryID: c.ID, CreatedAt: createdAt}
+		post := &entity.Post{Title: "t", Content: "c", AuthorID: 1, CreatedAt: createdAt}
+		if err := topics.CreateWithPost(ctx, topic, post); err != nil {
+			t.Fatalf("create topic: %v", err)
+		}
+		return topic, post
+	}
+	expectFirst := func(step string, topicID, wantPostID int64) {
+		t.Helper()
+		_, first, err := topics.GetByIDWithFirstPost(ctx, topicID)
+		if err != nil {
+			t.Fatalf("%s: get first post: %v", step, err)
+		}
+		if first.ID != wantPostID {
+			t.Fatalf("%s: first post = %d, want %d", step, first.ID, wantPostID)
+		}
+	}
+
+	now := time.Now().UTC()
+	target, opening := newTopic(now)
+	// Источник старше цели: после объединения его пост окажется раньше первого поста цели
+	source, older := newTopic(now.Add(-time.Hour))
+
+	if _, err := topics.MovePosts(ctx, source.ID, target.ID, nil); err != nil {
+		t.Fatalf("merge posts: %v", err)
+	}
+	if err := topics.RefreshStats(ctx, source.ID, target.ID); err != nil {
+		t.Fatalf("refresh after merge: %v", err)
+	}
+	expectFirst("merge", target.ID, opening.ID)
+
+	split := &entity.Topic{Title: "s", AuthorID: 1, CategoryID: c.ID, CreatedAt: now, LastActivity: now}
+	if err := topics.Create(ctx, split); err != nil {
+		t.Fatalf("create split topic: %v", err)
+	}
+	if _, err := topics.MovePosts(ctx, target.ID, split.ID, []int64{opening.ID}); err != nil {
+		t.Fatalf("split posts: %v", err)
+	}
+	if err := topics.RefreshStats(ctx, target.ID, split.ID); err != nil {
+		t.Fatalf("refresh after split: %v", err)
+	}
+	expectFirst("split: new topic", split.ID, opening.ID)
+	expectFirst("split: rest of the topic", target.ID, older.ID)
+}
+
+func TestSplitTopicKeepsStatusAndCountsHiddenPosts(t *testing.T) {
+	ctx, db, _ := testTx(t)
+	categories := NewCategoryRepository(db)
+	topics := NewTopicRepository(db).(*TopicRepository)
+	posts := NewPostRepository(db)
+
+	c, err := categories.Create(ctx, &entity.Category{Title: "c", Slug: fmt.Sprintf("c-%d", time.Now().UnixNano())})
+	if err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+	now := time.Now().UTC()
+	source := &entity.Topic{Title: "t", AuthorID: 1, CategoryID: c.ID, CreatedAt: now}
+	opening := &entity.Post{Title: "t", Content: "c", AuthorID: 1, CreatedAt: now, Status: entity.StatusHidden}
+	if err := topics.CreateWithPost(ctx, source, opening); err != nil {
+		t.Fatalf("create topic: %v", err)
+	}
+	pending := &entity.Post{TopicID: source.ID, Content: "p", AuthorID: 2, CreatedAt: now.Add(time.Minute), Status: entity.StatusPending}
+	if _, err := posts.Create(ctx, pending); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+
+	split := &entity.Topic{Title: "s", AuthorID: 2, CategoryID: c.ID, CreatedAt: now, LastActivity: now, Status: pending.Status}
+	if err := topics.Create(ctx, split); err != nil {
+		t.Fatalf("create split topic: %v", err)
+	}
+	got, err := topics.GetByID(ctx, split.ID)
+	if err != nil {
+		t.Fatalf("get split topic: %v", err)
+	}
+	if got.Status != entity.StatusPending {
+		t.Fatalf("split topic status = %d, want %d", got.Status, entity.StatusPending)
+	}
+
+	if _, err := topics.MovePosts(ctx, source.ID, split.ID, []int64{pending.ID}); err != nil {
+		t.Fatalf("split posts: %v", err)
+	}
+	// В источнике остался только скрытый пост: posts_count = 0, но топик не пуст
+	has, err := topics.HasPosts(ctx, source.ID)
+	if err != nil {
+		t.Fatalf("has posts: %v", err)
+	}
+	if !has {
+		t.Fatal("source topic with a hidden post reported as empty")
+	}
+}
+
+func TestAddViewsCountsEachViewerOncePerWindow(t *testing.T) {
+	ctx, db, _ := testTx(t)
+	categories := NewCategoryRepository(db)
+	topics := NewTopicRepository(db).(*TopicRepository)
+
+	c, err := categories.Create(ctx, &entity.Category{Title: "c", Slug: fmt.Sprintf("c-%d", time.Now().UnixNano())})
+	if err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+	topic := &entity.Topic{Title: "t", AuthorID: 1, CategoryID: c.ID, CreatedAt: time.Now().UTC()}
+	if err := topics.CreateWithPost(ctx, topic, &entity.Post{Title: "t", Content: "c", AuthorID: 1}); err != nil {
+		t.Fatalf("create topic: %v", err)
+	}
+
+	// Две реплики пишут одного и того же зрителя: второй раз просмотр не добавляется
+	replica := []entity.TopicView{
+		{TopicID: topic.ID, ViewerHash: "a", Bucket: 1},
+		{TopicID: topic.ID, ViewerHash: "b", Bucket: 1},
+	}
+	other := []entity.TopicView{
+		{TopicID: topic.ID, ViewerHash: "a", Bucket: 1},
+		{TopicID: topic.ID, ViewerHash: "a", Bucket: 2},
+	}
+	for _, views := range [][]entity.TopicView{replica, other} {
+		if err := topics.AddViews(ctx, views); err != nil {
+			t.Fatalf("add views: %v", err)
+		}
+	}
+
+	got, err := topics.GetByID(ctx, topic.ID)
+	if err != nil {
+		t.Fatalf("get topic: %v", err)
+	}
+	if got.ViewsCount != 3 {
+		t.Fatalf("views_count = %d, want 3", got.ViewsCount)
+	}
+}
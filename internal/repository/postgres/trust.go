@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+type trustRepository struct {
+	db *sql.DB
+}
+
+func NewTrustRepository(db *sql.DB) repository.TrustRepository {
+	return &trustRepository{db: db}
+}
+
+func (r *trustRepository) Stats(ctx context.Context, userID int64, survivedBefore time.Time) (entity.ReputationStats, error) {
+	// Ответ в собственном топике не считается: принять его может сам автор
+	const query = `
+		SELECT
+			(SELECT COALESCE(SUM(likes_count), 0) FROM posts WHERE author_id = $1 AND status = 1),
+			(SELECT COUNT(*) FROM posts p
+				JOIN topics t ON t.accepted_post_id = p.id AND t.id = p.topic_id
+				WHERE p.author_id = $1 AND p.status = 1 AND t.author_id <> $1),
+			(SELECT COUNT(*) FROM posts WHERE author_id = $1 AND status = 1 AND created_at < $2),
+			(SELECT COUNT(*) FROM moderation_cases
+				WHERE author_id = $1 AND status = 'resolved' AND resolution IN ('hide', 'delete', 'warn', 'reject'))
+	`
+	var s entity.ReputationStats
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, userID, survivedBefore).Scan(
+		&s.LikesReceived, &s.AcceptedAnswers, &s.SurvivedPosts, &s.UpheldReports,
+	)
+	if err != nil {
+		return s, fmt.Errorf("failed to get reputation stats: %w", err)
+	}
+	return s, nil
+}
+
+func (r *trustRepository) GetOverride(ctx context.Context, userID int64) (*entity.TrustOverride, error) {
+	const query = `SELECT user_id, level, reason, set_by, set_at FROM trust_overrides WHERE user_id = $1`
+	o := &entity.TrustOverride{}
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, userID).Scan(&o.UserID, &o.Level, &o.Reason, &o.SetBy, &o.SetAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trust override: %w", err)
+	}
+	return o, nil
+}
+
+func (r *trustRepository) SetOverride(ctx context.Context, o *entity.TrustOverride) error {
+	const query = `
+		INSERT INTO trust_overrides (user_id, level, reason, set_by, set_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE
+		SET level = EXCLUDED.level, reason = EXCLUDED.reason, set_by = EXCLUDED.set_by, set_at = EXCLUDED.set_at
+	`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, o.UserID, o.Level, o.Reason, o.SetBy, o.SetAt); err != nil {
+		return fmt.Errorf("failed to set trust override: %w", err)
+	}
+	return nil
+}
+
+func (r *trustRepository) DeleteOverride(ctx context.Context, userID int64) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM trust_overrides WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete trust override: %w", err)
+	}
+	return expectAffected(result)
+}
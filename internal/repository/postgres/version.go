@@ -0,0 +1,23 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+// versionMissError объясняет, почему правка с проверкой версии не затронула ни одной строки:
+// запись удалена (ErrNotFound) или её успели изменить (ErrConflict). table — только константа из кода.
+func versionMissError(ctx context.Context, db *sql.DB, table string, id int64) error {
+	var exists bool
+	err := conn(ctx, db).QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM `+table+` WHERE id = $1)`, id).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check %s version: %w", table, err)
+	}
+	if !exists {
+		return repository.ErrNotFound
+	}
+	return repository.ErrConflict
+}
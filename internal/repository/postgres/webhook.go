@@ -0,0 +1,340 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+	"github.com/lib/pq"
+)
+
+type webhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) repository.WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+const webhookColumns = `id, url, secret, event_types, category_ids, active, consecutive_failures, dead_at, created_by, created_at, updated_at`
+
+func scanWebhook(row rowScanner) (*entity.Webhook, error) {
+	w := &entity.Webhook{}
+	var eventTypes []string
+	if err := row.Scan(
+		&w.ID, &w.URL, &w.Secret, pq.Array(&eventTypes), pq.Array(&w.CategoryIDs), &w.Active,
+		&w.ConsecutiveFailures, &w.DeadAt, &w.CreatedBy, &w.CreatedAt, &w.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	for _, t := range eventTypes {
+		w.EventTypes = append(w.EventTypes, entity.EventType(t))
+	}
+	return w, nil
+}
+
+func eventTypeStrings(types []entity.EventType) []string {
+	out := make([]string, len(types))
+	for i, t := range types {
+		out[i] = string(t)
+	}
+	return out
+}
+
+func (r *webhookRepository) Create(ctx context.Context, w *entity.Webhook) error {
+	const query = `
+		INSERT INTO webhooks (url, secret, event_types, category_ids, active, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		RETURNING id
+	`
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		w.URL,
+		w.Secret,
+		pq.Array(eventTypeStrings(w.EventTypes)),
+		pq.Array(w.CategoryIDs),
+		w.Active,
+		w.CreatedBy,
+		w.CreatedAt,
+	).Scan(&w.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+	w.UpdatedAt = w.CreatedAt
+	return nil
+}
+
+func (r *webhookRepository) GetByID(ctx context.Context, id int64) (*entity.Webhook, error) {
+	row := conn(ctx, r.db).QueryRowContext(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`, id)
+	w, err := scanWebhook(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return w, nil
+}
+
+func (r *webhookRepository) List(ctx context.Context, limit, offset int) ([]*entity.Webhook, int64, error) {
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM webhooks`).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhooks: %w", err)
+	}
+	webhooks, err := r.query(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY id LIMIT $1 OFFSET $2`, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return webhooks, total, nil
+}
+
+func (r *webhookRepository) ListDeliverable(ctx context.Context) ([]*entity.Webhook, error) {
+	return r.query(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE active AND dead_at IS NULL ORDER BY id`)
+}
+
+func (r *webhookRepository) query(ctx context.Context, query string, args ...any) ([]*entity.Webhook, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []*entity.Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		webhooks = append(webhooks, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return webhooks, nil
+}
+
+func (r *webhookRepository) Update(ctx context.Context, w *entity.Webhook) error {
+	const query = `
+		UPDATE webhooks
+		SET url = $2, secret = $3, event_types = $4, category_ids = $5, active = $6, updated_at = $7
+		WHERE id = $1
+	`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
+		w.ID,
+		w.URL,
+		w.Secret,
+		pq.Array(eventTypeStrings(w.EventTypes)),
+		pq.Array(w.CategoryIDs),
+		w.Active,
+		w.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+	return expectAffected(result)
+}
+
+func (r *webhookRepository) Delete(ctx context.Context, id int64) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return expectAffected(result)
+}
+
+func (r *webhookRepository) Reactivate(ctx context.Context, id int64, at time.Time) error {
+	const query = `
+		UPDATE webhooks
+		SET dead_at = NULL, consecutive_failures = 0, updated_at = $2
+		WHERE id = $1
+	`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to reactivate webhook: %w", err)
+	}
+	return expectAffected(result)
+}
+
+func (r *webhookRepository) RecordResult(ctx context.Context, id int64, success bool, deadAfter int, at time.Time) (bool, error) {
+	if success {
+		const query = `UPDATE webhooks SET consecutive_failures = 0 WHERE id = $1 AND consecutive_failures <> 0`
+		if _, err := conn(ctx, r.db).ExecContext(ctx, query, id); err != nil {
+			return false, fmt.Errorf("failed to reset webhook failures: %w", err)
+		}
+		return false, nil
+	}
+
+	var dead bool
+	err := inTx(ctx, r.db, func(q querier) error {
+		const query = `
+			UPDATE webhooks
+			SET consecutive_failures = consecutive_failures + 1,
+				dead_at = CASE WHEN dead_at IS NULL AND consecutive_failures + 1 >= $2 THEN $3 ELSE dead_at END
+			WHERE id = $1
+			RETURNING dead_at IS NOT NULL
+		`
+		if err := q.QueryRowContext(ctx, query, id, deadAfter, at).Scan(&dead); err != nil {
+			return fmt.Errorf("failed to record webhook failure: %w", err)
+		}
+		if !dead {
+			return nil
+		}
+		const park = `UPDATE webhook_deliveries SET status = 'dead' WHERE webhook_id = $1 AND status = 'pending'`
+		if _, err := q.ExecContext(ctx, park, id); err != nil {
+			return fmt.Errorf("failed to park webhook deliveries: %w", err)
+		}
+		return nil
+	})
+	return dead, err
+}
+
+func (r *webhookRepository) EnqueueDeliveries(ctx context.Context, deliveries []*entity.WebhookDelivery) error {
+	const query = `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, status, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, 'pending', $5, $5)
+		ON CONFLICT (webhook_id, event_id) DO NOTHING
+	`
+	return inTx(ctx, r.db, func(q querier) error {
+		for _, d := range deliveries {
+			if _, err := q.ExecContext(ctx, query, d.WebhookID, d.EventID, d.EventType, []byte(d.Payload), d.CreatedAt); err != nil {
+				return fmt.Errorf("failed to enqueue webhook delivery: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+const deliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+	last_status_code, last_error, created_at, delivered_at`
+
+func scanDelivery(row rowScanner) (*entity.WebhookDelivery, error) {
+	d := &entity.WebhookDelivery{}
+	var payload []byte
+	if err := row.Scan(
+		&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+		&d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt,
+	); err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	return d, nil
+}
+
+func (r *webhookRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*entity.WebhookDelivery, error) {
+	const query = `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns
+	return r.queryDeliveries(ctx, query, now, leaseUntil, limit)
+}
+
+func (r *webhookRepository) queryDeliveries(ctx context.Context, query string, args ...any) ([]*entity.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []*entity.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (r *webhookRepository) SaveDeliveryResult(ctx context.Context, d *entity.WebhookDelivery) error {
+	// status = 'pending' в WHERE: если вебхук успел уйти в dead-letter, результат не воскрешает доставку
+	const query = `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4, last_status_code = $5, last_error = $6, delivered_at = $7
+		WHERE id = $1 AND status = 'pending'
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query,
+		d.ID,
+		d.Status,
+		d.Attempts,
+		d.NextAttemptAt,
+		d.LastStatusCode,
+		d.LastError,
+		d.DeliveredAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookRepository) GetDelivery(ctx context.Context, id int64) (*entity.WebhookDelivery, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE id = $1`, id)
+	d, err := scanDelivery(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	return d, nil
+}
+
+func (r *webhookRepository) ListDeliveries(ctx context.Context, webhookID int64, status entity.DeliveryStatus, limit, offset int) ([]*entity.WebhookDelivery, int64, error) {
+	filter := ` WHERE webhook_id = $1 AND ($2 = '' OR status = $2)`
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM webhook_deliveries`+filter, webhookID, status).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries` + filter + ` ORDER BY id DESC LIMIT $3 OFFSET $4`
+	deliveries, err := r.queryDeliveries(ctx, query, webhookID, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+func (r *webhookRepository) ResetDelivery(ctx context.Context, id int64, at time.Time) error {
+	const query = `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = $2, last_error = '', delivered_at = NULL
+		WHERE id = $1
+	`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to reset webhook delivery: %w", err)
+	}
+	return expectAffected(result)
+}
+
+func (r *webhookRepository) DeleteFinishedBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	const query = `
+		DELETE FROM webhook_deliveries
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status IN ('delivered', 'failed') AND created_at < $1
+			ORDER BY created_at
+			LIMIT $2
+		)
+	`
+	result, err := r.db.ExecContext(ctx, query, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old webhook deliveries: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+type RankingRepository interface {
+	// TopicActivity возвращает активность не более limit топиков с id > afterID на момент now.
+	TopicActivity(ctx context.Context, now time.Time, afterID int64, limit int) ([]*entity.ActivityStats, error)
+	PostActivity(ctx context.Context, now time.Time, afterID int64, limit int) ([]*entity.ActivityStats, error)
+	SaveTopicScores(ctx context.Context, scores []entity.Scores) error
+	SavePostScores(ctx context.Context, scores []entity.Scores) error
+	// PruneTopicScores и PrunePostScores удаляют рейтинги того, что больше не участвует в пересчёте.
+	PruneTopicScores(ctx context.Context) (int64, error)
+	PrunePostScores(ctx context.Context) (int64, error)
+}
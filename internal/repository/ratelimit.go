@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+// RateLimitRepository хранит token bucket'ы. Реализации: postgres (общая для всех реплик)
+// и ratelimit.MemoryStore (для одной реплики и локального запуска).
+type RateLimitRepository interface {
+	// Take забирает токен из корзины key. Если токена нет, возвращает время до его появления, иначе 0.
+	Take(ctx context.Context, key string, limit entity.RateLimit) (time.Duration, error)
+	// Cleanup удаляет корзины, к которым не обращались дольше idle: они уже полные.
+	Cleanup(ctx context.Context, idle time.Duration) (int64, error)
+}
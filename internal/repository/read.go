@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+type ReadRepository interface {
+	// MarkRead сдвигает маркер вперёд до postID; назад маркер не двигается.
+	MarkRead(ctx context.Context, userID, topicID, postID int64, at time.Time) error
+	// MarkTopicRead сдвигает маркер до последнего поста топика.
+	MarkTopicRead(ctx context.Context, userID, topicID int64, at time.Time) error
+	// MarkCategoriesRead отмечает прочитанным всё в категориях и удаляет ставшие лишними маркеры топиков.
+	MarkCategoriesRead(ctx context.Context, userID int64, categoryIDs []int64, at time.Time) error
+	ReadStates(ctx context.Context, userID int64, topicIDs []int64) (map[int64]*entity.ReadState, error)
+}
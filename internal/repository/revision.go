@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+type RevisionRepository interface {
+	// Add сохраняет версию со следующим номером и заполняет rev.Number.
+	Add(ctx context.Context, rev *entity.Revision) error
+	// Latest возвращает последнюю версию или ErrNotFound, если объект не правили.
+	Latest(ctx context.Context, targetType entity.ContentType, targetID int64) (*entity.Revision, error)
+	Get(ctx context.Context, targetType entity.ContentType, targetID int64, number int) (*entity.Revision, error)
+	// List — версии от новых к старым, без текста и diff.
+	List(ctx context.Context, targetType entity.ContentType, targetID int64, limit, offset int) ([]*entity.Revision, int64, error)
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+type SanctionFilter struct {
+	UserID     int64               // 0 — все пользователи
+	CategoryID int64               // 0 — любые; иначе только санкции этой категории
+	Kind       entity.SanctionKind // пусто — баны и муты
+}
+
+type SanctionRepository interface {
+	Create(ctx context.Context, s *entity.Sanction) error
+	GetByID(ctx context.Context, id int64) (*entity.Sanction, error)
+	// ListActive возвращает неснятые и неистёкшие на момент at санкции.
+	ListActive(ctx context.Context, filter SanctionFilter, at time.Time, limit, offset int) ([]*entity.Sanction, int64, error)
+	// ActiveFor возвращает действующие санкции пользователя: форумные и по категориям из categoryIDs.
+	// Баны идут первыми.
+	ActiveFor(ctx context.Context, userID int64, categoryIDs []int64, at time.Time) ([]*entity.Sanction, error)
+	// Lift снимает санкцию; уже снятая — ErrNotFound.
+	Lift(ctx context.Context, id, liftedBy int64, at time.Time) error
+}
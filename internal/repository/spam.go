@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+type SpamRepository interface {
+	AddFingerprint(ctx context.Context, fp *entity.Fingerprint) error
+	// RecentFingerprints возвращает не больше limit последних отпечатков автора, созданных после since.
+	RecentFingerprints(ctx context.Context, authorID int64, since time.Time, limit int) ([]*entity.Fingerprint, error)
+	DeleteFingerprintsBefore(ctx context.Context, before time.Time, limit int) (int64, error)
+
+	TokenStats(ctx context.Context, tokens []string) (map[string]entity.TokenStats, error)
+	// TrainingDocs — сколько текстов каждого класса видел классификатор.
+	TrainingDocs(ctx context.Context) (spam, ham int64, err error)
+	// Train добавляет текст с уникальными tokens в обучающую выборку.
+	Train(ctx context.Context, tokens []string, spam bool) error
+}
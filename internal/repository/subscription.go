@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+type SubscriptionRepository interface {
+	// Save создаёт подписку или перезаписывает флаг muted у существующей.
+	Save(ctx context.Context, sub *entity.Subscription) error
+	// Ensure создаёт подписку, только если её ещё нет; заглушённые подписки не трогает.
+	Ensure(ctx context.Context, sub *entity.Subscription) error
+	Delete(ctx context.Context, userID int64, targetType entity.SubscriptionTarget, targetID int64) error
+	ListByUser(ctx context.Context, userID int64, limit, offset int) ([]*entity.Subscription, int64, error)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/VaneZ444/forum-service/internal/entity"
 	forumv1 "github.com/VaneZ444/golang-forum-protos/gen/go/forum"
@@ -11,8 +12,29 @@ type TopicRepository interface {
 	CreateWithPost(ctx context.Context, topic *entity.Topic, post *entity.Post) error
 	GetByID(ctx context.Context, id int64) (*entity.Topic, error)
 	GetByIDWithFirstPost(ctx context.Context, id int64) (*entity.Topic, *entity.Post, error)
-	List(ctx context.Context, categoryID *int64, limit, offset int, sorting *forumv1.Sorting) ([]*entity.Topic, int64, error)
+	List(ctx context.Context, categoryIDs []int64, limit, offset int, sorting *forumv1.Sorting) ([]*entity.Topic, int64, error)
+	// Update меняет правимые поля, только если topic.Version совпадает с текущей (0 — без проверки), иначе ErrConflict.
 	Update(ctx context.Context, topic *entity.Topic) (*entity.Topic, error)
 	Delete(ctx context.Context, id int64) error
 	Search(ctx context.Context, query string, limit, offset int) ([]*entity.Topic, int64, error)
+	Lock(ctx context.Context, id, moderatorID int64, reason string, at time.Time) error
+	Unlock(ctx context.Context, id int64) error
+	// SetAcceptedPost отмечает принятый ответ; postID = 0 снимает отметку.
+	SetAcceptedPost(ctx context.Context, topicID, postID int64) error
+	// LockInactive закрывает от имени системы (locked_by = 0) активные топики без активности с before.
+	LockInactive(ctx context.Context, before time.Time, reason string, at time.Time) ([]*entity.Topic, error)
+	Create(ctx context.Context, topic *entity.Topic) error
+	HasPosts(ctx context.Context, id int64) (bool, error)
+	Move(ctx context.Context, id, categoryID int64) error
+	CreateRedirect(ctx context.Context, from *entity.Topic, targetID int64, at time.Time) (int64, error)
+	MarkRedirect(ctx context.Context, id, targetID int64, at time.Time) error
+	MovePosts(ctx context.Context, fromID, toID int64, postIDs []int64) (int64, error)
+	// RefreshStats пересчитывает счётчики и первый пост топиков после переноса постов.
+	RefreshStats(ctx context.Context, ids ...int64) error
+	// AddViews записывает отметки просмотров и прибавляет к views_count только новые:
+	// отметка, уже записанная другой репликой, просмотр не добавляет.
+	AddViews(ctx context.Context, views []entity.TopicView) error
+	// DeleteViewsBefore удаляет отметки окон с номером меньше bucket.
+	DeleteViewsBefore(ctx context.Context, bucket int64) error
+	ListTop(ctx context.Context, period entity.RankingPeriod, categoryIDs []int64, limit, offset int) ([]*entity.Topic, int64, error)
 }
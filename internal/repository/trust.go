@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+type TrustRepository interface {
+	// Stats собирает слагаемые репутации; контент, опубликованный раньше survivedBefore, считается прошедшим проверку.
+	Stats(ctx context.Context, userID int64, survivedBefore time.Time) (entity.ReputationStats, error)
+	// GetOverride возвращает назначенный уровень или ErrNotFound.
+	GetOverride(ctx context.Context, userID int64) (*entity.TrustOverride, error)
+	SetOverride(ctx context.Context, o *entity.TrustOverride) error
+	// DeleteOverride — ErrNotFound, если уровень не назначался.
+	DeleteOverride(ctx context.Context, userID int64) error
+}
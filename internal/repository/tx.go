@@ -0,0 +1,12 @@
+package repository
+
+import "context"
+
+// Transactor выполняет fn в одной транзакции. Репозитории, вызванные с ctx из fn,
+// работают внутри неё; вложенный WithinTx присоединяется к внешней транзакции.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// AfterCommit откладывает fn до коммита транзакции из ctx; вне транзакции fn выполняется сразу.
+	// fn получает ctx без транзакции, а при откате не вызывается.
+	AfterCommit(ctx context.Context, fn func(ctx context.Context))
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, w *entity.Webhook) error
+	GetByID(ctx context.Context, id int64) (*entity.Webhook, error)
+	List(ctx context.Context, limit, offset int) ([]*entity.Webhook, int64, error)
+	// ListDeliverable возвращает активные вебхуки не в dead-letter.
+	ListDeliverable(ctx context.Context) ([]*entity.Webhook, error)
+	Update(ctx context.Context, w *entity.Webhook) error
+	Delete(ctx context.Context, id int64) error
+	// Reactivate выводит вебхук из dead-letter и сбрасывает счётчик ошибок.
+	Reactivate(ctx context.Context, id int64, at time.Time) error
+	// RecordResult обновляет счётчик подряд идущих ошибок. При failures >= deadAfter вебхук
+	// уходит в dead-letter вместе с ожидающими доставками; возвращает true, если это произошло.
+	RecordResult(ctx context.Context, id int64, success bool, deadAfter int, at time.Time) (bool, error)
+
+	// EnqueueDeliveries создаёт доставки; повтор пары (webhook, event) игнорируется.
+	EnqueueDeliveries(ctx context.Context, deliveries []*entity.WebhookDelivery) error
+	// ClaimDue забирает до limit ожидающих доставок и откладывает их до leaseUntil,
+	// чтобы параллельный воркер не отправил их повторно.
+	ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*entity.WebhookDelivery, error)
+	SaveDeliveryResult(ctx context.Context, d *entity.WebhookDelivery) error
+	GetDelivery(ctx context.Context, id int64) (*entity.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, webhookID int64, status entity.DeliveryStatus, limit, offset int) ([]*entity.WebhookDelivery, int64, error)
+	// ResetDelivery возвращает доставку в очередь с нулём попыток.
+	ResetDelivery(ctx context.Context, id int64, at time.Time) error
+	// DeleteFinishedBefore удаляет до limit доставленных и исчерпавших попытки доставок, созданных раньше before.
+	DeleteFinishedBefore(ctx context.Context, before time.Time, limit int) (int64, error)
+}
@@ -0,0 +1,80 @@
+package spam
+
+import (
+	"math"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+const (
+	// interestingTokens — сколько самых «говорящих» слов участвует в оценке (по Грэму).
+	interestingTokens = 15
+	// priorStrength — вес априорной вероятности 0.5 для редких слов (по Робинсону).
+	priorStrength = 1.0
+	minTokenLen   = 2
+	maxTokenLen   = 32
+)
+
+// Tokens — уникальные слова текста и хосты ссылок для классификатора.
+func Tokens(text string) []string {
+	seen := make(map[string]struct{})
+	var tokens []string
+	add := func(t string) {
+		if _, ok := seen[t]; ok {
+			return
+		}
+		seen[t] = struct{}{}
+		tokens = append(tokens, t)
+	}
+
+	for _, w := range Words(text) {
+		if n := utf8.RuneCountInString(w); n >= minTokenLen && n <= maxTokenLen {
+			add(w)
+		}
+	}
+	for _, host := range Links(text) {
+		add("host:" + host)
+	}
+	return tokens
+}
+
+// Classify возвращает вероятность того, что текст из tokens — спам.
+// stats — частоты слов, spamDocs и hamDocs — размер обучающей выборки по классам.
+func Classify(tokens []string, stats map[string]entity.TokenStats, spamDocs, hamDocs int64) float64 {
+	if spamDocs == 0 || hamDocs == 0 {
+		return 0.5
+	}
+
+	probs := make([]float64, 0, len(tokens))
+	for _, t := range tokens {
+		s, ok := stats[t]
+		if !ok || s.Spam+s.Ham == 0 {
+			continue
+		}
+		spamFreq := math.Min(float64(s.Spam)/float64(spamDocs), 1)
+		hamFreq := math.Min(float64(s.Ham)/float64(hamDocs), 1)
+		p := spamFreq / (spamFreq + hamFreq)
+		n := float64(s.Spam + s.Ham)
+		probs = append(probs, (priorStrength*0.5+n*p)/(priorStrength+n))
+	}
+	if len(probs) == 0 {
+		return 0.5
+	}
+
+	sort.Slice(probs, func(i, j int) bool {
+		return math.Abs(probs[i]-0.5) > math.Abs(probs[j]-0.5)
+	})
+	if len(probs) > interestingTokens {
+		probs = probs[:interestingTokens]
+	}
+
+	// Складываем в логарифмах, чтобы произведение малых вероятностей не ушло в ноль
+	var eta float64
+	for _, p := range probs {
+		p = math.Min(math.Max(p, 0.0001), 0.9999)
+		eta += math.Log(1-p) - math.Log(p)
+	}
+	return 1 / (1 + math.Exp(eta))
+}
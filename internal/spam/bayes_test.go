@@ -0,0 +1,61 @@
+package spam
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+func TestTokens(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "unique words", text: "Cheap cheap pills", want: []string{"cheap", "pills"}},
+		{name: "too short words are skipped", text: "a b ok", want: []string{"ok"}},
+		{name: "link hosts", text: "visit www.pills.biz now", want: []string{"visit", "www", "pills", "biz", "now", "host:pills.biz"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Tokens(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Tokens(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassify(t *testing.T) {
+	stats := map[string]entity.TokenStats{
+		"viagra":         {Spam: 90, Ham: 1},
+		"casino":         {Spam: 70, Ham: 2},
+		"host:pills.biz": {Spam: 40, Ham: 0},
+		"golang":         {Spam: 1, Ham: 80},
+		"compiler":       {Spam: 0, Ham: 50},
+		"the":            {Spam: 100, Ham: 100},
+		"rare":           {Spam: 1, Ham: 0},
+	}
+
+	tests := []struct {
+		name              string
+		tokens            []string
+		spamDocs, hamDocs int64
+		min, max          float64
+	}{
+		{name: "no training data", tokens: []string{"viagra"}, spamDocs: 0, hamDocs: 100, min: 0.5, max: 0.5},
+		{name: "unknown tokens", tokens: []string{"hello", "world"}, spamDocs: 100, hamDocs: 100, min: 0.5, max: 0.5},
+		{name: "spammy text", tokens: []string{"viagra", "casino", "host:pills.biz", "the"}, spamDocs: 100, hamDocs: 100, min: 0.99, max: 1},
+		{name: "normal text", tokens: []string{"golang", "compiler", "the"}, spamDocs: 100, hamDocs: 100, min: 0, max: 0.01},
+		{name: "neutral word", tokens: []string{"the"}, spamDocs: 100, hamDocs: 100, min: 0.45, max: 0.55},
+		// Одно редкое слово почти ничего не говорит: априорная 0.5 тянет оценку к середине
+		{name: "single rare word", tokens: []string{"rare"}, spamDocs: 100, hamDocs: 100, min: 0.5, max: 0.8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if p := Classify(tt.tokens, stats, tt.spamDocs, tt.hamDocs); p < tt.min || p > tt.max {
+				t.Fatalf("Classify() = %v, want within [%v, %v]", p, tt.min, tt.max)
+			}
+		})
+	}
+}
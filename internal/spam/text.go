@@ -0,0 +1,94 @@
+// Package spam содержит разбор текста для антиспама: нормализацию, отпечатки,
+// ссылки и наивный байесовский классификатор. Хранилище и решения — в usecase.
+package spam
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/fnv"
+	"math/bits"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// shingleSize — по сколько слов склеивается шингл для SimHash.
+const shingleSize = 3
+
+var linkRe = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"']+`)
+
+// Words разбивает текст на слова в нижнем регистре; пунктуация отбрасывается.
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Normalize приводит текст к виду, в котором мелкие правки (регистр, пробелы, знаки) не меняют отпечаток.
+func Normalize(text string) string {
+	return strings.Join(Words(text), " ")
+}
+
+// Hash — отпечаток для точных повторов.
+func Hash(normalized string) string {
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// SimHash — 64-битный отпечаток по шинглам слов: у похожих текстов отличается в немногих битах.
+func SimHash(words []string) uint64 {
+	if len(words) == 0 {
+		return 0
+	}
+	n := max(len(words)-shingleSize+1, 1)
+
+	var weights [64]int
+	for i := 0; i < n; i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:min(i+shingleSize, len(words))], " ")))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var out uint64
+	for bit, w := range weights {
+		if w > 0 {
+			out |= 1 << bit
+		}
+	}
+	return out
+}
+
+// Distance — расстояние Хэмминга между двумя SimHash.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Links возвращает хосты всех ссылок в тексте, в нижнем регистре и без www.
+func Links(text string) []string {
+	matches := linkRe.FindAllString(text, -1)
+	hosts := make([]string, 0, len(matches))
+	for _, m := range matches {
+		if !strings.Contains(m, "://") {
+			m = "http://" + m
+		}
+		u, err := url.Parse(strings.TrimRight(m, ".,;:!?)"))
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		hosts = append(hosts, strings.TrimPrefix(strings.ToLower(u.Hostname()), "www."))
+	}
+	return hosts
+}
+
+// MatchDomain проверяет, что host — это domain или его поддомен.
+func MatchDomain(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
@@ -0,0 +1,118 @@
+package spam
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "case and punctuation", text: "Hello, World!!!", want: "hello world"},
+		{name: "whitespace", text: "  one\t\ttwo\nthree  ", want: "one two three"},
+		{name: "digits and cyrillic", text: "Скидка 50% — ТОЛЬКО сегодня", want: "скидка 50 только сегодня"},
+		{name: "only punctuation", text: "?!... ---", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.text); got != tt.want {
+				t.Fatalf("Normalize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimHash(t *testing.T) {
+	const base = "Buy cheap watches online today with free shipping to any country and a full warranty " +
+		"on every single order you place with our store this week"
+
+	tests := []struct {
+		name    string
+		text    string
+		maxDist int
+		minDist int
+	}{
+		{
+			name: "same text after normalization",
+			text: "buy CHEAP watches, online today with free shipping to any country and a full warranty " +
+				"on every single order you place with our store this week!!!",
+			maxDist: 0,
+		},
+		{
+			name: "one word replaced",
+			text: "Buy cheap watches online today with free shipping to any country and a full warranty " +
+				"on every single order you place with our shop this week",
+			maxDist: 12,
+		},
+		{
+			name: "one word removed",
+			text: "Buy cheap watches online today with free shipping to any country and a full warranty " +
+				"on every order you place with our store this week",
+			maxDist: 12,
+		},
+		{
+			name: "unrelated text",
+			text: "The meeting about the new release schedule moved to Thursday afternoon because half " +
+				"of the team is travelling to the conference on Monday",
+			minDist: 20,
+			maxDist: 64,
+		},
+	}
+	want := SimHash(Words(base))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Distance(want, SimHash(Words(tt.text)))
+			if d < tt.minDist || d > tt.maxDist {
+				t.Fatalf("Distance() = %d, want within [%d, %d]", d, tt.minDist, tt.maxDist)
+			}
+		})
+	}
+
+	if got := SimHash(nil); got != 0 {
+		t.Fatalf("SimHash(nil) = %x, want 0", got)
+	}
+	// Текст короче шингла всё равно получает отпечаток
+	if SimHash([]string{"hi"}) == 0 {
+		t.Fatal("SimHash of a single word is 0")
+	}
+}
+
+func TestLinks(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "no links", text: "just text", want: []string{}},
+		{name: "scheme and www", text: "see https://Example.com/a and www.test.org.", want: []string{"example.com", "test.org"}},
+		{name: "trailing punctuation", text: "(look at http://spam.io/x?y=1)", want: []string{"spam.io"}},
+		{name: "www is stripped from subdomains only as a prefix", text: "http://www.shop.example.com", want: []string{"shop.example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Links(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Links(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchDomain(t *testing.T) {
+	tests := []struct {
+		host, domain string
+		want         bool
+	}{
+		{"example.com", "example.com", true},
+		{"shop.example.com", "example.com", true},
+		{"badexample.com", "example.com", false},
+		{"example.com", "shop.example.com", false},
+	}
+	for _, tt := range tests {
+		if got := MatchDomain(tt.host, tt.domain); got != tt.want {
+			t.Errorf("MatchDomain(%q, %q) = %v, want %v", tt.host, tt.domain, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+type AuditUseCase interface {
+	List(ctx context.Context, filter repository.AuditFilter, limit, offset int) ([]*entity.AuditEntry, int64, error)
+	// Cleanup удаляет записи старше retention пачками по auditCleanupBatch.
+	Cleanup(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+// auditCleanupBatch — сколько записей журнала удаляется за один запрос.
+const auditCleanupBatch = 5000
+
+type auditUseCase struct {
+	auditRepo repository.AuditRepository
+	logger    *slog.Logger
+}
+
+func NewAuditUseCase(auditRepo repository.AuditRepository, logger *slog.Logger) AuditUseCase {
+	return &auditUseCase{
+		auditRepo: auditRepo,
+		logger:    logger,
+	}
+}
+
+func (uc *auditUseCase) List(ctx context.Context, filter repository.AuditFilter, limit, offset int) ([]*entity.AuditEntry, int64, error) {
+	limit, offset = normalizePage(limit, offset)
+	return uc.auditRepo.List(ctx, filter, limit, offset)
+}
+
+func (uc *auditUseCase) Cleanup(ctx context.Context, retention time.Duration) (int64, error) {
+	before := time.Now().UTC().Add(-retention)
+
+	var total int64
+	for {
+		n, err := uc.auditRepo.DeleteBefore(ctx, before, auditCleanupBatch)
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if n < auditCleanupBatch {
+			break
+		}
+	}
+
+	if total > 0 {
+		uc.logger.Info("old audit entries removed", slog.Int64("rows", total))
+	}
+	return total, nil
+}
@@ -12,27 +12,48 @@ import (
 )
 
 type CategoryUseCase interface {
-	CreateCategory(ctx context.Context, category *entity.Category) (*entity.Category, error)
+	// Изменяющие методы принимают actorID — кто выполняет действие, для журнала аудита.
+	CreateCategory(ctx context.Context, category *entity.Category, actorID int64) (*entity.Category, error)
 	GetByID(ctx context.Context, id int64) (*entity.Category, error)
 	GetBySlug(ctx context.Context, slug string) (*entity.Category, error)
 	List(ctx context.Context, limit, offset int) ([]*entity.Category, int64, error)
-	UpdateCategory(ctx context.Context, category *entity.Category) (*entity.Category, error)
-	DeleteCategory(ctx context.Context, id int64) error
+	// UpdateCategory применяет правку, если category.Version совпадает с текущей (0 — без проверки), иначе ErrVersionMismatch.
+	UpdateCategory(ctx context.Context, category *entity.Category, actorID int64) (*entity.Category, error)
+	// DeleteCategory не удаляет категорию с подкатегориями (ErrCategoryHasChildren): их нужно перенести или удалить раньше.
+	DeleteCategory(ctx context.Context, id, actorID int64) error
+	ListTree(ctx context.Context) ([]*entity.Category, error)
+	ListChildren(ctx context.Context, parentID int64, limit, offset int) ([]*entity.Category, int64, error)
+	GetBreadcrumbs(ctx context.Context, id int64) ([]*entity.Category, error)
+	ReorderCategories(ctx context.Context, parentID int64, orderedIDs []int64, actorID int64) error
+	// SetPremoderated включает премодерацию нового контента в категории и её подкатегориях.
+	SetPremoderated(ctx context.Context, id int64, premoderated bool, actorID int64) (*entity.Category, error)
 }
 
+// MaxCategoryDepth — максимальная вложенность категорий, корень считается первым уровнем.
+const MaxCategoryDepth = 5
+
 type categoryUseCase struct {
 	categoryRepo repository.CategoryRepository
+	auditRepo    repository.AuditRepository
+	tx           repository.Transactor
 	logger       *slog.Logger
 }
 
-func NewCategoryUseCase(categoryRepo repository.CategoryRepository, logger *slog.Logger) CategoryUseCase {
+func NewCategoryUseCase(
+	categoryRepo repository.CategoryRepository,
+	auditRepo repository.AuditRepository,
+	tx repository.Transactor,
+	logger *slog.Logger,
+) CategoryUseCase {
 	return &categoryUseCase{
 		categoryRepo: categoryRepo,
+		auditRepo:    auditRepo,
+		tx:           tx,
 		logger:       logger,
 	}
 }
 
-func (uc *categoryUseCase) CreateCategory(ctx context.Context, category *entity.Category) (*entity.Category, error) {
+func (uc *categoryUseCase) CreateCategory(ctx context.Context, category *entity.Category, actorID int64) (*entity.Category, error) {
 	// Generate slug if not provided
 	if category.Slug == "" {
 		category.Slug = slug.Make(category.Title)
@@ -45,12 +66,36 @@ func (uc *categoryUseCase) CreateCategory(ctx context.Context, category *entity.
 		return nil, ErrCategoryAlreadyExists
 	}
 
+	if category.ParentID != 0 {
+		if err := uc.validateParent(ctx, 0, category.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Set timestamps
 	now := time.Now().UTC()
 	category.CreatedAt = now
 	category.UpdatedAt = now
 
-	return uc.categoryRepo.Create(ctx, category)
+	var created *entity.Category
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if created, err = uc.categoryRepo.Create(ctx, category); err != nil {
+			return err
+		}
+		return uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    actorID,
+			Action:     entity.AuditActionCategoryCreate,
+			TargetType: entity.AuditTargetCategory,
+			TargetID:   created.ID,
+			After:      categoryFields(created),
+			CreatedAt:  now,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
 }
 
 func (uc *categoryUseCase) GetByID(ctx context.Context, id int64) (*entity.Category, error) {
@@ -94,7 +139,7 @@ func (uc *categoryUseCase) List(ctx context.Context, limit, offset int) ([]*enti
 	return categories, total, nil
 }
 
-func (uc *categoryUseCase) UpdateCategory(ctx context.Context, category *entity.Category) (*entity.Category, error) {
+func (uc *categoryUseCase) UpdateCategory(ctx context.Context, category *entity.Category, actorID int64) (*entity.Category, error) {
 	existing, err := uc.categoryRepo.GetByID(ctx, category.ID)
 	if err != nil {
 		if err == repository.ErrNotFound {
@@ -102,14 +147,245 @@ func (uc *categoryUseCase) UpdateCategory(ctx context.Context, category *entity.
 		}
 		return nil, err
 	}
+	if err := checkVersion(category.Version, existing.Version); err != nil {
+		return nil, err
+	}
+	// Даже без версии от клиента правка не должна затереть то, что изменили после нашего чтения
+	category.Version = existing.Version
+
+	if category.ParentID != existing.ParentID && category.ParentID != 0 {
+		if err := uc.validateParent(ctx, category.ID, category.ParentID); err != nil {
+			return nil, err
+		}
+	}
 
 	// Preserve created_at
 	category.CreatedAt = existing.CreatedAt
 	category.UpdatedAt = time.Now().UTC()
 
-	return uc.categoryRepo.Update(ctx, category)
+	var updated *entity.Category
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if updated, err = uc.categoryRepo.Update(ctx, category); err != nil {
+			return versionConflict(err)
+		}
+		return uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    actorID,
+			Action:     entity.AuditActionCategoryUpdate,
+			TargetType: entity.AuditTargetCategory,
+			TargetID:   category.ID,
+			Before:     categoryFields(existing),
+			After:      categoryFields(updated),
+			CreatedAt:  category.UpdatedAt,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (uc *categoryUseCase) DeleteCategory(ctx context.Context, id, actorID int64) error {
+	existing, err := uc.categoryRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrCategoryNotFound
+		}
+		return err
+	}
+
+	return uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.categoryRepo.Delete(ctx, id); err != nil {
+			if errors.Is(err, repository.ErrConflict) {
+				return ErrCategoryHasChildren
+			}
+			return err
+		}
+		return uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    actorID,
+			Action:     entity.AuditActionCategoryDelete,
+			TargetType: entity.AuditTargetCategory,
+			TargetID:   id,
+			Before:     categoryFields(existing),
+			CreatedAt:  time.Now().UTC(),
+		})
+	})
+}
+
+func (uc *categoryUseCase) SetPremoderated(ctx context.Context, id int64, premoderated bool, actorID int64) (*entity.Category, error) {
+	existing, err := uc.categoryRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	if existing.Premoderated == premoderated {
+		return existing, nil
+	}
+
+	updated := *existing
+	updated.Premoderated = premoderated
+	updated.UpdatedAt = time.Now().UTC()
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.categoryRepo.SetPremoderated(ctx, id, premoderated); err != nil {
+			return err
+		}
+		return uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    actorID,
+			Action:     entity.AuditActionCategoryUpdate,
+			TargetType: entity.AuditTargetCategory,
+			TargetID:   id,
+			Before:     categoryFields(existing),
+			After:      categoryFields(&updated),
+			CreatedAt:  updated.UpdatedAt,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// ListTree возвращает корневые категории с заполненными Children.
+func (uc *categoryUseCase) ListTree(ctx context.Context) ([]*entity.Category, error) {
+	categories, err := uc.categoryRepo.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*entity.Category, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+
+	roots := []*entity.Category{}
+	for _, c := range categories {
+		parent, ok := byID[c.ParentID]
+		if !ok {
+			roots = append(roots, c)
+			continue
+		}
+		parent.Children = append(parent.Children, c)
+	}
+	return roots, nil
+}
+
+func (uc *categoryUseCase) ListChildren(ctx context.Context, parentID int64, limit, offset int) ([]*entity.Category, int64, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if parentID != 0 {
+		if _, err := uc.GetByID(ctx, parentID); err != nil {
+			return nil, 0, err
+		}
+	}
+	return uc.categoryRepo.ListByParent(ctx, parentID, limit, offset)
+}
+
+// GetBreadcrumbs возвращает путь от корня до категории id включительно.
+func (uc *categoryUseCase) GetBreadcrumbs(ctx context.Context, id int64) ([]*entity.Category, error) {
+	path, err := uc.categoryRepo.Ancestors(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return path, nil
+}
+
+// validateParent проверяет, что категорию id (0 — новая) можно поместить в parentID:
+// родитель существует, не лежит в её поддереве и не превышается MaxCategoryDepth.
+func (uc *categoryUseCase) validateParent(ctx context.Context, id, parentID int64) error {
+	if parentID == id {
+		return ErrCategoryCycle
+	}
+
+	path, err := uc.categoryRepo.Ancestors(ctx, parentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrCategoryNotFound
+		}
+		return err
+	}
+	for _, c := range path {
+		if c.ID == id {
+			return ErrCategoryCycle
+		}
+	}
+
+	height := 1
+	if id != 0 {
+		descendants, err := uc.categoryRepo.Descendants(ctx, id)
+		if err != nil {
+			return err
+		}
+		height = subtreeHeight(id, descendants)
+	}
+
+	if len(path)+height > MaxCategoryDepth {
+		uc.logger.Warn("category nesting limit exceeded",
+			slog.Int64("id", id),
+			slog.Int64("parent_id", parentID),
+		)
+		return ErrCategoryTooDeep
+	}
+	return nil
+}
+
+// subtreeHeight считает число уровней в поддереве rootID, включая сам корень.
+func subtreeHeight(rootID int64, descendants []*entity.Category) int {
+	parents := make(map[int64]int64, len(descendants))
+	for _, c := range descendants {
+		parents[c.ID] = c.ParentID
+	}
+
+	height := 1
+	for _, c := range descendants {
+		depth := 1
+		for id := c.ID; id != rootID; id = parents[id] {
+			depth++
+		}
+		if depth > height {
+			height = depth
+		}
+	}
+	return height
 }
 
-func (uc *categoryUseCase) DeleteCategory(ctx context.Context, id int64) error {
-	return uc.categoryRepo.Delete(ctx, id)
+// ReorderCategories задаёт порядок всех детей parentID (0 — корневых категорий) одним запросом.
+func (uc *categoryUseCase) ReorderCategories(ctx context.Context, parentID int64, orderedIDs []int64, actorID int64) error {
+	seen := make(map[int64]struct{}, len(orderedIDs))
+	for _, id := range orderedIDs {
+		if _, ok := seen[id]; ok {
+			return ErrInvalidCategoryOrder
+		}
+		seen[id] = struct{}{}
+	}
+
+	err := uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.categoryRepo.Reorder(ctx, parentID, orderedIDs); err != nil {
+			return err
+		}
+		return uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    actorID,
+			Action:     entity.AuditActionCategoryReorder,
+			TargetType: entity.AuditTargetCategory,
+			TargetID:   parentID,
+			Details:    map[string]any{"ordered_ids": orderedIDs},
+			CreatedAt:  time.Now().UTC(),
+		})
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidOrder) {
+			return ErrInvalidCategoryOrder
+		}
+		uc.logger.Error("failed to reorder categories", slog.String("err", err.Error()))
+		return err
+	}
+	return nil
 }
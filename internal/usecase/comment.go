@@ -2,7 +2,9 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/VaneZ444/forum-service/internal/entity"
@@ -10,53 +12,182 @@ import (
 )
 
 type CommentUseCase interface {
-	CreateComment(ctx context.Context, comment *entity.Comment) (int64, error)
-	GetCommentByID(ctx context.Context, id int64) (*entity.Comment, error)
+	// CreateComment разрешает комментировать только пост, видимый actor: неопубликованный — автору и модераторам, скрытый — модераторам.
+	CreateComment(ctx context.Context, comment *entity.Comment, actor entity.Viewer) (int64, error)
+	GetCommentByID(ctx context.Context, id int64, viewer entity.Viewer) (*entity.Comment, error)
 	ListByPost(ctx context.Context, postID int64, limit, offset int) ([]*entity.Comment, int64, error)
-	UpdateComment(ctx context.Context, comment *entity.Comment) error
-	DeleteComment(ctx context.Context, commentID int64) error
+	// UpdateComment и DeleteComment пишут в журнал аудита действия над чужими комментариями.
+	// Править комментарий могут его автор и модераторы, остальным — ErrForbidden.
+	// Правка сохраняется в истории версий вместе с reason (необязательна).
+	// comment.Version — ожидаемая версия (0 — без проверки); после правки в нём новая.
+	UpdateComment(ctx context.Context, comment *entity.Comment, actor entity.Viewer, reason string) error
+	// RollbackComment возвращает текст версии number новой правкой; только для модераторов.
+	RollbackComment(ctx context.Context, id int64, number int, actor entity.Viewer, reason string) (*entity.Comment, error)
+	// DeleteComment в закрытом топике доступен только модераторам.
+	DeleteComment(ctx context.Context, commentID int64, actor entity.Viewer) error
 }
 
 type commentUseCase struct {
-	commentRepo repository.CommentRepository
-	postRepo    repository.PostRepository
-	logger      *slog.Logger
+	commentRepo  repository.CommentRepository
+	postRepo     repository.PostRepository
+	topicRepo    repository.TopicRepository
+	auditRepo    repository.AuditRepository
+	outboxRepo   repository.OutboxRepository
+	revisionRepo repository.RevisionRepository
+	tx           repository.Transactor
+	guard        PostingGuard
+	limiter      RateLimiter
+	spam         SpamUseCase
+	premod       Premoderation
+	notifier     Notifier
+	logger       *slog.Logger
 }
 
-func NewCommentUseCase(commentRepo repository.CommentRepository, postRepo repository.PostRepository, logger *slog.Logger) CommentUseCase {
+func NewCommentUseCase(
+	commentRepo repository.CommentRepository,
+	postRepo repository.PostRepository,
+	topicRepo repository.TopicRepository,
+	auditRepo repository.AuditRepository,
+	outboxRepo repository.OutboxRepository,
+	revisionRepo repository.RevisionRepository,
+	tx repository.Transactor,
+	guard PostingGuard,
+	limiter RateLimiter,
+	spam SpamUseCase,
+	premod Premoderation,
+	notifier Notifier,
+	logger *slog.Logger,
+) CommentUseCase {
 	return &commentUseCase{
-		commentRepo: commentRepo,
-		postRepo:    postRepo,
-		logger:      logger,
+		commentRepo:  commentRepo,
+		postRepo:     postRepo,
+		topicRepo:    topicRepo,
+		auditRepo:    auditRepo,
+		outboxRepo:   outboxRepo,
+		revisionRepo: revisionRepo,
+		tx:           tx,
+		guard:        guard,
+		limiter:      limiter,
+		spam:         spam,
+		premod:       premod,
+		notifier:     notifier,
+		logger:       logger,
 	}
 }
 
-func (uc *commentUseCase) CreateComment(ctx context.Context, comment *entity.Comment) (int64, error) {
-	_, err := uc.postRepo.GetByID(ctx, comment.PostID)
+func (uc *commentUseCase) CreateComment(ctx context.Context, comment *entity.Comment, actor entity.Viewer) (int64, error) {
+	post, err := uc.postRepo.GetByID(ctx, comment.PostID)
 	if err != nil {
 		uc.logger.Warn("post not found", slog.Int64("postID", comment.PostID), slog.String("err", err.Error()))
 		return 0, ErrPostNotFound
 	}
+	if !actor.CanSee(post.AuthorID, post.Status) {
+		return 0, ErrPostNotFound
+	}
+	// О комментариях к посту, которого остальные не видят, подписчикам знать незачем
+	quiet := post.Status != entity.StatusActive
+	// Новые ответы в закрытый топик не принимаются ни от кого, как и в CreatePost
+	topic, err := openTopic(ctx, uc.topicRepo, post.TopicID, entity.Viewer{})
+	if err != nil {
+		return 0, err
+	}
+	if err := uc.guard.CheckCanPost(ctx, comment.AuthorID, topic.CategoryID); err != nil {
+		return 0, err
+	}
+	if err := uc.limiter.Allow(ctx, entity.RateCommentCreate, UserSubject(comment.AuthorID)); err != nil {
+		return 0, err
+	}
 
 	comment.CreatedAt = time.Now().UTC()
+	comment.Status = entity.StatusActive
+	verdict := uc.spam.Check(ctx, comment.AuthorID, comment.Content)
+	premoderated := !verdict.Flagged() && uc.premod.Required(ctx, comment.AuthorID, topic.CategoryID)
+	held := verdict.Flagged() || premoderated
+	if held {
+		comment.Status = entity.StatusPending
+	}
 
-	id, err := uc.commentRepo.Create(ctx, comment)
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := uc.commentRepo.Create(ctx, comment); err != nil {
+			return err
+		}
+		ref := &entity.ContentRef{
+			Type:       entity.ContentComment,
+			ID:         comment.ID,
+			AuthorID:   comment.AuthorID,
+			TopicID:    topic.ID,
+			PostID:     post.ID,
+			CategoryID: topic.CategoryID,
+			Status:     comment.Status,
+		}
+		if err := uc.spam.Record(ctx, ref, verdict); err != nil {
+			return err
+		}
+		if premoderated {
+			return uc.premod.Hold(ctx, ref)
+		}
+		if held || quiet {
+			// Событие и уведомления уйдут, когда модератор одобрит комментарий
+			return nil
+		}
+		return uc.outboxRepo.Add(ctx, commentEvent(entity.EventCommentCreated, comment, post.TopicID))
+	})
 	if err != nil {
 		uc.logger.Error("failed to create comment", slog.String("err", err.Error()))
 		return 0, err
 	}
+	if held {
+		uc.logger.Info("comment held for moderation",
+			slog.Int64("commentID", comment.ID),
+			slog.Int64("authorID", comment.AuthorID),
+			slog.Any("flags", verdict.Flags),
+			slog.Bool("premoderated", premoderated),
+		)
+		return comment.ID, nil
+	}
+	if !quiet {
+		// Внутри внешней транзакции — после её коммита, как в CreateTopic
+		uc.tx.AfterCommit(ctx, func(ctx context.Context) { uc.notifier.CommentCreated(ctx, post, comment) })
+	}
 
-	return id, nil
+	return comment.ID, nil
 }
 
-func (uc *commentUseCase) DeleteComment(ctx context.Context, commentID int64) error {
-	_, err := uc.commentRepo.GetByID(ctx, commentID)
+func (uc *commentUseCase) DeleteComment(ctx context.Context, commentID int64, actor entity.Viewer) error {
+	actorID := actor.UserID
+	comment, err := uc.commentRepo.GetByID(ctx, commentID)
 	if err != nil {
 		uc.logger.Warn("comment not found", slog.Int64("commentID", commentID), slog.String("err", err.Error()))
 		return ErrCommentNotFound
 	}
+	post, err := uc.postRepo.GetByID(ctx, comment.PostID)
+	if err != nil {
+		uc.logger.Warn("post not found", slog.Int64("postID", comment.PostID), slog.String("err", err.Error()))
+		return ErrPostNotFound
+	}
+	if _, err := openTopic(ctx, uc.topicRepo, post.TopicID, actor); err != nil {
+		return err
+	}
 
-	err = uc.commentRepo.Delete(ctx, commentID)
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.commentRepo.Delete(ctx, commentID); err != nil {
+			return err
+		}
+		if err := uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    actorID,
+			Action:     entity.AuditActionCommentDelete,
+			TargetType: entity.AuditTargetComment,
+			TargetID:   commentID,
+			Before:     commentFields(comment, post.TopicID),
+			CreatedAt:  time.Now().UTC(),
+		}); err != nil {
+			return err
+		}
+		return uc.outboxRepo.Add(ctx, deletedEvent(entity.EventCommentDeleted, "comment", commentID, map[string]any{
+			"post_id":  comment.PostID,
+			"topic_id": post.TopicID,
+		}))
+	})
 	if err != nil {
 		uc.logger.Error("failed to delete comment", slog.String("err", err.Error()))
 		return err
@@ -65,17 +196,76 @@ func (uc *commentUseCase) DeleteComment(ctx context.Context, commentID int64) er
 	return nil
 }
 
-func (uc *commentUseCase) UpdateComment(ctx context.Context, comment *entity.Comment) error {
-	// Optionally, you can check if the comment exists before updating
-	_, err := uc.commentRepo.GetByID(ctx, comment.ID)
+func (uc *commentUseCase) UpdateComment(ctx context.Context, comment *entity.Comment, actor entity.Viewer, reason string) error {
+	actorID := actor.UserID
+	reason = strings.TrimSpace(reason)
+	if err := validateEditReason(reason); err != nil {
+		return err
+	}
+	existing, err := uc.commentRepo.GetByID(ctx, comment.ID)
 	if err != nil {
 		uc.logger.Warn("comment not found", slog.Int64("commentID", comment.ID), slog.String("err", err.Error()))
 		return ErrCommentNotFound
 	}
+	if err := checkVersion(comment.Version, existing.Version); err != nil {
+		return err
+	}
+	if actorID != existing.AuthorID && !actor.Moderator {
+		return ErrForbidden
+	}
+	comment.Version = existing.Version
+	post, err := uc.postRepo.GetByID(ctx, comment.PostID)
+	if err != nil {
+		uc.logger.Warn("post not found", slog.Int64("postID", comment.PostID), slog.String("err", err.Error()))
+		return ErrPostNotFound
+	}
+	topic, err := openTopic(ctx, uc.topicRepo, post.TopicID, actor)
+	if err != nil {
+		return err
+	}
+	if err := uc.guard.CheckCanEdit(ctx, actorID, topic.CategoryID); err != nil {
+		return err
+	}
+	if err := uc.spam.CheckEdit(ctx, actorID, comment.Content); err != nil {
+		return err
+	}
 
 	// Update only the content and the updated time
 	comment.UpdatedAt = time.Now().UTC()
-	err = uc.commentRepo.Update(ctx, comment)
+	comment.EditedBy, comment.EditedAt = actorID, &comment.UpdatedAt
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.commentRepo.Update(ctx, comment); err != nil {
+			return versionConflict(err)
+		}
+		if err := recordRevision(ctx, uc.revisionRepo, commentRevision(existing), &entity.Revision{
+			TargetType: entity.ContentComment,
+			TargetID:   comment.ID,
+			Content:    comment.Content,
+			EditorID:   actorID,
+			Reason:     reason,
+			CreatedAt:  comment.UpdatedAt,
+		}); err != nil {
+			return err
+		}
+		if actorID != existing.AuthorID {
+			if err := uc.auditRepo.Create(ctx, &entity.AuditEntry{
+				ActorID:    actorID,
+				Action:     entity.AuditActionCommentUpdate,
+				TargetType: entity.AuditTargetComment,
+				TargetID:   comment.ID,
+				Before:     commentFields(existing, post.TopicID),
+				After:      commentFields(comment, post.TopicID),
+				Reason:     reason,
+				CreatedAt:  comment.UpdatedAt,
+			}); err != nil {
+				return err
+			}
+		}
+		if !existing.Status.Published() {
+			return nil // о неопубликованном подписчики узнают после одобрения
+		}
+		return uc.outboxRepo.Add(ctx, commentEvent(entity.EventCommentUpdated, comment, post.TopicID))
+	})
 	if err != nil {
 		uc.logger.Error("failed to update comment", slog.String("err", err.Error()))
 		return err
@@ -84,12 +274,86 @@ func (uc *commentUseCase) UpdateComment(ctx context.Context, comment *entity.Com
 	return nil
 }
 
-func (uc *commentUseCase) GetCommentByID(ctx context.Context, id int64) (*entity.Comment, error) {
+func (uc *commentUseCase) RollbackComment(ctx context.Context, id int64, number int, actor entity.Viewer, reason string) (*entity.Comment, error) {
+	if actor.UserID <= 0 {
+		return nil, ErrUnauthenticated
+	}
+	if !actor.Moderator {
+		return nil, fmt.Errorf("%w: only moderators can roll back edits", ErrForbidden)
+	}
+	reason = strings.TrimSpace(reason)
+	if err := validateEditReason(reason); err != nil {
+		return nil, err
+	}
 	comment, err := uc.commentRepo.GetByID(ctx, id)
 	if err != nil {
 		uc.logger.Warn("comment not found", slog.Int64("id", id), slog.String("err", err.Error()))
 		return nil, ErrCommentNotFound
 	}
+	post, err := uc.postRepo.GetByID(ctx, comment.PostID)
+	if err != nil {
+		return nil, ErrPostNotFound
+	}
+	target, err := getRevision(ctx, uc.revisionRepo, entity.ContentComment, id, number)
+	if err != nil {
+		return nil, err
+	}
+
+	before := commentFields(comment, post.TopicID)
+	prev := commentRevision(comment)
+	comment.Content = target.Content
+	comment.UpdatedAt = time.Now().UTC()
+	comment.EditedBy, comment.EditedAt = actor.UserID, &comment.UpdatedAt
+
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.commentRepo.Update(ctx, comment); err != nil {
+			return versionConflict(err)
+		}
+		if err := recordRevision(ctx, uc.revisionRepo, prev, &entity.Revision{
+			TargetType:   entity.ContentComment,
+			TargetID:     id,
+			Content:      comment.Content,
+			EditorID:     actor.UserID,
+			Reason:       reason,
+			RestoredFrom: number,
+			CreatedAt:    comment.UpdatedAt,
+		}); err != nil {
+			return err
+		}
+		if err := uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    actor.UserID,
+			Action:     entity.AuditActionCommentRollback,
+			TargetType: entity.AuditTargetComment,
+			TargetID:   id,
+			Reason:     reason,
+			Before:     before,
+			After:      commentFields(comment, post.TopicID),
+			Details:    map[string]any{"revision": number},
+			CreatedAt:  comment.UpdatedAt,
+		}); err != nil {
+			return err
+		}
+		if !comment.Status.Published() {
+			return nil
+		}
+		return uc.outboxRepo.Add(ctx, commentEvent(entity.EventCommentUpdated, comment, post.TopicID))
+	})
+	if err != nil {
+		uc.logger.Error("failed to roll back comment", slog.Int64("id", id), slog.String("err", err.Error()))
+		return nil, err
+	}
+	return comment, nil
+}
+
+func (uc *commentUseCase) GetCommentByID(ctx context.Context, id int64, viewer entity.Viewer) (*entity.Comment, error) {
+	comment, err := uc.commentRepo.GetByID(ctx, id)
+	if err != nil {
+		uc.logger.Warn("comment not found", slog.Int64("id", id), slog.String("err", err.Error()))
+		return nil, ErrCommentNotFound
+	}
+	if !viewer.CanSee(comment.AuthorID, comment.Status) {
+		return nil, ErrCommentNotFound
+	}
 	return comment, nil
 }
 
@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+func TestUpdateCommentPermissions(t *testing.T) {
+	const authorID, otherID = 1, 2
+
+	tests := []struct {
+		name  string
+		actor entity.Viewer
+		want  error
+	}{
+		{name: "author", actor: entity.Viewer{UserID: authorID}},
+		{name: "moderator", actor: entity.Viewer{UserID: otherID, Moderator: true}},
+		{name: "foreign comment", actor: entity.Viewer{UserID: otherID}, want: ErrForbidden},
+		{name: "anonymous", actor: entity.Viewer{}, want: ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &commentUseCase{
+				commentRepo: &fakeCommentRepo{comment: &entity.Comment{ID: 30, PostID: 10, AuthorID: authorID, Version: 1}},
+				postRepo:    &fakePostRepo{post: &entity.Post{ID: 10, TopicID: 20}},
+				topicRepo:   &fakeTopicRepo{topic: &entity.Topic{ID: 20}},
+				// Проверка санкций идёт после проверки прав и останавливает правку
+				guard:  &fakeGuard{err: ErrUserBanned},
+				logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+			}
+			err := uc.UpdateComment(context.Background(), &entity.Comment{ID: 30, PostID: 10, Content: "new content"}, tt.actor, "")
+
+			want := tt.want
+			if want == nil {
+				want = ErrUserBanned
+			}
+			if !errors.Is(err, want) {
+				t.Fatalf("UpdateComment() error = %v, want %v", err, want)
+			}
+		})
+	}
+}
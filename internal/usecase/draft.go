@@ -0,0 +1,364 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+type DraftConfig struct {
+	MaxPerUser       int           // черновиков у одного автора
+	MaxTitleLength   int           // в символах
+	MaxContentLength int           // в символах
+	MaxScheduleAhead time.Duration // насколько вперёд можно запланировать публикацию
+	PublishBatch     int           // черновиков за один проход планировщика
+	// PublishLease — на сколько черновик блокируется на время публикации; если реплика упала, по истечении его возьмёт другая
+	PublishLease time.Duration
+}
+
+func DefaultDraftConfig() DraftConfig {
+	return DraftConfig{
+		MaxPerUser:       100,
+		MaxTitleLength:   300,
+		MaxContentLength: 100_000,
+		MaxScheduleAhead: 365 * 24 * time.Hour,
+		PublishBatch:     50,
+		PublishLease:     5 * time.Minute,
+	}
+}
+
+// DraftUseCase — черновики автора. Все методы, кроме PublishDue, видят только черновики actorID:
+// чужой черновик — ErrDraftNotFound.
+type DraftUseCase interface {
+	CreateDraft(ctx context.Context, d *entity.Draft, actorID int64) (*entity.Draft, error)
+	GetDraft(ctx context.Context, id, actorID int64) (*entity.Draft, error)
+	ListDrafts(ctx context.Context, actorID int64, limit, offset int) ([]*entity.Draft, int64, error)
+	// UpdateDraft меняет текст и место публикации; запланированная публикация сохраняется.
+	UpdateDraft(ctx context.Context, d *entity.Draft, actorID int64) (*entity.Draft, error)
+	DeleteDraft(ctx context.Context, id, actorID int64) error
+	// PublishDraft публикует черновик сейчас через CreateTopic, CreatePost или CreateComment — с их проверками,
+	// лимитами, антиспамом и премодерацией — и удаляет его. Возвращает id созданного топика, поста или комментария.
+	PublishDraft(ctx context.Context, id, actorID int64) (int64, error)
+	// SchedulePublish планирует публикацию на publishAt; нулевое время снимает расписание.
+	SchedulePublish(ctx context.Context, id int64, publishAt time.Time, actorID int64) (*entity.Draft, error)
+	// PublishDue публикует черновики, время которых пришло; вызывается фоновым планировщиком.
+	PublishDue(ctx context.Context) error
+}
+
+type draftUseCase struct {
+	draftRepo repository.DraftRepository
+	tx        repository.Transactor
+	topics    TopicUseCase
+	posts     PostUseCase
+	comments  CommentUseCase
+	cfg       DraftConfig
+	logger    *slog.Logger
+}
+
+func NewDraftUseCase(
+	draftRepo repository.DraftRepository,
+	tx repository.Transactor,
+	topics TopicUseCase,
+	posts PostUseCase,
+	comments CommentUseCase,
+	cfg DraftConfig,
+	logger *slog.Logger,
+) DraftUseCase {
+	return &draftUseCase{
+		draftRepo: draftRepo,
+		tx:        tx,
+		topics:    topics,
+		posts:     posts,
+		comments:  comments,
+		cfg:       cfg,
+		logger:    logger,
+	}
+}
+
+func (uc *draftUseCase) CreateDraft(ctx context.Context, d *entity.Draft, actorID int64) (*entity.Draft, error) {
+	if actorID <= 0 {
+		return nil, ErrUnauthenticated
+	}
+	if err := uc.validate(d); err != nil {
+		return nil, err
+	}
+	count, err := uc.draftRepo.CountByAuthor(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= int64(uc.cfg.MaxPerUser) {
+		return nil, fmt.Errorf("%w: at most %d drafts per user", ErrTooManyDrafts, uc.cfg.MaxPerUser)
+	}
+
+	d.AuthorID = actorID
+	d.CreatedAt = time.Now().UTC()
+	d.PublishAt, d.LastError = nil, ""
+	if err := uc.draftRepo.Create(ctx, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (uc *draftUseCase) GetDraft(ctx context.Context, id, actorID int64) (*entity.Draft, error) {
+	if actorID <= 0 {
+		return nil, ErrUnauthenticated
+	}
+	d, err := uc.draftRepo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrDraftNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if d.AuthorID != actorID {
+		return nil, ErrDraftNotFound
+	}
+	return d, nil
+}
+
+func (uc *draftUseCase) ListDrafts(ctx context.Context, actorID int64, limit, offset int) ([]*entity.Draft, int64, error) {
+	if actorID <= 0 {
+		return nil, 0, ErrUnauthenticated
+	}
+	limit, offset = normalizePage(limit, offset)
+	return uc.draftRepo.ListByAuthor(ctx, actorID, limit, offset)
+}
+
+func (uc *draftUseCase) UpdateDraft(ctx context.Context, d *entity.Draft, actorID int64) (*entity.Draft, error) {
+	existing, err := uc.GetDraft(ctx, d.ID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.validate(d); err != nil {
+		return nil, err
+	}
+	if existing.PublishAt != nil {
+		if err := uc.validatePublishable(d); err != nil {
+			return nil, err
+		}
+	}
+
+	d.AuthorID = existing.AuthorID
+	d.CreatedAt = existing.CreatedAt
+	d.PublishAt, d.LastError = existing.PublishAt, existing.LastError
+	d.UpdatedAt = time.Now().UTC()
+	if err := uc.draftRepo.Update(ctx, d); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrDraftNotFound
+		}
+		return nil, err
+	}
+	return d, nil
+}
+
+func (uc *draftUseCase) DeleteDraft(ctx context.Context, id, actorID int64) error {
+	if _, err := uc.GetDraft(ctx, id, actorID); err != nil {
+		return err
+	}
+	if err := uc.draftRepo.Delete(ctx, id); errors.Is(err, repository.ErrNotFound) {
+		return ErrDraftNotFound
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (uc *draftUseCase) PublishDraft(ctx context.Context, id, actorID int64) (int64, error) {
+	d, err := uc.GetDraft(ctx, id, actorID)
+	if err != nil {
+		return 0, err
+	}
+	if err := uc.validatePublishable(d); err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+	if err := uc.draftRepo.Lock(ctx, id, now, now.Add(uc.cfg.PublishLease)); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrConflict):
+			return 0, ErrDraftPublishing // его как раз публикует планировщик
+		case errors.Is(err, repository.ErrNotFound):
+			return 0, ErrDraftNotFound
+		}
+		return 0, err
+	}
+
+	createdID, err := uc.publish(ctx, d)
+	if err != nil {
+		if unlockErr := uc.draftRepo.Unlock(context.WithoutCancel(ctx), id); unlockErr != nil {
+			uc.logger.Error("failed to unlock draft", slog.Int64("id", id), slog.String("err", unlockErr.Error()))
+		}
+		return 0, err
+	}
+	uc.logger.Info("draft published", slog.Int64("id", id), slog.String("kind", string(d.Kind)), slog.Int64("createdID", createdID))
+	return createdID, nil
+}
+
+func (uc *draftUseCase) SchedulePublish(ctx context.Context, id int64, publishAt time.Time, actorID int64) (*entity.Draft, error) {
+	d, err := uc.GetDraft(ctx, id, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	var at *time.Time
+	if !publishAt.IsZero() {
+		now := time.Now().UTC()
+		publishAt = publishAt.UTC()
+		switch {
+		case !publishAt.After(now):
+			return nil, fmt.Errorf("%w: publish time must be in the future", ErrInvalidDraft)
+		case publishAt.After(now.Add(uc.cfg.MaxScheduleAhead)):
+			return nil, fmt.Errorf("%w: publish time is more than %s ahead", ErrInvalidDraft, uc.cfg.MaxScheduleAhead)
+		}
+		// Очевидные ошибки ловим сразу, а не в момент публикации
+		if err := uc.validatePublishable(d); err != nil {
+			return nil, err
+		}
+		at = &publishAt
+	}
+
+	if err := uc.draftRepo.SetSchedule(ctx, id, at); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrDraftNotFound
+		}
+		return nil, err
+	}
+	d.PublishAt, d.LastError = at, ""
+	return d, nil
+}
+
+func (uc *draftUseCase) PublishDue(ctx context.Context) error {
+	now := time.Now().UTC()
+	drafts, err := uc.draftRepo.LockDue(ctx, now, now.Add(uc.cfg.PublishLease), uc.cfg.PublishBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range drafts {
+		createdID, err := uc.publish(ctx, d)
+		var rateErr *RateLimitError
+		switch {
+		case err == nil:
+			uc.logger.Info("scheduled draft published",
+				slog.Int64("id", d.ID),
+				slog.String("kind", string(d.Kind)),
+				slog.Int64("createdID", createdID),
+			)
+		case errors.As(err, &rateErr):
+			// Лимит временный — расписание оставляем, черновик возьмёт следующий проход
+			if err := uc.draftRepo.Unlock(ctx, d.ID); err != nil {
+				return err
+			}
+		default:
+			// Остальное — в основном отказы CreateTopic/CreatePost/CreateComment: автор увидит причину в last_error и перепланирует
+			uc.logger.Warn("scheduled draft was not published", slog.Int64("id", d.ID), slog.String("err", err.Error()))
+			if err := uc.draftRepo.MarkFailed(ctx, d.ID, err.Error()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// publish создаёт контент из черновика от имени автора и удаляет черновик в той же транзакции:
+// если удалить не удалось, не создаётся и контент, и повторная публикация не даст дубля.
+// CreateTopic, CreatePost и CreateComment присоединяются к этой транзакции, а уведомления
+// откладывают через AfterCommit — они уходят только после коммита и не могут его сорвать.
+func (uc *draftUseCase) publish(ctx context.Context, d *entity.Draft) (int64, error) {
+	var createdID int64
+	err := uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if createdID, err = uc.create(ctx, d); err != nil {
+			return err
+		}
+		return uc.draftRepo.Delete(ctx, d.ID)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return createdID, nil
+}
+
+// create публикует черновик через CreateTopic, CreatePost или CreateComment.
+func (uc *draftUseCase) create(ctx context.Context, d *entity.Draft) (int64, error) {
+	var createdID int64
+	var err error
+	switch d.Kind {
+	case entity.DraftTopic:
+		createdID, _, err = uc.topics.CreateTopic(ctx,
+			&entity.Topic{
+				Title:          d.Title,
+				AuthorID:       d.AuthorID,
+				AuthorNickname: d.AuthorNickname,
+				CategoryID:     d.CategoryID,
+			},
+			&entity.Post{
+				Title:          d.Title,
+				Content:        d.Content,
+				AuthorID:       d.AuthorID,
+				AuthorNickname: d.AuthorNickname,
+			},
+		)
+	case entity.DraftPost:
+		post := &entity.Post{
+			TopicID:        d.TopicID,
+			AuthorID:       d.AuthorID,
+			AuthorNickname: d.AuthorNickname,
+			Title:          d.Title,
+			Content:        d.Content,
+			Images:         d.Images,
+			Tags:           make([]entity.Tag, len(d.TagIDs)),
+		}
+		for i, id := range d.TagIDs {
+			post.Tags[i] = entity.Tag{ID: id}
+		}
+		createdID, err = uc.posts.CreatePost(ctx, post)
+	case entity.DraftComment:
+		createdID, err = uc.comments.CreateComment(ctx, &entity.Comment{
+			PostID:         d.PostID,
+			AuthorID:       d.AuthorID,
+			AuthorNickname: d.AuthorNickname,
+			Content:        d.Content,
+		}, entity.Viewer{UserID: d.AuthorID})
+	default:
+		return 0, fmt.Errorf("%w: unknown kind %q", ErrInvalidDraft, d.Kind)
+	}
+	return createdID, err
+}
+
+// validate — проверки при сохранении: черновик может быть неполным, но не произвольным.
+func (uc *draftUseCase) validate(d *entity.Draft) error {
+	d.Title = strings.TrimSpace(d.Title)
+	switch {
+	case !d.Kind.Valid():
+		return fmt.Errorf("%w: kind must be topic, post or comment", ErrInvalidDraft)
+	case len([]rune(d.Title)) > uc.cfg.MaxTitleLength:
+		return fmt.Errorf("%w: title is longer than %d characters", ErrInvalidDraft, uc.cfg.MaxTitleLength)
+	case len([]rune(d.Content)) > uc.cfg.MaxContentLength:
+		return fmt.Errorf("%w: content is longer than %d characters", ErrInvalidDraft, uc.cfg.MaxContentLength)
+	case d.Kind == entity.DraftComment && (d.Title != "" || len(d.Images) > 0 || len(d.TagIDs) > 0):
+		return fmt.Errorf("%w: comments have no title, images or tags", ErrInvalidDraft)
+	case d.Kind == entity.DraftTopic && (len(d.Images) > 0 || len(d.TagIDs) > 0):
+		return fmt.Errorf("%w: images and tags are set on posts", ErrInvalidDraft)
+	}
+	return nil
+}
+
+// validatePublishable — минимум, без которого публиковать нечего; остальное проверят CreateTopic/CreatePost/CreateComment.
+func (uc *draftUseCase) validatePublishable(d *entity.Draft) error {
+	switch {
+	case d.ParentID() <= 0:
+		return fmt.Errorf("%w: %s draft has no place to publish to", ErrInvalidDraft, d.Kind)
+	case d.Kind == entity.DraftTopic && d.Title == "":
+		return fmt.Errorf("%w: topic title is required", ErrInvalidDraft)
+	case strings.TrimSpace(d.Content) == "":
+		return fmt.Errorf("%w: content is required", ErrInvalidDraft)
+	}
+	return nil
+}
@@ -3,14 +3,58 @@ package usecase
 import "errors"
 
 var (
-	ErrCategoryAlreadyExists = errors.New("category already exists")
-	ErrCategoryNotFound      = errors.New("category not found")
-	ErrTopicNotFound         = errors.New("topic not found")
-	ErrPostNotFound          = errors.New("post not found")
-	ErrCommentNotFound       = errors.New("comment not found")
-	ErrTagNotFound           = errors.New("tag not found")
-	ErrInvalidLimit          = errors.New("invalid limit")
-	ErrInvalidOffset         = errors.New("invalid offset")
-	ErrUpdateFailed          = errors.New("update failed")
-	ErrDeleteFailed          = errors.New("delete failed")
+	ErrCategoryAlreadyExists     = errors.New("category already exists")
+	ErrCategoryNotFound          = errors.New("category not found")
+	ErrTopicNotFound             = errors.New("topic not found")
+	ErrPostNotFound              = errors.New("post not found")
+	ErrCommentNotFound           = errors.New("comment not found")
+	ErrTagNotFound               = errors.New("tag not found")
+	ErrInvalidLimit              = errors.New("invalid limit")
+	ErrInvalidOffset             = errors.New("invalid offset")
+	ErrUpdateFailed              = errors.New("update failed")
+	ErrDeleteFailed              = errors.New("delete failed")
+	ErrTopicLocked               = errors.New("topic is locked")
+	ErrTopicNotLocked            = errors.New("topic is not locked")
+	ErrInvalidTopicMove          = errors.New("invalid topic move")
+	ErrInvalidSplit              = errors.New("invalid topic split")
+	ErrCategoryCycle             = errors.New("category cannot be moved into its own subtree")
+	ErrCategoryTooDeep           = errors.New("category nesting is too deep")
+	ErrCategoryHasChildren       = errors.New("category has subcategories")
+	ErrInvalidCategoryOrder      = errors.New("invalid category order")
+	ErrInvalidRankingPeriod      = errors.New("invalid ranking period")
+	ErrUnauthenticated           = errors.New("user is not authenticated")
+	ErrSubscriptionNotFound      = errors.New("subscription not found")
+	ErrInvalidSubscriptionTarget = errors.New("invalid subscription target")
+	ErrWebhookNotFound           = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound   = errors.New("webhook delivery not found")
+	ErrInvalidWebhook            = errors.New("invalid webhook")
+	ErrWebhookDead               = errors.New("webhook is in dead-letter state")
+	ErrInvalidReport             = errors.New("invalid report")
+	ErrAlreadyReported           = errors.New("content already reported by this user")
+	ErrCaseNotFound              = errors.New("moderation case not found")
+	ErrCaseClaimed               = errors.New("moderation case is claimed by another moderator")
+	ErrCaseResolved              = errors.New("moderation case is already resolved")
+	ErrUserBanned                = errors.New("user is banned")
+	ErrUserMuted                 = errors.New("user is muted")
+	ErrInvalidSanction           = errors.New("invalid sanction")
+	ErrSanctionNotFound          = errors.New("sanction not found")
+	ErrSanctionInactive          = errors.New("sanction is already lifted or expired")
+	ErrRateLimited               = errors.New("rate limit exceeded")
+	ErrNotPending                = errors.New("content is not awaiting approval")
+	ErrInsufficientTrust         = errors.New("trust level is too low")
+	ErrInvalidTrustLevel         = errors.New("invalid trust level")
+	ErrTrustOverrideNotFound     = errors.New("trust level override not found")
+	ErrForbidden                 = errors.New("action is not allowed")
+	ErrInvalidAnswer             = errors.New("invalid accepted answer")
+	ErrRevisionNotFound          = errors.New("revision not found")
+	ErrInvalidRevision           = errors.New("invalid revision request")
+	ErrVersionMismatch           = errors.New("entity was modified by someone else")
+	ErrInvalidIdempotencyKey     = errors.New("invalid idempotency key")
+	ErrIdempotencyKeyReused      = errors.New("idempotency key was already used for a different request")
+	ErrIdempotencyInProgress     = errors.New("request with this idempotency key is still in progress")
+	ErrDraftNotFound             = errors.New("draft not found")
+	ErrInvalidDraft              = errors.New("invalid draft")
+	ErrTooManyDrafts             = errors.New("too many drafts")
+	ErrDraftPublishing           = errors.New("draft is already being published")
+	ErrSpamSuspected             = errors.New("content looks like spam")
 )
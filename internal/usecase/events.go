@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+// newEvent собирает событие для outbox. В fields — только строки, числа и время,
+// поэтому json.Marshal здесь не падает.
+func newEvent(eventType entity.EventType, aggregateType string, aggregateID int64, fields map[string]any) *entity.Event {
+	payload, _ := json.Marshal(fields)
+	return &entity.Event{
+		Type:          eventType,
+		Version:       entity.EventVersion,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       payload,
+		OccurredAt:    time.Now().UTC(),
+	}
+}
+
+func topicEvent(eventType entity.EventType, t *entity.Topic) *entity.Event {
+	return newEvent(eventType, "topic", t.ID, topicFields(t))
+}
+
+// topicEventWith — событие топика с дополнительными полями о действии модератора.
+func topicEventWith(eventType entity.EventType, t *entity.Topic, extra map[string]any) *entity.Event {
+	fields := topicFields(t)
+	for k, v := range extra {
+		fields[k] = v
+	}
+	return newEvent(eventType, "topic", t.ID, fields)
+}
+
+func postEvent(eventType entity.EventType, p *entity.Post) *entity.Event {
+	return newEvent(eventType, "post", p.ID, postFields(p))
+}
+
+func commentEvent(eventType entity.EventType, c *entity.Comment, topicID int64) *entity.Event {
+	return newEvent(eventType, "comment", c.ID, commentFields(c, topicID))
+}
+
+// *Fields — общее представление объектов для payload событий и снимков журнала аудита.
+
+func topicFields(t *entity.Topic) map[string]any {
+	return map[string]any{
+		"id":               t.ID,
+		"title":            t.Title,
+		"author_id":        t.AuthorID,
+		"author_nickname":  t.AuthorNickname,
+		"category_id":      t.CategoryID,
+		"accepted_post_id": t.AcceptedPostID,
+		"version":          t.Version,
+		"created_at":       t.CreatedAt,
+	}
+}
+
+func postFields(p *entity.Post) map[string]any {
+	tagIDs := make([]int64, len(p.Tags))
+	for i, tag := range p.Tags {
+		tagIDs[i] = tag.ID
+	}
+	return map[string]any{
+		"id":              p.ID,
+		"topic_id":        p.TopicID,
+		"title":           p.Title,
+		"content":         p.Content,
+		"author_id":       p.AuthorID,
+		"author_nickname": p.AuthorNickname,
+		"tag_ids":         tagIDs,
+		"wiki":            p.Wiki,
+		"version":         p.Version,
+		"created_at":      p.CreatedAt,
+	}
+}
+
+func commentFields(c *entity.Comment, topicID int64) map[string]any {
+	return map[string]any{
+		"id":              c.ID,
+		"post_id":         c.PostID,
+		"topic_id":        topicID,
+		"content":         c.Content,
+		"author_id":       c.AuthorID,
+		"author_nickname": c.AuthorNickname,
+		"created_at":      c.CreatedAt,
+		"updated_at":      c.UpdatedAt,
+		"edit_count":      c.EditCount,
+		"version":         c.Version,
+	}
+}
+
+func categoryFields(c *entity.Category) map[string]any {
+	return map[string]any{
+		"id":           c.ID,
+		"parent_id":    c.ParentID,
+		"title":        c.Title,
+		"slug":         c.Slug,
+		"description":  c.Description,
+		"position":     c.Position,
+		"premoderated": c.Premoderated,
+		"version":      c.Version,
+	}
+}
+
+func tagFields(t *entity.Tag) map[string]any {
+	return map[string]any{
+		"id":   t.ID,
+		"name": t.Name,
+		"slug": t.Slug,
+	}
+}
+
+func tagEvent(t *entity.Tag) *entity.Event {
+	return newEvent(entity.EventTagCreated, "tag", t.ID, tagFields(t))
+}
+
+func postTagEvent(eventType entity.EventType, postID, tagID int64) *entity.Event {
+	return newEvent(eventType, "post", postID, map[string]any{
+		"post_id": postID,
+		"tag_id":  tagID,
+	})
+}
+
+// deletedEvent — для удалений достаточно id и ссылок на родителей.
+func deletedEvent(eventType entity.EventType, aggregateType string, id int64, parents map[string]any) *entity.Event {
+	fields := map[string]any{"id": id}
+	for k, v := range parents {
+		fields[k] = v
+	}
+	return newEvent(eventType, aggregateType, id, fields)
+}
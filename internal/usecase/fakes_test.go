@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+// Фейки реализуют только то, до чего доходят тесты; остальные методы паникуют.
+type fakePostRepo struct {
+	repository.PostRepository
+	post *entity.Post
+}
+
+func (r *fakePostRepo) GetByID(context.Context, int64) (*entity.Post, error) {
+	p := *r.post
+	return &p, nil
+}
+
+type fakeCommentRepo struct {
+	repository.CommentRepository
+	comment *entity.Comment
+}
+
+func (r *fakeCommentRepo) GetByID(context.Context, int64) (*entity.Comment, error) {
+	c := *r.comment
+	return &c, nil
+}
+
+type fakeTopicRepo struct {
+	repository.TopicRepository
+	topic *entity.Topic
+}
+
+func (r *fakeTopicRepo) GetByID(context.Context, int64) (*entity.Topic, error) {
+	t := *r.topic
+	return &t, nil
+}
+
+type fakeTrust struct {
+	TrustUseCase
+	allowed bool
+}
+
+func (t *fakeTrust) Require(context.Context, int64, entity.Privilege) error {
+	if t.allowed {
+		return nil
+	}
+	return ErrInsufficientTrust
+}
+
+// fakeGuard отвечает одной и той же ошибкой на любую проверку.
+type fakeGuard struct {
+	err error
+}
+
+func (g *fakeGuard) CheckCanPost(context.Context, int64, int64) error { return g.err }
+
+func (g *fakeGuard) CheckCanEdit(context.Context, int64, int64) error { return g.err }
+
+// fakeTx не открывает транзакцию и сразу возвращает err: тест останавливается перед записью.
+type fakeTx struct {
+	err error
+}
+
+func (t *fakeTx) WithinTx(context.Context, func(ctx context.Context) error) error { return t.err }
+
+func (t *fakeTx) AfterCommit(ctx context.Context, fn func(ctx context.Context)) { fn(ctx) }
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+type IdempotencyConfig struct {
+	TTL time.Duration // сколько повтор с тем же ключом возвращает сохранённый ответ
+	// StaleAfter — через сколько незавершённый запрос считается брошенным (реплика упала) и ключ можно занять снова
+	StaleAfter   time.Duration
+	MaxKeyLength int
+}
+
+func DefaultIdempotencyConfig() IdempotencyConfig {
+	return IdempotencyConfig{
+		TTL:          24 * time.Hour,
+		StaleAfter:   time.Minute,
+		MaxKeyLength: 255,
+	}
+}
+
+type IdempotencyUseCase interface {
+	// Do выполняет create один раз на ключ пользователя. Повтор с тем же method и request в пределах TTL
+	// получает сохранённый ответ без вызова create (replayed = true); тот же ключ с другим запросом — ErrIdempotencyKeyReused.
+	// Если create вернул ошибку, ключ освобождается: ошибки не кэшируются.
+	Do(ctx context.Context, userID int64, key, method string, request []byte, create func() ([]byte, error)) (response []byte, replayed bool, err error)
+	// Cleanup удаляет истёкшие ключи.
+	Cleanup(ctx context.Context) (int64, error)
+}
+
+type idempotencyUseCase struct {
+	repo   repository.IdempotencyRepository
+	cfg    IdempotencyConfig
+	logger *slog.Logger
+}
+
+func NewIdempotencyUseCase(repo repository.IdempotencyRepository, cfg IdempotencyConfig, logger *slog.Logger) IdempotencyUseCase {
+	return &idempotencyUseCase{
+		repo:   repo,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (uc *idempotencyUseCase) Do(ctx context.Context, userID int64, key, method string, request []byte, create func() ([]byte, error)) ([]byte, bool, error) {
+	if userID <= 0 {
+		return nil, false, ErrUnauthenticated // ключи принадлежат пользователю, у анонимов их не различить
+	}
+	if len(key) > uc.cfg.MaxKeyLength {
+		return nil, false, fmt.Errorf("%w: key is longer than %d bytes", ErrInvalidIdempotencyKey, uc.cfg.MaxKeyLength)
+	}
+
+	hash := requestHash(method, request)
+	now := time.Now().UTC()
+	reserved, err := uc.repo.Reserve(ctx, &entity.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		Method:      method,
+		RequestHash: hash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(uc.cfg.TTL),
+	}, now.Add(-uc.cfg.StaleAfter))
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !reserved {
+		existing, err := uc.repo.Get(ctx, userID, key)
+		if errors.Is(err, repository.ErrNotFound) {
+			// Первый запрос упал и освободил ключ между Reserve и Get
+			return nil, false, ErrIdempotencyInProgress
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if !bytes.Equal(existing.RequestHash, hash) {
+			return nil, false, ErrIdempotencyKeyReused
+		}
+		if !existing.Completed() {
+			return nil, false, ErrIdempotencyInProgress
+		}
+		uc.logger.Info("idempotent request replayed", slog.Int64("userID", userID), slog.String("method", method))
+		return existing.Response, true, nil
+	}
+
+	response, err := create()
+	if err != nil {
+		// Отдельный контекст: ключ нужно освободить, даже если клиент уже отключился
+		if relErr := uc.repo.Release(context.WithoutCancel(ctx), userID, key); relErr != nil {
+			uc.logger.Error("failed to release idempotency key", slog.Int64("userID", userID), slog.String("err", relErr.Error()))
+		}
+		return nil, false, err
+	}
+	if err := uc.repo.Complete(context.WithoutCancel(ctx), userID, key, response); err != nil {
+		// Создание уже прошло — отдаём ответ, повтор в худшем случае создаст дубль, как без ключа
+		uc.logger.Error("failed to store idempotent response", slog.Int64("userID", userID), slog.String("err", err.Error()))
+	}
+	return response, false, nil
+}
+
+func (uc *idempotencyUseCase) Cleanup(ctx context.Context) (int64, error) {
+	n, err := uc.repo.Cleanup(ctx, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 {
+		uc.logger.Info("expired idempotency keys removed", slog.Int64("count", n))
+	}
+	return n, nil
+}
+
+// requestHash — отпечаток запроса; метод входит в него, чтобы ключ нельзя было переиспользовать в другом RPC.
+func requestHash(method string, request []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write(request)
+	return h.Sum(nil)
+}
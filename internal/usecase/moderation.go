@@ -0,0 +1,596 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+type ModerationConfig struct {
+	AutoHideThreshold int           // такой суммарный вес жалоб скрывает контент до решения модератора; 0 — не скрывать
+	ClaimTTL          time.Duration // после этого захваченный кейс может взять другой модератор
+}
+
+func DefaultModerationConfig() ModerationConfig {
+	return ModerationConfig{
+		AutoHideThreshold: 5,
+		ClaimTTL:          30 * time.Minute,
+	}
+}
+
+// maxReportComment — ограничение на свободный текст жалобы, в символах.
+const maxReportComment = 1000
+
+type ModerationUseCase interface {
+	Report(ctx context.Context, reporterID int64, targetType entity.ContentType, targetID int64, reason entity.ReportReason, comment string) (*entity.ModerationCase, error)
+	ListQueue(ctx context.Context, filter repository.CaseFilter, limit, offset int) ([]*entity.ModerationCase, int64, error)
+	GetCase(ctx context.Context, id int64) (*entity.ModerationCase, []*entity.Report, error)
+	ClaimCase(ctx context.Context, id, moderatorID int64) (*entity.ModerationCase, error)
+	// ResolveCase применяет action к объекту жалобы и закрывает кейс.
+	ResolveCase(ctx context.Context, id, moderatorID int64, action entity.ModerationAction, note string) (*entity.ModerationCase, error)
+	// Approve и Reject разбирают контент, ждущий одобрения (премодерация или антиспам):
+	// закрывают его кейс действием dismiss или reject. Для Reject причина обязательна, она уходит автору.
+	Approve(ctx context.Context, targetType entity.ContentType, targetID, moderatorID int64, note string) (*entity.ModerationCase, error)
+	Reject(ctx context.Context, targetType entity.ContentType, targetID, moderatorID int64, reason string) (*entity.ModerationCase, error)
+}
+
+type moderationUseCase struct {
+	moderationRepo   repository.ModerationRepository
+	topicRepo        repository.TopicRepository
+	postRepo         repository.PostRepository
+	commentRepo      repository.CommentRepository
+	notificationRepo repository.NotificationRepository
+	auditRepo        repository.AuditRepository
+	outboxRepo       repository.OutboxRepository
+	tx               repository.Transactor
+	spam             SpamUseCase
+	trust            TrustUseCase
+	notifier         Notifier
+	cfg              ModerationConfig
+	logger           *slog.Logger
+}
+
+func NewModerationUseCase(
+	moderationRepo repository.ModerationRepository,
+	topicRepo repository.TopicRepository,
+	postRepo repository.PostRepository,
+	commentRepo repository.CommentRepository,
+	notificationRepo repository.NotificationRepository,
+	auditRepo repository.AuditRepository,
+	outboxRepo repository.OutboxRepository,
+	tx repository.Transactor,
+	spam SpamUseCase,
+	trust TrustUseCase,
+	notifier Notifier,
+	cfg ModerationConfig,
+	logger *slog.Logger,
+) ModerationUseCase {
+	return &moderationUseCase{
+		moderationRepo:   moderationRepo,
+		topicRepo:        topicRepo,
+		postRepo:         postRepo,
+		commentRepo:      commentRepo,
+		notificationRepo: notificationRepo,
+		auditRepo:        auditRepo,
+		outboxRepo:       outboxRepo,
+		tx:               tx,
+		spam:             spam,
+		trust:            trust,
+		notifier:         notifier,
+		cfg:              cfg,
+		logger:           logger,
+	}
+}
+
+func (uc *moderationUseCase) Report(ctx context.Context, reporterID int64, targetType entity.ContentType, targetID int64, reason entity.ReportReason, comment string) (*entity.ModerationCase, error) {
+	if reporterID <= 0 {
+		return nil, ErrUnauthenticated
+	}
+	comment = strings.TrimSpace(comment)
+	if err := validateReport(reason, comment); err != nil {
+		return nil, err
+	}
+
+	ref, err := uc.contentInfo(ctx, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	// Скрытое и удалённое пользователь видеть не должен, жаловаться на него тоже не на что
+	if ref.Status != entity.StatusActive {
+		return nil, notFoundError(targetType)
+	}
+	if ref.AuthorID == reporterID {
+		return nil, fmt.Errorf("%w: cannot report your own content", ErrInvalidReport)
+	}
+
+	report := &entity.Report{
+		TargetType: targetType,
+		TargetID:   targetID,
+		ReporterID: reporterID,
+		Reason:     reason,
+		Comment:    comment,
+		Weight:     uc.trust.FlagWeight(ctx, reporterID),
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	var (
+		c      *entity.ModerationCase
+		hidden bool
+	)
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		c, err = uc.moderationRepo.AddReport(ctx, ref, report)
+		if err != nil {
+			return err
+		}
+		if uc.cfg.AutoHideThreshold <= 0 || c.ReportWeight < uc.cfg.AutoHideThreshold || c.AutoHidden {
+			return nil
+		}
+		if err := uc.moderationRepo.SetContentStatus(ctx, targetType, targetID, entity.StatusHidden); err != nil {
+			return err
+		}
+		c.AutoHidden, hidden = true, true
+		return uc.moderationRepo.MarkAutoHidden(ctx, c.ID)
+	})
+	if errors.Is(err, repository.ErrAlreadyExists) {
+		return nil, ErrAlreadyReported
+	}
+	if err != nil {
+		uc.logger.Error("failed to add report",
+			slog.String("targetType", string(targetType)),
+			slog.Int64("targetID", targetID),
+			slog.String("err", err.Error()),
+		)
+		return nil, err
+	}
+
+	if hidden {
+		uc.logger.Info("content auto-hidden by reports",
+			slog.String("targetType", string(targetType)),
+			slog.Int64("targetID", targetID),
+			slog.Int("reports", c.ReportsCount),
+			slog.Int("weight", c.ReportWeight),
+		)
+	}
+	return c, nil
+}
+
+func (uc *moderationUseCase) ListQueue(ctx context.Context, filter repository.CaseFilter, limit, offset int) ([]*entity.ModerationCase, int64, error) {
+	switch filter.Status {
+	case "", entity.CaseOpen, entity.CaseClaimed, entity.CaseResolved:
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown case status %q", ErrInvalidReport, filter.Status)
+	}
+	if filter.Reason != "" && filter.Reason != entity.ReasonPremoderation && !validReportReason(filter.Reason) {
+		return nil, 0, fmt.Errorf("%w: unknown reason %q", ErrInvalidReport, filter.Reason)
+	}
+	if filter.TargetType != "" && !validContentType(filter.TargetType) {
+		return nil, 0, fmt.Errorf("%w: unknown content type %q", ErrInvalidReport, filter.TargetType)
+	}
+	limit, offset = normalizePage(limit, offset)
+	return uc.moderationRepo.ListCases(ctx, filter, limit, offset)
+}
+
+func (uc *moderationUseCase) GetCase(ctx context.Context, id int64) (*entity.ModerationCase, []*entity.Report, error) {
+	c, err := uc.getCase(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	reports, err := uc.moderationRepo.ListReports(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, reports, nil
+}
+
+func (uc *moderationUseCase) ClaimCase(ctx context.Context, id, moderatorID int64) (*entity.ModerationCase, error) {
+	if moderatorID <= 0 {
+		return nil, ErrUnauthenticated
+	}
+	c, err := uc.getCase(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Status == entity.CaseResolved {
+		return nil, ErrCaseResolved
+	}
+
+	c, err = uc.moderationRepo.ClaimCase(ctx, id, moderatorID, uc.cfg.ClaimTTL, time.Now().UTC())
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return nil, ErrCaseNotFound
+	case errors.Is(err, repository.ErrConflict):
+		return nil, ErrCaseClaimed
+	}
+	return c, err
+}
+
+func (uc *moderationUseCase) ResolveCase(ctx context.Context, id, moderatorID int64, action entity.ModerationAction, note string) (*entity.ModerationCase, error) {
+	if moderatorID <= 0 {
+		return nil, ErrUnauthenticated
+	}
+	switch action {
+	case entity.ActionDismiss, entity.ActionHide, entity.ActionDelete, entity.ActionWarn, entity.ActionReject:
+	default:
+		return nil, fmt.Errorf("%w: unknown action %q", ErrInvalidReport, action)
+	}
+	note = strings.TrimSpace(note)
+	if action == entity.ActionWarn && note == "" {
+		return nil, fmt.Errorf("%w: warning text is required", ErrInvalidReport)
+	}
+	if action == entity.ActionReject && note == "" {
+		return nil, fmt.Errorf("%w: rejection reason is required", ErrInvalidReport)
+	}
+
+	c, err := uc.getCase(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Status == entity.CaseResolved {
+		return nil, ErrCaseResolved
+	}
+
+	// Текст читаем до транзакции: после delete его уже не будет
+	sample, train := uc.spamSample(ctx, c, action)
+
+	now := time.Now().UTC()
+	c.ResolvedBy = moderatorID
+	c.ResolvedAt = &now
+	c.Resolution = action
+	c.ResolutionNote = note
+
+	var published *publishedContent
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Сначала закрываем кейс: если его параллельно взял другой модератор, действие не применится
+		if err := uc.moderationRepo.ResolveCase(ctx, c); err != nil {
+			return err
+		}
+		var err error
+		if published, err = uc.apply(ctx, c, note); err != nil {
+			return err
+		}
+		return uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    moderatorID,
+			Action:     entity.AuditActionCaseResolve,
+			TargetType: entity.AuditTargetCase,
+			TargetID:   c.ID,
+			Reason:     note,
+			Details: map[string]any{
+				"action":        action,
+				"target_type":   c.TargetType,
+				"target_id":     c.TargetID,
+				"author_id":     c.AuthorID,
+				"reports_count": c.ReportsCount,
+			},
+			CreatedAt: now,
+		})
+	})
+	if errors.Is(err, repository.ErrConflict) {
+		return nil, ErrCaseClaimed
+	}
+	if err != nil {
+		uc.logger.Error("failed to resolve moderation case",
+			slog.Int64("caseID", id),
+			slog.String("action", string(action)),
+			slog.String("err", err.Error()),
+		)
+		return nil, err
+	}
+
+	uc.logger.Info("moderation case resolved",
+		slog.Int64("caseID", id),
+		slog.Int64("moderatorID", moderatorID),
+		slog.String("action", string(action)),
+	)
+
+	if published != nil {
+		uc.notifyPublished(ctx, published)
+	}
+	if train {
+		if err := uc.spam.Train(ctx, sample, action != entity.ActionDismiss); err != nil {
+			uc.logger.Warn("failed to train spam classifier", slog.Int64("caseID", id), slog.String("err", err.Error()))
+		}
+	}
+	return c, nil
+}
+
+func (uc *moderationUseCase) Approve(ctx context.Context, targetType entity.ContentType, targetID, moderatorID int64, note string) (*entity.ModerationCase, error) {
+	return uc.resolvePending(ctx, targetType, targetID, moderatorID, entity.ActionDismiss, note)
+}
+
+func (uc *moderationUseCase) Reject(ctx context.Context, targetType entity.ContentType, targetID, moderatorID int64, reason string) (*entity.ModerationCase, error) {
+	return uc.resolvePending(ctx, targetType, targetID, moderatorID, entity.ActionReject, reason)
+}
+
+func (uc *moderationUseCase) resolvePending(ctx context.Context, targetType entity.ContentType, targetID, moderatorID int64, action entity.ModerationAction, note string) (*entity.ModerationCase, error) {
+	ref, err := uc.contentInfo(ctx, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Status != entity.StatusPending {
+		return nil, ErrNotPending
+	}
+	c, err := uc.moderationRepo.OpenCase(ctx, targetType, targetID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrCaseNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return uc.ResolveCase(ctx, c.ID, moderatorID, action, note)
+}
+
+// publishedContent — одобренный модератором контент, о котором ещё не разосланы уведомления.
+type publishedContent struct {
+	kind    entity.ContentType
+	topic   *entity.Topic
+	post    *entity.Post
+	comment *entity.Comment
+}
+
+// spamSample возвращает текст для обучения классификатора, если кейс открыт жалобой на спам
+// и модератор его подтвердил (hide, delete) или отклонил (dismiss).
+func (uc *moderationUseCase) spamSample(ctx context.Context, c *entity.ModerationCase, action entity.ModerationAction) (string, bool) {
+	if action == entity.ActionWarn {
+		return "", false
+	}
+	reports, err := uc.moderationRepo.ListReports(ctx, c.ID)
+	if err != nil {
+		uc.logger.Warn("failed to load case reports", slog.Int64("caseID", c.ID), slog.String("err", err.Error()))
+		return "", false
+	}
+	if !slices.ContainsFunc(reports, func(r *entity.Report) bool { return r.Reason == entity.ReasonSpam }) {
+		return "", false
+	}
+
+	if c.TargetType == entity.ContentTopic {
+		topic, post, err := uc.topicRepo.GetByIDWithFirstPost(ctx, c.TargetID)
+		if err != nil {
+			return "", false
+		}
+		return topic.Title + "\n" + post.Content, true
+	}
+	if c.TargetType == entity.ContentPost {
+		post, err := uc.postRepo.GetByID(ctx, c.TargetID)
+		if err != nil {
+			return "", false
+		}
+		return post.Title + "\n" + post.Content, true
+	}
+	comment, err := uc.commentRepo.GetByID(ctx, c.TargetID)
+	if err != nil {
+		return "", false
+	}
+	return comment.Content, true
+}
+
+// publish выпускает одобренный контент: событие о создании уходит только сейчас.
+func (uc *moderationUseCase) publish(ctx context.Context, ref *entity.ContentRef) (*publishedContent, error) {
+	if ref.Type == entity.ContentTopic {
+		topic, post, err := uc.topicRepo.GetByIDWithFirstPost(ctx, ref.ID)
+		if err != nil {
+			return nil, err
+		}
+		topic.Status, post.Status = entity.StatusActive, entity.StatusActive
+		return &publishedContent{kind: ref.Type, topic: topic, post: post}, uc.outboxRepo.Add(ctx,
+			topicEvent(entity.EventTopicCreated, topic),
+			postEvent(entity.EventPostCreated, post),
+		)
+	}
+
+	topic, err := uc.topicRepo.GetByID(ctx, ref.TopicID)
+	if err != nil {
+		return nil, err
+	}
+	post, err := uc.postRepo.GetByID(ctx, ref.PostID)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Type == entity.ContentPost {
+		post.Status = entity.StatusActive
+		return &publishedContent{kind: ref.Type, topic: topic, post: post}, uc.outboxRepo.Add(ctx, postEvent(entity.EventPostCreated, post))
+	}
+
+	comment, err := uc.commentRepo.GetByID(ctx, ref.ID)
+	if err != nil {
+		return nil, err
+	}
+	comment.Status = entity.StatusActive
+	return &publishedContent{kind: ref.Type, topic: topic, post: post, comment: comment},
+		uc.outboxRepo.Add(ctx, commentEvent(entity.EventCommentCreated, comment, ref.TopicID))
+}
+
+func (uc *moderationUseCase) notifyPublished(ctx context.Context, p *publishedContent) {
+	switch p.kind {
+	case entity.ContentTopic:
+		uc.notifier.TopicCreated(ctx, p.topic, p.post)
+	case entity.ContentPost:
+		uc.notifier.PostCreated(ctx, p.topic, p.post)
+	default:
+		uc.notifier.CommentCreated(ctx, p.post, p.comment)
+	}
+}
+
+// setStatus меняет статус объекта; у неопубликованного топика вместе с ним меняется и первый пост,
+// а у поста, с которого начинается ожидающий топик (выделенный SplitTopic), — сам топик.
+func (uc *moderationUseCase) setStatus(ctx context.Context, ref *entity.ContentRef, status entity.Status) error {
+	if err := uc.moderationRepo.SetContentStatus(ctx, ref.Type, ref.ID, status); err != nil {
+		return err
+	}
+	switch {
+	case ref.Type == entity.ContentTopic && ref.Status == entity.StatusPending:
+		return uc.moderationRepo.SetPendingPostsStatus(ctx, ref.ID, status)
+	case ref.Type == entity.ContentPost:
+		// ref.TopicID мог устареть: пост уже перенесли в новый топик
+		post, err := uc.postRepo.GetByID(ctx, ref.ID)
+		if err != nil {
+			return err
+		}
+		topic, first, err := uc.topicRepo.GetByIDWithFirstPost(ctx, post.TopicID)
+		if err != nil {
+			return err
+		}
+		if topic.Status == entity.StatusPending && first.ID == post.ID {
+			return uc.moderationRepo.SetContentStatus(ctx, entity.ContentTopic, topic.ID, status)
+		}
+	}
+	return nil
+}
+
+// apply возвращает контент, одобренный из антиспама: о нём нужно разослать уведомления после коммита.
+func (uc *moderationUseCase) apply(ctx context.Context, c *entity.ModerationCase, note string) (*publishedContent, error) {
+	if c.Resolution == entity.ActionDismiss {
+		if !c.AutoHidden {
+			return nil, nil
+		}
+		ref, err := uc.moderationRepo.ContentInfo(ctx, c.TargetType, c.TargetID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		// Жалобы не подтвердились — возвращаем скрытый по порогу или публикуем задержанный контент
+		if err := uc.setStatus(ctx, ref, entity.StatusActive); err != nil {
+			return nil, err
+		}
+		if ref.Status != entity.StatusPending {
+			return nil, nil
+		}
+		return uc.publish(ctx, ref)
+	}
+
+	ref, err := uc.contentInfo(ctx, c.TargetType, c.TargetID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch c.Resolution {
+	case entity.ActionHide:
+		// Неопубликованное не скрывается, а отклоняется: иначе оно попадёт в счётчики
+		if ref.Status == entity.StatusPending {
+			return nil, uc.setStatus(ctx, ref, entity.StatusRejected)
+		}
+		return nil, uc.setStatus(ctx, ref, entity.StatusHidden)
+	case entity.ActionReject:
+		if ref.Status != entity.StatusPending {
+			return nil, ErrNotPending
+		}
+		if err := uc.setStatus(ctx, ref, entity.StatusRejected); err != nil {
+			return nil, err
+		}
+		return nil, uc.notifyAuthor(ctx, c, ref, entity.NotificationRejected, note)
+	case entity.ActionDelete:
+		return nil, uc.deleteContent(ctx, ref)
+	case entity.ActionWarn:
+		return nil, uc.notifyAuthor(ctx, c, ref, entity.NotificationWarning, note)
+	}
+	return nil, nil
+}
+
+func (uc *moderationUseCase) notifyAuthor(ctx context.Context, c *entity.ModerationCase, ref *entity.ContentRef, kind entity.NotificationKind, text string) error {
+	n := &entity.Notification{
+		UserID:    ref.AuthorID,
+		Kind:      kind,
+		ActorID:   c.ResolvedBy,
+		TopicID:   ref.TopicID,
+		PostID:    ref.PostID,
+		Text:      text,
+		CreatedAt: *c.ResolvedAt,
+	}
+	if c.TargetType == entity.ContentComment {
+		n.CommentID = ref.ID
+	}
+	return uc.notificationRepo.Create(ctx, n)
+}
+
+// deleteContent удаляет объект так же, как Delete* соответствующего юзкейса, вместе с событием в outbox.
+func (uc *moderationUseCase) deleteContent(ctx context.Context, ref *entity.ContentRef) error {
+	switch ref.Type {
+	case entity.ContentTopic:
+		if err := uc.topicRepo.Delete(ctx, ref.ID); err != nil {
+			return err
+		}
+		return uc.outboxRepo.Add(ctx, deletedEvent(entity.EventTopicDeleted, "topic", ref.ID, map[string]any{"category_id": ref.CategoryID}))
+	case entity.ContentPost:
+		if err := uc.postRepo.Delete(ctx, ref.ID); err != nil {
+			return err
+		}
+		return uc.outboxRepo.Add(ctx, deletedEvent(entity.EventPostDeleted, "post", ref.ID, map[string]any{"topic_id": ref.TopicID}))
+	default:
+		if err := uc.commentRepo.Delete(ctx, ref.ID); err != nil {
+			return err
+		}
+		return uc.outboxRepo.Add(ctx, deletedEvent(entity.EventCommentDeleted, "comment", ref.ID, map[string]any{
+			"post_id":  ref.PostID,
+			"topic_id": ref.TopicID,
+		}))
+	}
+}
+
+func (uc *moderationUseCase) getCase(ctx context.Context, id int64) (*entity.ModerationCase, error) {
+	c, err := uc.moderationRepo.GetCase(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrCaseNotFound
+	}
+	return c, err
+}
+
+func (uc *moderationUseCase) contentInfo(ctx context.Context, targetType entity.ContentType, targetID int64) (*entity.ContentRef, error) {
+	if !validContentType(targetType) {
+		return nil, fmt.Errorf("%w: unknown content type %q", ErrInvalidReport, targetType)
+	}
+	ref, err := uc.moderationRepo.ContentInfo(ctx, targetType, targetID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFoundError(targetType)
+	}
+	return ref, err
+}
+
+func validateReport(reason entity.ReportReason, comment string) error {
+	if !validReportReason(reason) {
+		return fmt.Errorf("%w: unknown reason %q", ErrInvalidReport, reason)
+	}
+	if reason == entity.ReasonOther && comment == "" {
+		return fmt.Errorf("%w: comment is required for reason %q", ErrInvalidReport, reason)
+	}
+	if utf8.RuneCountInString(comment) > maxReportComment {
+		return fmt.Errorf("%w: comment is longer than %d characters", ErrInvalidReport, maxReportComment)
+	}
+	return nil
+}
+
+func validReportReason(reason entity.ReportReason) bool {
+	switch reason {
+	case entity.ReasonSpam, entity.ReasonAbuse, entity.ReasonOffTopic, entity.ReasonIllegal, entity.ReasonOther:
+		return true
+	}
+	return false
+}
+
+func validContentType(t entity.ContentType) bool {
+	switch t {
+	case entity.ContentTopic, entity.ContentPost, entity.ContentComment:
+		return true
+	}
+	return false
+}
+
+func notFoundError(t entity.ContentType) error {
+	switch t {
+	case entity.ContentTopic:
+		return ErrTopicNotFound
+	case entity.ContentPost:
+		return ErrPostNotFound
+	default:
+		return ErrCommentNotFound
+	}
+}
@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+type NotificationUseCase interface {
+	Notifier
+	List(ctx context.Context, userID int64, unreadOnly bool, limit, offset int) ([]*entity.Notification, int64, error)
+	// MarkRead отмечает прочитанными уведомления из ids, а при пустом ids — все.
+	MarkRead(ctx context.Context, userID int64, ids []int64) (int64, error)
+	UnreadCount(ctx context.Context, userID int64) (int64, error)
+}
+
+// Notifier вызывается юзкейсами после успешной записи: подписывает автора и раскладывает
+// уведомления подписчикам. Ошибки только логируются — запись контента от них не откатывается.
+type Notifier interface {
+	TopicCreated(ctx context.Context, topic *entity.Topic, post *entity.Post)
+	PostCreated(ctx context.Context, topic *entity.Topic, post *entity.Post)
+	CommentCreated(ctx context.Context, post *entity.Post, comment *entity.Comment)
+}
+
+type notificationUseCase struct {
+	notificationRepo repository.NotificationRepository
+	subscriptionRepo repository.SubscriptionRepository
+	topicRepo        repository.TopicRepository
+	categoryRepo     repository.CategoryRepository
+	logger           *slog.Logger
+}
+
+func NewNotificationUseCase(
+	notificationRepo repository.NotificationRepository,
+	subscriptionRepo repository.SubscriptionRepository,
+	topicRepo repository.TopicRepository,
+	categoryRepo repository.CategoryRepository,
+	logger *slog.Logger,
+) NotificationUseCase {
+	return &notificationUseCase{
+		notificationRepo: notificationRepo,
+		subscriptionRepo: subscriptionRepo,
+		topicRepo:        topicRepo,
+		categoryRepo:     categoryRepo,
+		logger:           logger,
+	}
+}
+
+func (uc *notificationUseCase) List(ctx context.Context, userID int64, unreadOnly bool, limit, offset int) ([]*entity.Notification, int64, error) {
+	if userID <= 0 {
+		return nil, 0, ErrUnauthenticated
+	}
+	limit, offset = normalizePage(limit, offset)
+	return uc.notificationRepo.ListByUser(ctx, userID, unreadOnly, limit, offset)
+}
+
+func (uc *notificationUseCase) MarkRead(ctx context.Context, userID int64, ids []int64) (int64, error) {
+	if userID <= 0 {
+		return 0, ErrUnauthenticated
+	}
+	return uc.notificationRepo.MarkRead(ctx, userID, ids, time.Now().UTC())
+}
+
+func (uc *notificationUseCase) UnreadCount(ctx context.Context, userID int64) (int64, error) {
+	if userID <= 0 {
+		return 0, ErrUnauthenticated
+	}
+	return uc.notificationRepo.UnreadCount(ctx, userID)
+}
+
+func (uc *notificationUseCase) TopicCreated(ctx context.Context, topic *entity.Topic, post *entity.Post) {
+	uc.autoSubscribe(ctx, topic.AuthorID, topic.ID)
+	uc.fanOut(ctx, topic.CategoryID, &entity.Notification{
+		Kind:          entity.NotificationNewTopic,
+		ActorID:       topic.AuthorID,
+		ActorNickname: topic.AuthorNickname,
+		TopicID:       topic.ID,
+		PostID:        post.ID,
+		CreatedAt:     post.CreatedAt,
+	})
+}
+
+func (uc *notificationUseCase) PostCreated(ctx context.Context, topic *entity.Topic, post *entity.Post) {
+	uc.autoSubscribe(ctx, post.AuthorID, topic.ID)
+	uc.fanOut(ctx, topic.CategoryID, &entity.Notification{
+		Kind:          entity.NotificationNewPost,
+		ActorID:       post.AuthorID,
+		ActorNickname: post.AuthorNickname,
+		TopicID:       topic.ID,
+		PostID:        post.ID,
+		CreatedAt:     post.CreatedAt,
+	})
+}
+
+func (uc *notificationUseCase) CommentCreated(ctx context.Context, post *entity.Post, comment *entity.Comment) {
+	topic, err := uc.topicRepo.GetByID(ctx, post.TopicID)
+	if err != nil {
+		uc.logger.Warn("failed to load topic for notifications",
+			slog.Int64("topic_id", post.TopicID),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	uc.autoSubscribe(ctx, comment.AuthorID, topic.ID)
+	uc.fanOut(ctx, topic.CategoryID, &entity.Notification{
+		Kind:          entity.NotificationNewComment,
+		ActorID:       comment.AuthorID,
+		ActorNickname: comment.AuthorNickname,
+		TopicID:       topic.ID,
+		PostID:        post.ID,
+		CommentID:     comment.ID,
+		CreatedAt:     comment.CreatedAt,
+	})
+}
+
+// autoSubscribe подписывает участника на топик, не снимая ранее поставленный mute.
+func (uc *notificationUseCase) autoSubscribe(ctx context.Context, userID, topicID int64) {
+	if userID <= 0 {
+		return
+	}
+	err := uc.subscriptionRepo.Ensure(ctx, &entity.Subscription{
+		UserID:     userID,
+		TargetType: entity.SubscriptionTopic,
+		TargetID:   topicID,
+		CreatedAt:  time.Now().UTC(),
+	})
+	if err != nil {
+		uc.logger.Warn("failed to auto-subscribe",
+			slog.Int64("user_id", userID),
+			slog.Int64("topic_id", topicID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// fanOut рассылает уведомление подписчикам топика, его тегов и категории вместе с её предками.
+func (uc *notificationUseCase) fanOut(ctx context.Context, categoryID int64, n *entity.Notification) {
+	var categoryIDs []int64
+	ancestors, err := uc.categoryRepo.Ancestors(ctx, categoryID)
+	if err != nil {
+		uc.logger.Warn("failed to load category path for notifications",
+			slog.Int64("category_id", categoryID),
+			slog.String("error", err.Error()),
+		)
+		categoryIDs = []int64{categoryID}
+	}
+	for _, c := range ancestors {
+		categoryIDs = append(categoryIDs, c.ID)
+	}
+
+	sent, err := uc.notificationRepo.CreateForSubscribers(ctx, n, categoryIDs)
+	if err != nil {
+		uc.logger.Error("failed to create notifications",
+			slog.String("kind", string(n.Kind)),
+			slog.Int64("topic_id", n.TopicID),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	uc.logger.Debug("notifications created",
+		slog.String("kind", string(n.Kind)),
+		slog.Int64("topic_id", n.TopicID),
+		slog.Int64("recipients", sent),
+	)
+}
@@ -3,6 +3,8 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"strings"
 	"time"
@@ -16,63 +18,199 @@ import (
 
 type PostUseCase interface {
 	CreatePost(ctx context.Context, post *entity.Post) (int64, error)
-	GetPostByID(ctx context.Context, id int64) (*entity.Post, error)
+	// GetPostByID отдаёт неопубликованный пост только тем, кому его можно видеть (entity.Viewer.CanSee).
+	GetPostByID(ctx context.Context, id int64, viewer entity.Viewer) (*entity.Post, error)
 	ListByTopic(ctx context.Context, topicID int64, limit, offset int) ([]*entity.Post, int64, error)
 	List(ctx context.Context, topicID, tagID int64, limit, offset int) ([]*entity.Post, int64, error)
-	UpdatePost(ctx context.Context, req *forumv1.UpdatePostRequest) (*entity.Post, error)
-	DeletePost(ctx context.Context, id int64) error
+	// UpdatePost и DeletePost пишут в журнал аудита действия над чужими постами; actorID — кто их выполняет.
+	// Править можно свой пост; чужой — модератору, а wiki-пост ещё и с привилегией edit_wiki. Иначе ErrForbidden.
+	// Правка текста сохраняется в истории версий вместе с reason (необязательна).
+	// expectedVersion — версия, которую видел клиент; 0 — без проверки. Устаревшая — ErrVersionMismatch.
+	UpdatePost(ctx context.Context, req *forumv1.UpdatePostRequest, expectedVersion int64, actor entity.Viewer, reason string) (*entity.Post, error)
+	// RollbackPost возвращает текст версии number новой правкой; только для модераторов.
+	RollbackPost(ctx context.Context, id int64, number int, actor entity.Viewer, reason string) (*entity.Post, error)
+	// DeletePost в закрытом топике доступен только модераторам.
+	DeletePost(ctx context.Context, id int64, actor entity.Viewer) error
 	ListPostsByTag(ctx context.Context, tagID int64, limit, offset int) ([]*entity.Post, int64, error)
-	AddView(ctx context.Context, postID, userID int64) error
+	AddView(ctx context.Context, postID int64, viewer entity.Viewer) error
+	RollupViews(ctx context.Context, olderThan time.Duration) (int64, error)
 	SearchPosts(ctx context.Context, query string, limit, offset int) ([]*entity.Post, int64, error)
+	// SetWiki открывает пост для совместного редактирования; менять флаг могут автор и модераторы.
+	SetWiki(ctx context.Context, id int64, wiki bool, actor entity.Viewer) (*entity.Post, error)
 }
 
 type postUseCase struct {
-	postRepo  repository.PostRepository
-	topicRepo repository.TopicRepository
-	tagRepo   repository.TagRepository
-	logger    *slog.Logger
+	postRepo     repository.PostRepository
+	topicRepo    repository.TopicRepository
+	tagRepo      repository.TagRepository
+	auditRepo    repository.AuditRepository
+	outboxRepo   repository.OutboxRepository
+	revisionRepo repository.RevisionRepository
+	tx           repository.Transactor
+	viewWindow   time.Duration
+	guard        PostingGuard
+	limiter      RateLimiter
+	spam         SpamUseCase
+	premod       Premoderation
+	trust        TrustUseCase
+	notifier     Notifier
+	logger       *slog.Logger
 }
 
+// viewRollupBatch — сколько сырых просмотров сворачивается за один запрос.
+const viewRollupBatch = 5000
+
 func NewPostUseCase(
 	postRepo repository.PostRepository,
 	topicRepo repository.TopicRepository,
 	tagRepo repository.TagRepository,
+	auditRepo repository.AuditRepository,
+	outboxRepo repository.OutboxRepository,
+	revisionRepo repository.RevisionRepository,
+	tx repository.Transactor,
+	viewWindow time.Duration,
+	guard PostingGuard,
+	limiter RateLimiter,
+	spam SpamUseCase,
+	premod Premoderation,
+	trust TrustUseCase,
+	notifier Notifier,
 	logger *slog.Logger,
 ) PostUseCase {
 	return &postUseCase{
-		postRepo:  postRepo,
-		topicRepo: topicRepo,
-		tagRepo:   tagRepo,
-		logger:    logger,
+		postRepo:     postRepo,
+		topicRepo:    topicRepo,
+		tagRepo:      tagRepo,
+		auditRepo:    auditRepo,
+		outboxRepo:   outboxRepo,
+		revisionRepo: revisionRepo,
+		tx:           tx,
+		viewWindow:   viewWindow,
+		guard:        guard,
+		limiter:      limiter,
+		spam:         spam,
+		premod:       premod,
+		trust:        trust,
+		notifier:     notifier,
+		logger:       logger,
 	}
 }
 
 func (uc *postUseCase) CreatePost(ctx context.Context, post *entity.Post) (int64, error) {
-	_, err := uc.topicRepo.GetByID(ctx, post.TopicID)
+	topic, err := uc.topicRepo.GetByID(ctx, post.TopicID)
 	if err != nil {
 		uc.logger.Warn("topic not found", slog.Int64("topicID", post.TopicID))
 		return 0, ErrTopicNotFound
 	}
+	if topic.IsLocked() {
+		return 0, ErrTopicLocked
+	}
+	if err := uc.guard.CheckCanPost(ctx, post.AuthorID, topic.CategoryID); err != nil {
+		return 0, err
+	}
+	if err := uc.limiter.AllowPostInTopic(ctx, post.AuthorID, topic.ID); err != nil {
+		return 0, err
+	}
+	if err := uc.limiter.Allow(ctx, entity.RatePostCreate, UserSubject(post.AuthorID)); err != nil {
+		return 0, err
+	}
 
 	post.CreatedAt = time.Now().UTC()
+	post.Status = entity.StatusActive
+	verdict := uc.spam.Check(ctx, post.AuthorID, post.Title+"\n"+post.Content)
+	premoderated := !verdict.Flagged() && uc.premod.Required(ctx, post.AuthorID, topic.CategoryID)
+	held := verdict.Flagged() || premoderated
+	if held {
+		post.Status = entity.StatusPending
+	}
 
-	id, err := uc.postRepo.Create(ctx, post)
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := uc.postRepo.Create(ctx, post); err != nil {
+			return err
+		}
+		ref := &entity.ContentRef{
+			Type:       entity.ContentPost,
+			ID:         post.ID,
+			AuthorID:   post.AuthorID,
+			TopicID:    topic.ID,
+			PostID:     post.ID,
+			CategoryID: topic.CategoryID,
+			Status:     post.Status,
+		}
+		if err := uc.spam.Record(ctx, ref, verdict); err != nil {
+			return err
+		}
+		if premoderated {
+			return uc.premod.Hold(ctx, ref)
+		}
+		if held {
+			// Событие и уведомления уйдут, когда модератор одобрит пост
+			return nil
+		}
+		return uc.outboxRepo.Add(ctx, postEvent(entity.EventPostCreated, post))
+	})
 	if err != nil {
 		uc.logger.Error("failed to create post", slog.String("err", err.Error()))
 		return 0, err
 	}
+	if held {
+		uc.logger.Info("post held for moderation",
+			slog.Int64("postID", post.ID),
+			slog.Int64("authorID", post.AuthorID),
+			slog.Any("flags", verdict.Flags),
+			slog.Bool("premoderated", premoderated),
+		)
+		return post.ID, nil
+	}
+	// Внутри внешней транзакции — после её коммита, как в CreateTopic
+	uc.tx.AfterCommit(ctx, func(ctx context.Context) { uc.notifier.PostCreated(ctx, topic, post) })
 
-	return id, nil
+	return post.ID, nil
 }
-func (uc *postUseCase) AddView(ctx context.Context, postID, userID int64) error {
-	return uc.postRepo.AddView(ctx, postID, userID)
+
+// AddView учитывает просмотр, если зритель ещё не смотрел пост в текущем окне длиной viewWindow.
+// Боты и неопознанные зрители не учитываются.
+func (uc *postUseCase) AddView(ctx context.Context, postID int64, viewer entity.Viewer) error {
+	if isBot(viewer.UserAgent) {
+		return nil
+	}
+	hash := viewerHash(viewer)
+	if hash == "" {
+		return nil
+	}
+	return uc.postRepo.AddView(ctx, postID, viewer.UserID, hash, uc.viewWindow)
 }
-func (uc *postUseCase) GetPostByID(ctx context.Context, id int64) (*entity.Post, error) {
+
+// RollupViews сворачивает сырые просмотры старше olderThan в дневные агрегаты.
+func (uc *postUseCase) RollupViews(ctx context.Context, olderThan time.Duration) (int64, error) {
+	before := time.Now().UTC().Add(-olderThan)
+
+	var total int64
+	for {
+		n, err := uc.postRepo.RollupViews(ctx, before, viewRollupBatch)
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if n < viewRollupBatch {
+			break
+		}
+	}
+
+	if total > 0 {
+		uc.logger.Info("post views rolled up", slog.Int64("rows", total))
+	}
+	return total, nil
+}
+func (uc *postUseCase) GetPostByID(ctx context.Context, id int64, viewer entity.Viewer) (*entity.Post, error) {
 	post, err := uc.postRepo.GetByID(ctx, id)
 	if err != nil {
 		uc.logger.Warn("post not found", slog.Int64("id", id))
 		return nil, ErrPostNotFound
 	}
+	if !viewer.CanSee(post.AuthorID, post.Status) {
+		return nil, ErrPostNotFound
+	}
 	return post, nil
 }
 
@@ -111,12 +249,38 @@ func (uc *postUseCase) SearchPosts(ctx context.Context, query string, limit, off
 	return uc.postRepo.Search(ctx, query, limit, offset)
 }
 
-func (uc *postUseCase) UpdatePost(ctx context.Context, req *forumv1.UpdatePostRequest) (*entity.Post, error) {
+func (uc *postUseCase) UpdatePost(ctx context.Context, req *forumv1.UpdatePostRequest, expectedVersion int64, actor entity.Viewer, reason string) (*entity.Post, error) {
+	actorID := actor.UserID
+	reason = strings.TrimSpace(reason)
+	if err := validateEditReason(reason); err != nil {
+		return nil, err
+	}
 	post, err := uc.postRepo.GetByID(ctx, req.GetId())
 	if err != nil {
 		uc.logger.Warn("post not found", slog.Int64("id", req.GetId()))
 		return nil, ErrPostNotFound
 	}
+	if err := checkVersion(expectedVersion, post.Version); err != nil {
+		return nil, err
+	}
+	// Чужой пост правят модераторы, а wiki-пост — ещё и пользователи с привилегией edit_wiki
+	if actorID != post.AuthorID && !actor.Moderator {
+		if !post.Wiki {
+			return nil, ErrForbidden
+		}
+		if err := uc.trust.Require(ctx, actorID, entity.PrivilegeEditWiki); err != nil {
+			return nil, err
+		}
+	}
+	topic, err := openTopic(ctx, uc.topicRepo, post.TopicID, actor)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.guard.CheckCanEdit(ctx, actorID, topic.CategoryID); err != nil {
+		return nil, err
+	}
+	before := postFields(post)
+	prev := postRevision(post)
 
 	// Мержим изменения
 	if req.Title != nil {
@@ -137,8 +301,51 @@ func (uc *postUseCase) UpdatePost(ctx context.Context, req *forumv1.UpdatePostRe
 			post.Tags[i] = entity.Tag{ID: id}
 		}
 	}
+	if req.Title != nil || req.Content != nil {
+		if err := uc.spam.CheckEdit(ctx, actorID, post.Title+"\n"+post.Content); err != nil {
+			return nil, err
+		}
+	}
 
-	err = uc.postRepo.Update(ctx, post)
+	now := time.Now().UTC()
+	post.EditedBy, post.EditedAt = actorID, &now
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.postRepo.Update(ctx, post); err != nil {
+			return versionConflict(err)
+		}
+		if err := recordRevision(ctx, uc.revisionRepo, prev, &entity.Revision{
+			TargetType: entity.ContentPost,
+			TargetID:   post.ID,
+			Title:      post.Title,
+			Content:    post.Content,
+			EditorID:   actorID,
+			Reason:     reason,
+			CreatedAt:  now,
+		}); err != nil {
+			return err
+		}
+		if actorID != post.AuthorID {
+			if err := uc.auditRepo.Create(ctx, &entity.AuditEntry{
+				ActorID:    actorID,
+				Action:     entity.AuditActionPostUpdate,
+				TargetType: entity.AuditTargetPost,
+				TargetID:   post.ID,
+				Before:     before,
+				After:      postFields(post),
+				Reason:     reason,
+				CreatedAt:  now,
+			}); err != nil {
+				return err
+			}
+		}
+		if !post.Status.Published() {
+			return nil // о неопубликованном подписчики узнают после одобрения
+		}
+		return uc.outboxRepo.Add(ctx, postEvent(entity.EventPostUpdated, post))
+	})
+	if errors.Is(err, ErrVersionMismatch) {
+		return nil, err
+	}
 	if err != nil {
 		uc.logger.Error("failed to update post", slog.String("err", err.Error()))
 		return nil, ErrUpdateFailed
@@ -147,8 +354,153 @@ func (uc *postUseCase) UpdatePost(ctx context.Context, req *forumv1.UpdatePostRe
 	return post, nil
 }
 
-func (uc *postUseCase) DeletePost(ctx context.Context, id int64) error {
-	err := uc.postRepo.Delete(ctx, id)
+func (uc *postUseCase) RollbackPost(ctx context.Context, id int64, number int, actor entity.Viewer, reason string) (*entity.Post, error) {
+	if actor.UserID <= 0 {
+		return nil, ErrUnauthenticated
+	}
+	if !actor.Moderator {
+		return nil, fmt.Errorf("%w: only moderators can roll back edits", ErrForbidden)
+	}
+	reason = strings.TrimSpace(reason)
+	if err := validateEditReason(reason); err != nil {
+		return nil, err
+	}
+	post, err := uc.postRepo.GetByID(ctx, id)
+	if err != nil {
+		uc.logger.Warn("post not found", slog.Int64("id", id))
+		return nil, ErrPostNotFound
+	}
+	target, err := getRevision(ctx, uc.revisionRepo, entity.ContentPost, id, number)
+	if err != nil {
+		return nil, err
+	}
+	// Теги при откате не меняются, а Update заменяет их целиком
+	tags, err := uc.tagRepo.ListByPostID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	post.Tags = make([]entity.Tag, len(tags))
+	for i, t := range tags {
+		post.Tags[i] = *t
+	}
+
+	before := postFields(post)
+	prev := postRevision(post)
+	now := time.Now().UTC()
+	post.Title, post.Content = target.Title, target.Content
+	post.EditedBy, post.EditedAt = actor.UserID, &now
+
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.postRepo.Update(ctx, post); err != nil {
+			return versionConflict(err)
+		}
+		if err := recordRevision(ctx, uc.revisionRepo, prev, &entity.Revision{
+			TargetType:   entity.ContentPost,
+			TargetID:     id,
+			Title:        post.Title,
+			Content:      post.Content,
+			EditorID:     actor.UserID,
+			Reason:       reason,
+			RestoredFrom: number,
+			CreatedAt:    now,
+		}); err != nil {
+			return err
+		}
+		if err := uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    actor.UserID,
+			Action:     entity.AuditActionPostRollback,
+			TargetType: entity.AuditTargetPost,
+			TargetID:   id,
+			Reason:     reason,
+			Before:     before,
+			After:      postFields(post),
+			Details:    map[string]any{"revision": number},
+			CreatedAt:  now,
+		}); err != nil {
+			return err
+		}
+		if !post.Status.Published() {
+			return nil
+		}
+		return uc.outboxRepo.Add(ctx, postEvent(entity.EventPostUpdated, post))
+	})
+	if errors.Is(err, ErrVersionMismatch) {
+		return nil, err
+	}
+	if err != nil {
+		uc.logger.Error("failed to roll back post", slog.Int64("id", id), slog.String("err", err.Error()))
+		return nil, ErrUpdateFailed
+	}
+	return post, nil
+}
+
+func (uc *postUseCase) SetWiki(ctx context.Context, id int64, wiki bool, actor entity.Viewer) (*entity.Post, error) {
+	if actor.UserID <= 0 {
+		return nil, ErrUnauthenticated
+	}
+	post, err := uc.postRepo.GetByID(ctx, id)
+	if err != nil {
+		uc.logger.Warn("post not found", slog.Int64("id", id))
+		return nil, ErrPostNotFound
+	}
+	if !actor.CanSee(post.AuthorID, post.Status) {
+		return nil, ErrPostNotFound
+	}
+	if post.AuthorID != actor.UserID && !actor.Moderator {
+		return nil, fmt.Errorf("%w: only the author or a moderator can change wiki mode", ErrForbidden)
+	}
+	if _, err := openTopic(ctx, uc.topicRepo, post.TopicID, actor); err != nil {
+		return nil, err
+	}
+	if post.Wiki == wiki {
+		return post, nil
+	}
+	post.Wiki = wiki
+
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.postRepo.SetWiki(ctx, id, wiki); err != nil {
+			return err
+		}
+		post.Version++
+		if !post.Status.Published() {
+			return nil
+		}
+		return uc.outboxRepo.Add(ctx, postEvent(entity.EventPostUpdated, post))
+	})
+	if err != nil {
+		uc.logger.Error("failed to set post wiki", slog.Int64("id", id), slog.String("err", err.Error()))
+		return nil, ErrUpdateFailed
+	}
+	return post, nil
+}
+
+func (uc *postUseCase) DeletePost(ctx context.Context, id int64, actor entity.Viewer) error {
+	actorID := actor.UserID
+	post, err := uc.postRepo.GetByID(ctx, id)
+	if err != nil {
+		uc.logger.Warn("post not found", slog.Int64("id", id))
+		return ErrPostNotFound
+	}
+	if _, err := openTopic(ctx, uc.topicRepo, post.TopicID, actor); err != nil {
+		return err
+	}
+
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.postRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+		if err := uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    actorID,
+			Action:     entity.AuditActionPostDelete,
+			TargetType: entity.AuditTargetPost,
+			TargetID:   id,
+			Before:     postFields(post),
+			CreatedAt:  time.Now().UTC(),
+		}); err != nil {
+			return err
+		}
+		return uc.outboxRepo.Add(ctx, deletedEvent(entity.EventPostDeleted, "post", id, map[string]any{"topic_id": post.TopicID}))
+	})
 	if err != nil {
 		uc.logger.Error("failed to delete post", slog.String("err", err.Error()))
 		return ErrDeleteFailed
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	forumv1 "github.com/VaneZ444/golang-forum-protos/gen/go/forum"
+)
+
+func TestUpdatePostPermissions(t *testing.T) {
+	const authorID, otherID = 1, 2
+
+	tests := []struct {
+		name    string
+		wiki    bool
+		actor   entity.Viewer
+		trusted bool
+		want    error
+	}{
+		{name: "author", actor: entity.Viewer{UserID: authorID}},
+		{name: "moderator", actor: entity.Viewer{UserID: otherID, Moderator: true}},
+		{name: "foreign post", actor: entity.Viewer{UserID: otherID}, trusted: true, want: ErrForbidden},
+		{name: "anonymous", actor: entity.Viewer{}, want: ErrForbidden},
+		{name: "foreign wiki without edit_wiki", wiki: true, actor: entity.Viewer{UserID: otherID}, want: ErrInsufficientTrust},
+		{name: "foreign wiki with edit_wiki", wiki: true, actor: entity.Viewer{UserID: otherID}, trusted: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &postUseCase{
+				postRepo:  &fakePostRepo{post: &entity.Post{ID: 10, TopicID: 20, AuthorID: authorID, Wiki: tt.wiki, Version: 1}},
+				topicRepo: &fakeTopicRepo{topic: &entity.Topic{ID: 20}},
+				trust:     &fakeTrust{allowed: tt.trusted},
+				// Проверка санкций идёт после проверки прав и останавливает правку
+				guard:  &fakeGuard{err: ErrUserBanned},
+				logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+			}
+			content := "new content"
+			_, err := uc.UpdatePost(context.Background(), &forumv1.UpdatePostRequest{Id: 10, Content: &content}, 0, tt.actor, "")
+
+			want := tt.want
+			if want == nil {
+				want = ErrUserBanned
+			}
+			if !errors.Is(err, want) {
+				t.Fatalf("UpdatePost() error = %v, want %v", err, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+type PremoderationConfig struct {
+	// MinTrustLevel — с какого уровня доверия автор пишет без премодерации; TrustNew — не проверять
+	MinTrustLevel entity.TrustLevel
+}
+
+// DefaultPremoderationConfig не задерживает новых авторов: ApproveContent ещё не проброшен в хендлер,
+// и задержанный контент некому одобрить, а без опубликованных постов репутация не растёт.
+// TrustBasic включать вместе с RPC одобрения.
+func DefaultPremoderationConfig() PremoderationConfig {
+	return PremoderationConfig{
+		MinTrustLevel: entity.TrustNew,
+	}
+}
+
+// Premoderation решает, публиковать ли новый контент сразу или после одобрения модератором.
+type Premoderation interface {
+	// Required — нужно ли одобрение для контента автора в категории.
+	// Категория с премодерацией распространяет её на свои подкатегории.
+	Required(ctx context.Context, authorID, categoryID int64) bool
+	// Hold ставит созданный в статусе PENDING контент в очередь модерации. Вызывается в транзакции создания.
+	Hold(ctx context.Context, ref *entity.ContentRef) error
+}
+
+type premoderation struct {
+	categoryRepo   repository.CategoryRepository
+	moderationRepo repository.ModerationRepository
+	trust          TrustUseCase
+	cfg            PremoderationConfig
+	logger         *slog.Logger
+}
+
+func NewPremoderation(
+	categoryRepo repository.CategoryRepository,
+	moderationRepo repository.ModerationRepository,
+	trust TrustUseCase,
+	cfg PremoderationConfig,
+	logger *slog.Logger,
+) Premoderation {
+	return &premoderation{
+		categoryRepo:   categoryRepo,
+		moderationRepo: moderationRepo,
+		trust:          trust,
+		cfg:            cfg,
+		logger:         logger,
+	}
+}
+
+// Required при ошибке хранилища задерживает контент: лишняя проверка лучше пропущенной.
+func (p *premoderation) Required(ctx context.Context, authorID, categoryID int64) bool {
+	path, err := p.categoryRepo.Ancestors(ctx, categoryID)
+	if err != nil {
+		p.logger.Warn("failed to check category premoderation", slog.Int64("categoryID", categoryID), slog.String("err", err.Error()))
+		return true
+	}
+	if slices.ContainsFunc(path, func(c *entity.Category) bool { return c.Premoderated }) {
+		return true
+	}
+
+	if p.cfg.MinTrustLevel <= entity.TrustNew {
+		return false
+	}
+	if authorID <= 0 {
+		return true
+	}
+	rep, err := p.trust.Reputation(ctx, authorID)
+	if err != nil {
+		p.logger.Warn("failed to compute reputation", slog.Int64("authorID", authorID), slog.String("err", err.Error()))
+		return true
+	}
+	return rep.Level < p.cfg.MinTrustLevel
+}
+
+func (p *premoderation) Hold(ctx context.Context, ref *entity.ContentRef) error {
+	// Жалоба от системы (reporter_id = 0); auto_hidden — чтобы dismiss опубликовал контент
+	c, err := p.moderationRepo.AddReport(ctx, ref, &entity.Report{
+		TargetType: ref.Type,
+		TargetID:   ref.ID,
+		Reason:     entity.ReasonPremoderation,
+		Weight:     1,
+		CreatedAt:  time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	return p.moderationRepo.MarkAutoHidden(ctx, c.ID)
+}
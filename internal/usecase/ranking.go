@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+// RankingConfig — веса формулы рейтинга.
+// engagement = posts*WeightPosts + comments*WeightComments + likes*WeightLikes + views*WeightViews,
+// hot = engagement за неделю / (возраст в часах + 2)^Gravity.
+type RankingConfig struct {
+	WeightPosts    float64
+	WeightComments float64
+	WeightLikes    float64
+	WeightViews    float64
+	Gravity        float64
+	BatchSize      int
+}
+
+func DefaultRankingConfig() RankingConfig {
+	return RankingConfig{
+		WeightPosts:    3,
+		WeightComments: 2,
+		WeightLikes:    1,
+		WeightViews:    0.05,
+		Gravity:        1.8,
+		BatchSize:      500,
+	}
+}
+
+type RankingUseCase interface {
+	// Recompute пересчитывает рейтинги активных топиков и постов и удаляет рейтинги остальных.
+	Recompute(ctx context.Context) error
+	TopTopics(ctx context.Context, period entity.RankingPeriod, categoryID *int64, limit, offset int) ([]*entity.Topic, int64, error)
+	TopPosts(ctx context.Context, period entity.RankingPeriod, topicID int64, limit, offset int) ([]*entity.Post, int64, error)
+}
+
+type rankingUseCase struct {
+	rankingRepo  repository.RankingRepository
+	topicRepo    repository.TopicRepository
+	postRepo     repository.PostRepository
+	categoryRepo repository.CategoryRepository
+	cfg          RankingConfig
+	logger       *slog.Logger
+}
+
+func NewRankingUseCase(
+	rankingRepo repository.RankingRepository,
+	topicRepo repository.TopicRepository,
+	postRepo repository.PostRepository,
+	categoryRepo repository.CategoryRepository,
+	cfg RankingConfig,
+	logger *slog.Logger,
+) RankingUseCase {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	return &rankingUseCase{
+		rankingRepo:  rankingRepo,
+		topicRepo:    topicRepo,
+		postRepo:     postRepo,
+		categoryRepo: categoryRepo,
+		cfg:          cfg,
+		logger:       logger,
+	}
+}
+
+func (uc *rankingUseCase) Recompute(ctx context.Context) error {
+	now := time.Now()
+
+	topics, err := uc.recompute(ctx, now, uc.rankingRepo.TopicActivity, uc.rankingRepo.SaveTopicScores)
+	if err != nil {
+		return err
+	}
+	posts, err := uc.recompute(ctx, now, uc.rankingRepo.PostActivity, uc.rankingRepo.SavePostScores)
+	if err != nil {
+		return err
+	}
+	// Пересчёт обходит только активные топики и посты, поэтому рейтинги выбывших удаляются отдельно
+	prunedTopics, err := uc.rankingRepo.PruneTopicScores(ctx)
+	if err != nil {
+		return err
+	}
+	prunedPosts, err := uc.rankingRepo.PrunePostScores(ctx)
+	if err != nil {
+		return err
+	}
+
+	uc.logger.Info("rankings recomputed",
+		slog.Int("topics", topics),
+		slog.Int("posts", posts),
+		slog.Int64("prunedTopics", prunedTopics),
+		slog.Int64("prunedPosts", prunedPosts),
+		slog.Duration("took", time.Since(now)),
+	)
+	return nil
+}
+
+func (uc *rankingUseCase) recompute(
+	ctx context.Context,
+	now time.Time,
+	load func(ctx context.Context, now time.Time, afterID int64, limit int) ([]*entity.ActivityStats, error),
+	save func(ctx context.Context, scores []entity.Scores) error,
+) (int, error) {
+	var (
+		afterID int64
+		total   int
+	)
+	for {
+		batch, err := load(ctx, now, afterID, uc.cfg.BatchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(batch) == 0 {
+			return total, nil
+		}
+
+		scores := make([]entity.Scores, len(batch))
+		for i, stats := range batch {
+			scores[i] = uc.score(stats, now)
+		}
+		if err := save(ctx, scores); err != nil {
+			return total, err
+		}
+		total += len(batch)
+
+		if len(batch) < uc.cfg.BatchSize {
+			return total, nil
+		}
+		afterID = batch[len(batch)-1].ID
+	}
+}
+
+func (uc *rankingUseCase) score(stats *entity.ActivityStats, now time.Time) entity.Scores {
+	ageHours := math.Max(now.Sub(stats.CreatedAt).Hours(), 0)
+	return entity.Scores{
+		ID:    stats.ID,
+		Hot:   uc.engagement(stats.Week) / math.Pow(ageHours+2, uc.cfg.Gravity),
+		Day:   uc.engagement(stats.Day),
+		Week:  uc.engagement(stats.Week),
+		Month: uc.engagement(stats.Month),
+		All:   uc.engagement(stats.All),
+	}
+}
+
+func (uc *rankingUseCase) engagement(a entity.Activity) float64 {
+	return float64(a.Posts)*uc.cfg.WeightPosts +
+		float64(a.Comments)*uc.cfg.WeightComments +
+		float64(a.Likes)*uc.cfg.WeightLikes +
+		float64(a.Views)*uc.cfg.WeightViews
+}
+
+func (uc *rankingUseCase) TopTopics(ctx context.Context, period entity.RankingPeriod, categoryID *int64, limit, offset int) ([]*entity.Topic, int64, error) {
+	if !validRankingPeriod(period) {
+		return nil, 0, ErrInvalidRankingPeriod
+	}
+	limit, offset = normalizePage(limit, offset)
+
+	var categoryIDs []int64
+	if categoryID != nil {
+		if _, err := uc.categoryRepo.GetByID(ctx, *categoryID); err != nil {
+			return nil, 0, ErrCategoryNotFound
+		}
+		descendants, err := uc.categoryRepo.Descendants(ctx, *categoryID)
+		if err != nil {
+			return nil, 0, err
+		}
+		categoryIDs = append(categoryIDs, *categoryID)
+		for _, c := range descendants {
+			categoryIDs = append(categoryIDs, c.ID)
+		}
+	}
+	return uc.topicRepo.ListTop(ctx, period, categoryIDs, limit, offset)
+}
+
+func (uc *rankingUseCase) TopPosts(ctx context.Context, period entity.RankingPeriod, topicID int64, limit, offset int) ([]*entity.Post, int64, error) {
+	if !validRankingPeriod(period) {
+		return nil, 0, ErrInvalidRankingPeriod
+	}
+	limit, offset = normalizePage(limit, offset)
+
+	if topicID > 0 {
+		if _, err := uc.topicRepo.GetByID(ctx, topicID); err != nil {
+			return nil, 0, ErrTopicNotFound
+		}
+	}
+	return uc.postRepo.ListTop(ctx, period, topicID, limit, offset)
+}
+
+func validRankingPeriod(period entity.RankingPeriod) bool {
+	switch period {
+	case entity.RankingHot, entity.RankingDay, entity.RankingWeek, entity.RankingMonth, entity.RankingAll:
+		return true
+	}
+	return false
+}
+
+func normalizePage(limit, offset int) (int, int) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
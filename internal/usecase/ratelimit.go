@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+type RateLimitConfig struct {
+	Limits map[entity.RateAction]entity.RateLimit
+	// PostInterval — минимальная пауза между постами одного пользователя в одном топике; 0 — без паузы.
+	PostInterval time.Duration
+}
+
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		Limits: map[entity.RateAction]entity.RateLimit{
+			entity.RateTopicCreate:   {Events: 10, Per: time.Hour, Burst: 3},
+			entity.RatePostCreate:    {Events: 6, Per: time.Minute, Burst: 3},
+			entity.RateCommentCreate: {Events: 10, Per: time.Minute, Burst: 5},
+			entity.RateSearch:        {Events: 2, Per: time.Second, Burst: 5},
+		},
+		PostInterval: 15 * time.Second,
+	}
+}
+
+// RateLimitError возвращается, когда лимит исчерпан; RetryAfter уходит клиенту в RetryInfo.
+type RateLimitError struct {
+	Action     entity.RateAction
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded for %s, retry after %s", e.Action, e.RetryAfter)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+type RateLimiter interface {
+	// Allow списывает одно действие субъекта (пользователь или IP, см. UserSubject); пустой subject не ограничивается.
+	Allow(ctx context.Context, action entity.RateAction, subject string) error
+	// AllowPostInTopic проверяет паузу между постами пользователя в топике.
+	AllowPostInTopic(ctx context.Context, userID, topicID int64) error
+	// Cleanup удаляет давно не используемые корзины.
+	Cleanup(ctx context.Context) error
+}
+
+type rateLimiter struct {
+	store  repository.RateLimitRepository
+	cfg    RateLimitConfig
+	logger *slog.Logger
+}
+
+func NewRateLimiter(store repository.RateLimitRepository, cfg RateLimitConfig, logger *slog.Logger) RateLimiter {
+	return &rateLimiter{
+		store:  store,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// UserSubject — субъект лимита для пользователя; аноним (0) не ограничивается.
+func UserSubject(userID int64) string {
+	if userID <= 0 {
+		return ""
+	}
+	return "user:" + strconv.FormatInt(userID, 10)
+}
+
+// ViewerSubject ограничивает авторизованного зрителя по пользователю, анонимного — по IP.
+func ViewerSubject(viewer entity.Viewer) string {
+	if subject := UserSubject(viewer.UserID); subject != "" {
+		return subject
+	}
+	if viewer.ClientIP == "" {
+		return ""
+	}
+	return "ip:" + viewer.ClientIP
+}
+
+func (l *rateLimiter) Allow(ctx context.Context, action entity.RateAction, subject string) error {
+	limit := l.cfg.Limits[action]
+	if subject == "" || !limit.Enabled() {
+		return nil
+	}
+	return l.take(ctx, action, string(action)+":"+subject, limit)
+}
+
+func (l *rateLimiter) AllowPostInTopic(ctx context.Context, userID, topicID int64) error {
+	if userID <= 0 || l.cfg.PostInterval <= 0 {
+		return nil
+	}
+	key := fmt.Sprintf("post.interval:%d:%d", userID, topicID)
+	return l.take(ctx, entity.RatePostCreate, key, entity.RateLimit{Events: 1, Per: l.cfg.PostInterval, Burst: 1})
+}
+
+// take не блокирует запись при ошибке хранилища: лучше пропустить флуд, чем уронить постинг.
+func (l *rateLimiter) take(ctx context.Context, action entity.RateAction, key string, limit entity.RateLimit) error {
+	wait, err := l.store.Take(ctx, key, limit)
+	if err != nil {
+		l.logger.Warn("rate limit check failed", slog.String("key", key), slog.String("err", err.Error()))
+		return nil
+	}
+	if wait > 0 {
+		return &RateLimitError{Action: action, RetryAfter: wait}
+	}
+	return nil
+}
+
+func (l *rateLimiter) Cleanup(ctx context.Context) error {
+	// Корзина, к которой не обращались дольше полного пополнения, ничем не отличается от новой
+	idle := l.cfg.PostInterval
+	for _, limit := range l.cfg.Limits {
+		if limit.Enabled() {
+			idle = max(idle, time.Duration(limit.Capacity()/limit.TokensPerSecond()*float64(time.Second)))
+		}
+	}
+
+	removed, err := l.store.Cleanup(ctx, idle)
+	if err != nil {
+		return err
+	}
+	if removed > 0 {
+		l.logger.Info("rate limit buckets cleaned up", slog.Int64("count", removed))
+	}
+	return nil
+}
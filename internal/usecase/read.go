@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+type ReadUseCase interface {
+	// MarkRead отмечает прочитанными посты топика до postID включительно.
+	MarkRead(ctx context.Context, userID, topicID, postID int64) error
+	// MarkTopicRead отмечает прочитанным весь топик.
+	MarkTopicRead(ctx context.Context, userID, topicID int64) error
+	// MarkCategoryRead отмечает прочитанным всё в категории и её подкатегориях.
+	MarkCategoryRead(ctx context.Context, userID, categoryID int64) error
+	// FillReadState заполняет Topic.Read для пользователя. Для анонимов ничего не делает.
+	FillReadState(ctx context.Context, userID int64, topics []*entity.Topic) error
+}
+
+type readUseCase struct {
+	readRepo     repository.ReadRepository
+	categoryRepo repository.CategoryRepository
+	logger       *slog.Logger
+}
+
+func NewReadUseCase(readRepo repository.ReadRepository, categoryRepo repository.CategoryRepository, logger *slog.Logger) ReadUseCase {
+	return &readUseCase{
+		readRepo:     readRepo,
+		categoryRepo: categoryRepo,
+		logger:       logger,
+	}
+}
+
+func (uc *readUseCase) MarkRead(ctx context.Context, userID, topicID, postID int64) error {
+	if userID <= 0 || topicID <= 0 || postID <= 0 {
+		return nil
+	}
+	return uc.readRepo.MarkRead(ctx, userID, topicID, postID, time.Now())
+}
+
+func (uc *readUseCase) MarkTopicRead(ctx context.Context, userID, topicID int64) error {
+	if userID <= 0 {
+		return nil
+	}
+	err := uc.readRepo.MarkTopicRead(ctx, userID, topicID, time.Now())
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrTopicNotFound
+	}
+	return err
+}
+
+func (uc *readUseCase) MarkCategoryRead(ctx context.Context, userID, categoryID int64) error {
+	if userID <= 0 {
+		return nil
+	}
+	if _, err := uc.categoryRepo.GetByID(ctx, categoryID); err != nil {
+		return ErrCategoryNotFound
+	}
+	descendants, err := uc.categoryRepo.Descendants(ctx, categoryID)
+	if err != nil {
+		return err
+	}
+
+	ids := []int64{categoryID}
+	for _, c := range descendants {
+		ids = append(ids, c.ID)
+	}
+	if err := uc.readRepo.MarkCategoriesRead(ctx, userID, ids, time.Now()); err != nil {
+		return err
+	}
+
+	uc.logger.Info("category marked read",
+		slog.Int64("user_id", userID),
+		slog.Int64("category_id", categoryID),
+		slog.Int("categories", len(ids)),
+	)
+	return nil
+}
+
+func (uc *readUseCase) FillReadState(ctx context.Context, userID int64, topics []*entity.Topic) error {
+	if userID <= 0 || len(topics) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(topics))
+	for i, t := range topics {
+		ids[i] = t.ID
+	}
+	states, err := uc.readRepo.ReadStates(ctx, userID, ids)
+	if err != nil {
+		return err
+	}
+	for _, t := range topics {
+		t.Read = states[t.ID]
+	}
+	return nil
+}
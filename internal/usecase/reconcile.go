@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+type ReconcileUseCase interface {
+	// Reconcile сверяет все счётчики с исходными таблицами. При fix = false работает как dry-run.
+	Reconcile(ctx context.Context, fix bool) ([]*entity.CounterReport, error)
+}
+
+type reconcileUseCase struct {
+	counterRepo repository.CounterRepository
+	batchSize   int
+	logger      *slog.Logger
+}
+
+func NewReconcileUseCase(counterRepo repository.CounterRepository, batchSize int, logger *slog.Logger) ReconcileUseCase {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	return &reconcileUseCase{
+		counterRepo: counterRepo,
+		batchSize:   batchSize,
+		logger:      logger,
+	}
+}
+
+func (uc *reconcileUseCase) Reconcile(ctx context.Context, fix bool) ([]*entity.CounterReport, error) {
+	reports := make([]*entity.CounterReport, 0, len(entity.Counters))
+	for _, counter := range entity.Counters {
+		report, err := uc.reconcileCounter(ctx, counter, fix)
+		if err != nil {
+			return reports, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+func (uc *reconcileUseCase) reconcileCounter(ctx context.Context, counter entity.Counter, fix bool) (*entity.CounterReport, error) {
+	report := &entity.CounterReport{Counter: counter, Fixed: fix}
+
+	var afterID int64
+	for {
+		drift, scanned, lastID, err := uc.counterRepo.FindDrift(ctx, counter, afterID, uc.batchSize)
+		if err != nil {
+			return report, err
+		}
+		report.Checked += int64(scanned)
+		report.Drift = append(report.Drift, drift...)
+
+		if fix && len(drift) > 0 {
+			ids := make([]int64, len(drift))
+			for i, d := range drift {
+				ids[i] = d.EntityID
+			}
+			if err := uc.counterRepo.Fix(ctx, counter, ids); err != nil {
+				return report, err
+			}
+		}
+
+		if scanned < uc.batchSize {
+			break
+		}
+		afterID = lastID
+	}
+
+	if len(report.Drift) > 0 {
+		uc.logger.Warn("counter drift found",
+			slog.String("counter", string(counter)),
+			slog.Int64("checked", report.Checked),
+			slog.Int("drifted", len(report.Drift)),
+			slog.Bool("fixed", fix),
+		)
+	}
+	return report, nil
+}
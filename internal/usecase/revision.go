@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/VaneZ444/forum-service/internal/diff"
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+// maxEditReason — ограничение на причину правки, в символах.
+const maxEditReason = 500
+
+// RevisionUseCase отдаёт историю правок постов и комментариев тем, кому виден сам объект.
+type RevisionUseCase interface {
+	List(ctx context.Context, targetType entity.ContentType, targetID int64, viewer entity.Viewer, limit, offset int) ([]*entity.Revision, int64, error)
+	Get(ctx context.Context, targetType entity.ContentType, targetID int64, number int, viewer entity.Viewer) (*entity.Revision, error)
+	// Diff сравнивает две любые версии, from может быть и новее to.
+	Diff(ctx context.Context, targetType entity.ContentType, targetID int64, from, to int, viewer entity.Viewer) ([]diff.Line, error)
+}
+
+type revisionUseCase struct {
+	revisionRepo   repository.RevisionRepository
+	moderationRepo repository.ModerationRepository
+	logger         *slog.Logger
+}
+
+func NewRevisionUseCase(
+	revisionRepo repository.RevisionRepository,
+	moderationRepo repository.ModerationRepository,
+	logger *slog.Logger,
+) RevisionUseCase {
+	return &revisionUseCase{
+		revisionRepo:   revisionRepo,
+		moderationRepo: moderationRepo,
+		logger:         logger,
+	}
+}
+
+func (uc *revisionUseCase) List(ctx context.Context, targetType entity.ContentType, targetID int64, viewer entity.Viewer, limit, offset int) ([]*entity.Revision, int64, error) {
+	if err := uc.checkVisible(ctx, targetType, targetID, viewer); err != nil {
+		return nil, 0, err
+	}
+	limit, offset = normalizePage(limit, offset)
+	return uc.revisionRepo.List(ctx, targetType, targetID, limit, offset)
+}
+
+func (uc *revisionUseCase) Get(ctx context.Context, targetType entity.ContentType, targetID int64, number int, viewer entity.Viewer) (*entity.Revision, error) {
+	if err := uc.checkVisible(ctx, targetType, targetID, viewer); err != nil {
+		return nil, err
+	}
+	return getRevision(ctx, uc.revisionRepo, targetType, targetID, number)
+}
+
+func (uc *revisionUseCase) Diff(ctx context.Context, targetType entity.ContentType, targetID int64, from, to int, viewer entity.Viewer) ([]diff.Line, error) {
+	if err := uc.checkVisible(ctx, targetType, targetID, viewer); err != nil {
+		return nil, err
+	}
+	a, err := getRevision(ctx, uc.revisionRepo, targetType, targetID, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := getRevision(ctx, uc.revisionRepo, targetType, targetID, to)
+	if err != nil {
+		return nil, err
+	}
+	return diff.Lines(a.Text(), b.Text()), nil
+}
+
+func (uc *revisionUseCase) checkVisible(ctx context.Context, targetType entity.ContentType, targetID int64, viewer entity.Viewer) error {
+	if targetType != entity.ContentPost && targetType != entity.ContentComment {
+		return fmt.Errorf("%w: revisions are kept for posts and comments only", ErrInvalidRevision)
+	}
+	ref, err := uc.moderationRepo.ContentInfo(ctx, targetType, targetID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return notFoundError(targetType)
+	}
+	if err != nil {
+		return err
+	}
+	if !viewer.CanSee(ref.AuthorID, ref.Status) {
+		return notFoundError(targetType)
+	}
+	return nil
+}
+
+func getRevision(ctx context.Context, repo repository.RevisionRepository, targetType entity.ContentType, targetID int64, number int) (*entity.Revision, error) {
+	rev, err := repo.Get(ctx, targetType, targetID, number)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrRevisionNotFound
+	}
+	return rev, err
+}
+
+// recordRevision сохраняет правку в историю. При первой правке сначала сохраняется исходный текст
+// (prev) как версия 1. Если текст не изменился, версия не создаётся. Вызывается в транзакции правки.
+func recordRevision(ctx context.Context, repo repository.RevisionRepository, prev, next *entity.Revision) error {
+	if prev.Text() == next.Text() {
+		return nil
+	}
+	if _, err := repo.Latest(ctx, prev.TargetType, prev.TargetID); errors.Is(err, repository.ErrNotFound) {
+		if err := repo.Add(ctx, prev); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+	next.Diff = diff.Unified(prev.Text(), next.Text())
+	return repo.Add(ctx, next)
+}
+
+func validateEditReason(reason string) error {
+	if len([]rune(reason)) > maxEditReason {
+		return fmt.Errorf("%w: edit reason is longer than %d characters", ErrInvalidRevision, maxEditReason)
+	}
+	return nil
+}
+
+func postRevision(p *entity.Post) *entity.Revision {
+	return &entity.Revision{
+		TargetType: entity.ContentPost,
+		TargetID:   p.ID,
+		Title:      p.Title,
+		Content:    p.Content,
+		EditorID:   p.AuthorID,
+		CreatedAt:  p.CreatedAt,
+	}
+}
+
+func commentRevision(c *entity.Comment) *entity.Revision {
+	return &entity.Revision{
+		TargetType: entity.ContentComment,
+		TargetID:   c.ID,
+		Content:    c.Content,
+		EditorID:   c.AuthorID,
+		CreatedAt:  c.CreatedAt,
+	}
+}
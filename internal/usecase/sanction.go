@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+type SanctionUseCase interface {
+	PostingGuard
+	// Issue выдаёт бан или мут; s.IssuedBy — модератор из metadata.
+	Issue(ctx context.Context, s *entity.Sanction) (*entity.Sanction, error)
+	// Lift снимает санкцию досрочно.
+	Lift(ctx context.Context, id, moderatorID int64) error
+	ListActive(ctx context.Context, filter repository.SanctionFilter, limit, offset int) ([]*entity.Sanction, int64, error)
+}
+
+// PostingGuard вызывается юзкейсами контента перед записью. categoryID — категория топика,
+// санкции на её предков тоже действуют. userID = 0 (аноним) не проверяется.
+type PostingGuard interface {
+	// CheckCanPost запрещает создание контента забаненным и замученным.
+	CheckCanPost(ctx context.Context, userID, categoryID int64) error
+	// CheckCanEdit запрещает редактирование только забаненным.
+	CheckCanEdit(ctx context.Context, userID, categoryID int64) error
+}
+
+type sanctionUseCase struct {
+	sanctionRepo repository.SanctionRepository
+	categoryRepo repository.CategoryRepository
+	auditRepo    repository.AuditRepository
+	tx           repository.Transactor
+	logger       *slog.Logger
+}
+
+func NewSanctionUseCase(
+	sanctionRepo repository.SanctionRepository,
+	categoryRepo repository.CategoryRepository,
+	auditRepo repository.AuditRepository,
+	tx repository.Transactor,
+	logger *slog.Logger,
+) SanctionUseCase {
+	return &sanctionUseCase{
+		sanctionRepo: sanctionRepo,
+		categoryRepo: categoryRepo,
+		auditRepo:    auditRepo,
+		tx:           tx,
+		logger:       logger,
+	}
+}
+
+func (uc *sanctionUseCase) Issue(ctx context.Context, s *entity.Sanction) (*entity.Sanction, error) {
+	if s.IssuedBy <= 0 {
+		return nil, ErrUnauthenticated
+	}
+	now := time.Now().UTC()
+	s.Reason = strings.TrimSpace(s.Reason)
+	if err := validateSanction(s, now); err != nil {
+		return nil, err
+	}
+	if s.CategoryID != 0 {
+		if _, err := uc.categoryRepo.GetByID(ctx, s.CategoryID); err != nil {
+			return nil, ErrCategoryNotFound
+		}
+	}
+	s.CreatedAt = now
+
+	err := uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.sanctionRepo.Create(ctx, s); err != nil {
+			return err
+		}
+		return uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    s.IssuedBy,
+			Action:     entity.AuditActionSanctionIssue,
+			TargetType: entity.AuditTargetUser,
+			TargetID:   s.UserID,
+			Reason:     s.Reason,
+			After:      sanctionFields(s),
+			CreatedAt:  now,
+		})
+	})
+	if err != nil {
+		uc.logger.Error("failed to issue sanction", slog.Int64("userID", s.UserID), slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	uc.logger.Info("sanction issued",
+		slog.Int64("id", s.ID),
+		slog.Int64("userID", s.UserID),
+		slog.String("kind", string(s.Kind)),
+		slog.Int64("issuedBy", s.IssuedBy),
+	)
+	return s, nil
+}
+
+func (uc *sanctionUseCase) Lift(ctx context.Context, id, moderatorID int64) error {
+	if moderatorID <= 0 {
+		return ErrUnauthenticated
+	}
+	s, err := uc.sanctionRepo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrSanctionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	if !s.IsActive(now) {
+		return ErrSanctionInactive
+	}
+
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.sanctionRepo.Lift(ctx, id, moderatorID, now); err != nil {
+			return err
+		}
+		return uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    moderatorID,
+			Action:     entity.AuditActionSanctionLift,
+			TargetType: entity.AuditTargetUser,
+			TargetID:   s.UserID,
+			Before:     sanctionFields(s),
+			CreatedAt:  now,
+		})
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		// Параллельно сняли
+		return ErrSanctionInactive
+	}
+	return err
+}
+
+func (uc *sanctionUseCase) ListActive(ctx context.Context, filter repository.SanctionFilter, limit, offset int) ([]*entity.Sanction, int64, error) {
+	limit, offset = normalizePage(limit, offset)
+	return uc.sanctionRepo.ListActive(ctx, filter, time.Now().UTC(), limit, offset)
+}
+
+func (uc *sanctionUseCase) CheckCanPost(ctx context.Context, userID, categoryID int64) error {
+	return uc.check(ctx, userID, categoryID, true)
+}
+
+func (uc *sanctionUseCase) CheckCanEdit(ctx context.Context, userID, categoryID int64) error {
+	return uc.check(ctx, userID, categoryID, false)
+}
+
+func (uc *sanctionUseCase) check(ctx context.Context, userID, categoryID int64, includeMutes bool) error {
+	if userID <= 0 {
+		return nil
+	}
+
+	var categoryIDs []int64
+	if categoryID != 0 {
+		path, err := uc.categoryRepo.Ancestors(ctx, categoryID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		for _, c := range path {
+			categoryIDs = append(categoryIDs, c.ID)
+		}
+	}
+
+	sanctions, err := uc.sanctionRepo.ActiveFor(ctx, userID, categoryIDs, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	for _, s := range sanctions {
+		if s.Kind == entity.SanctionMute && !includeMutes {
+			continue
+		}
+		return sanctionError(s)
+	}
+	return nil
+}
+
+// sanctionError сообщает пользователю причину и срок, чтобы их не приходилось выяснять у модераторов.
+func sanctionError(s *entity.Sanction) error {
+	base := ErrUserBanned
+	if s.Kind == entity.SanctionMute {
+		base = ErrUserMuted
+	}
+	until := "permanently"
+	if s.ExpiresAt != nil {
+		until = "until " + s.ExpiresAt.Format(time.RFC3339)
+	}
+	return fmt.Errorf("%w %s: %s", base, until, s.Reason)
+}
+
+func validateSanction(s *entity.Sanction, now time.Time) error {
+	if s.UserID <= 0 {
+		return fmt.Errorf("%w: user_id is required", ErrInvalidSanction)
+	}
+	switch s.Kind {
+	case entity.SanctionBan, entity.SanctionMute:
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidSanction, s.Kind)
+	}
+	if s.Reason == "" {
+		return fmt.Errorf("%w: reason is required", ErrInvalidSanction)
+	}
+	if s.Kind == entity.SanctionMute && s.ExpiresAt == nil {
+		return fmt.Errorf("%w: mute must have an expiry", ErrInvalidSanction)
+	}
+	if s.ExpiresAt != nil && !s.ExpiresAt.After(now) {
+		return fmt.Errorf("%w: expiry must be in the future", ErrInvalidSanction)
+	}
+	return nil
+}
+
+func sanctionFields(s *entity.Sanction) map[string]any {
+	return map[string]any{
+		"id":          s.ID,
+		"user_id":     s.UserID,
+		"kind":        s.Kind,
+		"category_id": s.CategoryID,
+		"reason":      s.Reason,
+		"issued_by":   s.IssuedBy,
+		"expires_at":  s.ExpiresAt,
+	}
+}
@@ -0,0 +1,251 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+	"github.com/VaneZ444/forum-service/internal/spam"
+)
+
+type SpamConfig struct {
+	DuplicateWindow       time.Duration // с какими текстами автора сравнивать новый; столько же хранятся отпечатки
+	DuplicateLookback     int           // сколько последних текстов автора сравнивать
+	MinDuplicateLength    int           // короче (в символах нормализованного текста) повторы не ищем: «спасибо» пишут часто
+	NearDuplicateDistance int           // SimHash, отличающиеся не больше чем на столько бит, считаются почти повтором
+	NewUserMaxLinks       int           // сколько ссылок в одном тексте можно автору без привилегии post_links
+	BannedWords           []string      // слова и фразы, регистр и пунктуация не важны
+	BannedDomains         []string      // домены вместе с поддоменами
+	SpamThreshold         float64       // с такой вероятностью спама по классификатору текст уходит на проверку; 0 — не использовать
+	MinTrainingDocs       int64         // классификатор включается, когда в каждом классе набралось столько текстов
+}
+
+func DefaultSpamConfig() SpamConfig {
+	return SpamConfig{
+		DuplicateWindow:       24 * time.Hour,
+		DuplicateLookback:     50,
+		MinDuplicateLength:    20,
+		NearDuplicateDistance: 3,
+		NewUserMaxLinks:       0,
+		SpamThreshold:         0.9,
+		MinTrainingDocs:       50,
+	}
+}
+
+// fingerprintCleanupBatch — сколько отпечатков удаляется за один запрос.
+const fingerprintCleanupBatch = 5000
+
+type SpamUseCase interface {
+	// Check проверяет текст перед созданием. Ошибки хранилища только логируются:
+	// антиспам не должен ронять публикацию.
+	Check(ctx context.Context, authorID int64, text string) *entity.SpamVerdict
+	// CheckEdit проверяет новый текст правки на запрещённые слова, домены и ссылки без привилегии post_links
+	// и возвращает ErrSpamSuspected. Повторы и классификатор не применяются: правка почти всегда похожа на исходный текст.
+	CheckEdit(ctx context.Context, editorID int64, text string) error
+	// Record сохраняет отпечаток созданного контента, а помеченный — отправляет в очередь модерации.
+	// Вызывается в транзакции создания.
+	Record(ctx context.Context, ref *entity.ContentRef, verdict *entity.SpamVerdict) error
+	// Train учит классификатор на решении модератора.
+	Train(ctx context.Context, text string, isSpam bool) error
+	// Cleanup удаляет отпечатки старше DuplicateWindow.
+	Cleanup(ctx context.Context) (int64, error)
+}
+
+type spamUseCase struct {
+	spamRepo       repository.SpamRepository
+	moderationRepo repository.ModerationRepository
+	trust          TrustUseCase
+	cfg            SpamConfig
+	bannedWords    []string
+	bannedDomains  []string
+	logger         *slog.Logger
+}
+
+func NewSpamUseCase(
+	spamRepo repository.SpamRepository,
+	moderationRepo repository.ModerationRepository,
+	trust TrustUseCase,
+	cfg SpamConfig,
+	logger *slog.Logger,
+) SpamUseCase {
+	bannedWords := make([]string, 0, len(cfg.BannedWords))
+	for _, w := range cfg.BannedWords {
+		if w = spam.Normalize(w); w != "" {
+			bannedWords = append(bannedWords, w)
+		}
+	}
+	bannedDomains := make([]string, 0, len(cfg.BannedDomains))
+	for _, d := range cfg.BannedDomains {
+		if d = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(d)), "www."); d != "" {
+			bannedDomains = append(bannedDomains, d)
+		}
+	}
+
+	return &spamUseCase{
+		spamRepo:       spamRepo,
+		moderationRepo: moderationRepo,
+		trust:          trust,
+		cfg:            cfg,
+		bannedWords:    bannedWords,
+		bannedDomains:  bannedDomains,
+		logger:         logger,
+	}
+}
+
+func (uc *spamUseCase) Check(ctx context.Context, authorID int64, text string) *entity.SpamVerdict {
+	words := spam.Words(text)
+	normalized := strings.Join(words, " ")
+	v := &entity.SpamVerdict{
+		Fingerprint: entity.Fingerprint{
+			AuthorID:  authorID,
+			Hash:      spam.Hash(normalized),
+			SimHash:   spam.SimHash(words),
+			CreatedAt: time.Now().UTC(),
+		},
+	}
+
+	v.Flags = uc.contentFlags(ctx, authorID, text, normalized)
+	if flag := uc.checkDuplicates(ctx, v.Fingerprint, normalized); flag != "" {
+		v.Flags = append(v.Flags, flag)
+	}
+
+	if score, ok := uc.classify(ctx, text); ok {
+		v.Score = score
+		if score >= uc.cfg.SpamThreshold {
+			v.Flags = append(v.Flags, fmt.Sprintf("classified as spam (%.2f)", score))
+		}
+	}
+	return v
+}
+
+func (uc *spamUseCase) CheckEdit(ctx context.Context, editorID int64, text string) error {
+	flags := uc.contentFlags(ctx, editorID, text, strings.Join(spam.Words(text), " "))
+	if len(flags) > 0 {
+		return fmt.Errorf("%w: %s", ErrSpamSuspected, strings.Join(flags, "; "))
+	}
+	return nil
+}
+
+// contentFlags — проверки самого текста: запрещённые слова и домены, ссылки от новых пользователей.
+func (uc *spamUseCase) contentFlags(ctx context.Context, authorID int64, text, normalized string) []string {
+	var flags []string
+	padded := " " + normalized + " "
+	for _, w := range uc.bannedWords {
+		if strings.Contains(padded, " "+w+" ") {
+			flags = append(flags, fmt.Sprintf("banned word %q", w))
+		}
+	}
+
+	hosts := spam.Links(text)
+	for _, host := range hosts {
+		for _, d := range uc.bannedDomains {
+			if spam.MatchDomain(host, d) {
+				flags = append(flags, fmt.Sprintf("banned domain %s", host))
+			}
+		}
+	}
+	if len(hosts) > uc.cfg.NewUserMaxLinks && !uc.trust.Allows(ctx, authorID, entity.PrivilegePostLinks) {
+		flags = append(flags, fmt.Sprintf("%d links from a new user", len(hosts)))
+	}
+	return flags
+}
+
+func (uc *spamUseCase) checkDuplicates(ctx context.Context, fp entity.Fingerprint, normalized string) string {
+	if fp.AuthorID <= 0 || utf8.RuneCountInString(normalized) < uc.cfg.MinDuplicateLength {
+		return ""
+	}
+	recent, err := uc.spamRepo.RecentFingerprints(ctx, fp.AuthorID, fp.CreatedAt.Add(-uc.cfg.DuplicateWindow), uc.cfg.DuplicateLookback)
+	if err != nil {
+		uc.logger.Warn("failed to load recent fingerprints", slog.Int64("authorID", fp.AuthorID), slog.String("err", err.Error()))
+		return ""
+	}
+
+	for _, prev := range recent {
+		if prev.Hash == fp.Hash {
+			return fmt.Sprintf("duplicate of %s %d", prev.ContentType, prev.ContentID)
+		}
+		if spam.Distance(prev.SimHash, fp.SimHash) <= uc.cfg.NearDuplicateDistance {
+			return fmt.Sprintf("near-duplicate of %s %d", prev.ContentType, prev.ContentID)
+		}
+	}
+	return ""
+}
+
+// classify возвращает false, пока классификатор выключен или недообучен.
+func (uc *spamUseCase) classify(ctx context.Context, text string) (float64, bool) {
+	if uc.cfg.SpamThreshold <= 0 {
+		return 0, false
+	}
+	spamDocs, hamDocs, err := uc.spamRepo.TrainingDocs(ctx)
+	if err != nil {
+		uc.logger.Warn("failed to load spam training stats", slog.String("err", err.Error()))
+		return 0, false
+	}
+	if spamDocs < uc.cfg.MinTrainingDocs || hamDocs < uc.cfg.MinTrainingDocs {
+		return 0, false
+	}
+
+	tokens := spam.Tokens(text)
+	stats, err := uc.spamRepo.TokenStats(ctx, tokens)
+	if err != nil {
+		uc.logger.Warn("failed to load spam token stats", slog.String("err", err.Error()))
+		return 0, false
+	}
+	return spam.Classify(tokens, stats, spamDocs, hamDocs), true
+}
+
+func (uc *spamUseCase) Record(ctx context.Context, ref *entity.ContentRef, verdict *entity.SpamVerdict) error {
+	fp := verdict.Fingerprint
+	fp.ContentType = ref.Type
+	fp.ContentID = ref.ID
+	if err := uc.spamRepo.AddFingerprint(ctx, &fp); err != nil {
+		return err
+	}
+	if !verdict.Flagged() {
+		return nil
+	}
+
+	// Жалоба от системы (reporter_id = 0); auto_hidden — чтобы dismiss опубликовал контент
+	c, err := uc.moderationRepo.AddReport(ctx, ref, &entity.Report{
+		TargetType: ref.Type,
+		TargetID:   ref.ID,
+		Reason:     entity.ReasonSpam,
+		Comment:    strings.Join(verdict.Flags, "; "),
+		Weight:     1,
+		CreatedAt:  fp.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	return uc.moderationRepo.MarkAutoHidden(ctx, c.ID)
+}
+
+func (uc *spamUseCase) Train(ctx context.Context, text string, isSpam bool) error {
+	return uc.spamRepo.Train(ctx, spam.Tokens(text), isSpam)
+}
+
+func (uc *spamUseCase) Cleanup(ctx context.Context) (int64, error) {
+	before := time.Now().UTC().Add(-uc.cfg.DuplicateWindow)
+
+	var total int64
+	for {
+		n, err := uc.spamRepo.DeleteFingerprintsBefore(ctx, before, fingerprintCleanupBatch)
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if n < fingerprintCleanupBatch {
+			break
+		}
+	}
+
+	if total > 0 {
+		uc.logger.Info("old content fingerprints removed", slog.Int64("rows", total))
+	}
+	return total, nil
+}
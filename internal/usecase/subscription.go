@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+type SubscriptionUseCase interface {
+	Subscribe(ctx context.Context, userID int64, targetType entity.SubscriptionTarget, targetID int64) error
+	Unsubscribe(ctx context.Context, userID int64, targetType entity.SubscriptionTarget, targetID int64) error
+	// Mute глушит (muted = true) или возвращает уведомления по цели, не удаляя подписку.
+	Mute(ctx context.Context, userID int64, targetType entity.SubscriptionTarget, targetID int64, muted bool) error
+	ListSubscriptions(ctx context.Context, userID int64, limit, offset int) ([]*entity.Subscription, int64, error)
+}
+
+type subscriptionUseCase struct {
+	subscriptionRepo repository.SubscriptionRepository
+	topicRepo        repository.TopicRepository
+	categoryRepo     repository.CategoryRepository
+	tagRepo          repository.TagRepository
+	logger           *slog.Logger
+}
+
+func NewSubscriptionUseCase(
+	subscriptionRepo repository.SubscriptionRepository,
+	topicRepo repository.TopicRepository,
+	categoryRepo repository.CategoryRepository,
+	tagRepo repository.TagRepository,
+	logger *slog.Logger,
+) SubscriptionUseCase {
+	return &subscriptionUseCase{
+		subscriptionRepo: subscriptionRepo,
+		topicRepo:        topicRepo,
+		categoryRepo:     categoryRepo,
+		tagRepo:          tagRepo,
+		logger:           logger,
+	}
+}
+
+func (uc *subscriptionUseCase) Subscribe(ctx context.Context, userID int64, targetType entity.SubscriptionTarget, targetID int64) error {
+	return uc.save(ctx, userID, targetType, targetID, false)
+}
+
+func (uc *subscriptionUseCase) Mute(ctx context.Context, userID int64, targetType entity.SubscriptionTarget, targetID int64, muted bool) error {
+	return uc.save(ctx, userID, targetType, targetID, muted)
+}
+
+func (uc *subscriptionUseCase) save(ctx context.Context, userID int64, targetType entity.SubscriptionTarget, targetID int64, muted bool) error {
+	if userID <= 0 {
+		return ErrUnauthenticated
+	}
+	if err := uc.checkTarget(ctx, targetType, targetID); err != nil {
+		return err
+	}
+	return uc.subscriptionRepo.Save(ctx, &entity.Subscription{
+		UserID:     userID,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Muted:      muted,
+		CreatedAt:  time.Now().UTC(),
+	})
+}
+
+func (uc *subscriptionUseCase) Unsubscribe(ctx context.Context, userID int64, targetType entity.SubscriptionTarget, targetID int64) error {
+	if userID <= 0 {
+		return ErrUnauthenticated
+	}
+	err := uc.subscriptionRepo.Delete(ctx, userID, targetType, targetID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrSubscriptionNotFound
+	}
+	return err
+}
+
+func (uc *subscriptionUseCase) ListSubscriptions(ctx context.Context, userID int64, limit, offset int) ([]*entity.Subscription, int64, error) {
+	if userID <= 0 {
+		return nil, 0, ErrUnauthenticated
+	}
+	limit, offset = normalizePage(limit, offset)
+	return uc.subscriptionRepo.ListByUser(ctx, userID, limit, offset)
+}
+
+func (uc *subscriptionUseCase) checkTarget(ctx context.Context, targetType entity.SubscriptionTarget, targetID int64) error {
+	switch targetType {
+	case entity.SubscriptionTopic:
+		if _, err := uc.topicRepo.GetByID(ctx, targetID); err != nil {
+			return ErrTopicNotFound
+		}
+	case entity.SubscriptionCategory:
+		if _, err := uc.categoryRepo.GetByID(ctx, targetID); err != nil {
+			return ErrCategoryNotFound
+		}
+	case entity.SubscriptionTag:
+		if _, err := uc.tagRepo.GetByID(ctx, targetID); err != nil {
+			return ErrTagNotFound
+		}
+	default:
+		return ErrInvalidSubscriptionTarget
+	}
+	return nil
+}
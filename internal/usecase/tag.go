@@ -4,51 +4,87 @@ import (
 	"context"
 	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/VaneZ444/forum-service/internal/entity"
 	"github.com/VaneZ444/forum-service/internal/repository"
 )
 
 type TagUseCase interface {
-	CreateTag(ctx context.Context, tag *entity.Tag) error
+	// CreateTag доступен модераторам и пользователям с привилегией create_tags.
+	CreateTag(ctx context.Context, tag *entity.Tag, actor entity.Viewer) error
 	GetTagByID(ctx context.Context, id int64) (*entity.Tag, error)
 	GetTagBySlug(ctx context.Context, slug string) (*entity.Tag, error)
 	List(ctx context.Context, limit, offset int) ([]*entity.Tag, int64, error)
 	ListTagsByPostID(ctx context.Context, postID int64) ([]*entity.Tag, error)
-	AddTagToPost(ctx context.Context, postID, tagID int64) error
-	RemoveTagFromPost(ctx context.Context, postID, tagID int64) error
+	// AddTagToPost и RemoveTagFromPost в закрытом топике доступны только модераторам.
+	AddTagToPost(ctx context.Context, postID, tagID int64, actor entity.Viewer) error
+	RemoveTagFromPost(ctx context.Context, postID, tagID int64, actor entity.Viewer) error
 }
 
 type tagUseCase struct {
-	tagRepo  repository.TagRepository
-	postRepo repository.PostRepository
-	logger   *slog.Logger
+	tagRepo    repository.TagRepository
+	postRepo   repository.PostRepository
+	topicRepo  repository.TopicRepository
+	auditRepo  repository.AuditRepository
+	outboxRepo repository.OutboxRepository
+	tx         repository.Transactor
+	trust      TrustUseCase
+	logger     *slog.Logger
 }
 
 func NewTagUseCase(
 	tagRepo repository.TagRepository,
 	postRepo repository.PostRepository,
+	topicRepo repository.TopicRepository,
+	auditRepo repository.AuditRepository,
+	outboxRepo repository.OutboxRepository,
+	tx repository.Transactor,
+	trust TrustUseCase,
 	logger *slog.Logger,
 ) TagUseCase {
 	return &tagUseCase{
-		tagRepo:  tagRepo,
-		postRepo: postRepo,
-		logger:   logger,
+		tagRepo:    tagRepo,
+		postRepo:   postRepo,
+		topicRepo:  topicRepo,
+		auditRepo:  auditRepo,
+		outboxRepo: outboxRepo,
+		tx:         tx,
+		trust:      trust,
+		logger:     logger,
 	}
 }
 
-func (uc *tagUseCase) CreateTag(ctx context.Context, tag *entity.Tag) error {
+func (uc *tagUseCase) CreateTag(ctx context.Context, tag *entity.Tag, actor entity.Viewer) error {
+	if !actor.Moderator {
+		if err := uc.trust.Require(ctx, actor.UserID, entity.PrivilegeCreateTags); err != nil {
+			return err
+		}
+	}
 	if tag.Slug == "" {
 		tag.Slug = strings.ToLower(strings.ReplaceAll(tag.Name, " ", "-"))
 	}
 
-	id, err := uc.tagRepo.Create(ctx, tag)
+	err := uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := uc.tagRepo.Create(ctx, tag); err != nil {
+			return err
+		}
+		if err := uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    actor.UserID,
+			Action:     entity.AuditActionTagCreate,
+			TargetType: entity.AuditTargetTag,
+			TargetID:   tag.ID,
+			After:      tagFields(tag),
+			CreatedAt:  time.Now().UTC(),
+		}); err != nil {
+			return err
+		}
+		return uc.outboxRepo.Add(ctx, tagEvent(tag))
+	})
 	if err != nil {
 		uc.logger.Error("failed to create tag", slog.String("err", err.Error()))
 		return err
 	}
-
-	tag.ID = id
 	return nil
 }
 
@@ -87,20 +123,23 @@ func (uc *tagUseCase) ListTagsByPostID(ctx context.Context, postID int64) ([]*en
 	return uc.tagRepo.ListByPostID(ctx, postID)
 }
 
-func (uc *tagUseCase) AddTagToPost(ctx context.Context, postID, tagID int64) error {
-	_, err := uc.postRepo.GetByID(ctx, postID)
-	if err != nil {
-		uc.logger.Warn("post not found", slog.Int64("postID", postID))
-		return ErrPostNotFound
+func (uc *tagUseCase) AddTagToPost(ctx context.Context, postID, tagID int64, actor entity.Viewer) error {
+	if err := uc.ensurePostEditable(ctx, postID, actor); err != nil {
+		return err
 	}
 
-	_, err = uc.tagRepo.GetByID(ctx, tagID)
+	_, err := uc.tagRepo.GetByID(ctx, tagID)
 	if err != nil {
 		uc.logger.Warn("tag not found", slog.Int64("tagID", tagID))
 		return ErrTagNotFound
 	}
 
-	err = uc.tagRepo.AddToPost(ctx, postID, tagID)
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.tagRepo.AddToPost(ctx, postID, tagID); err != nil {
+			return err
+		}
+		return uc.outboxRepo.Add(ctx, postTagEvent(entity.EventTagAttached, postID, tagID))
+	})
 	if err != nil {
 		uc.logger.Error("failed to add tag to post", slog.String("err", err.Error()))
 		return err
@@ -109,11 +148,31 @@ func (uc *tagUseCase) AddTagToPost(ctx context.Context, postID, tagID int64) err
 	return nil
 }
 
-func (uc *tagUseCase) RemoveTagFromPost(ctx context.Context, postID, tagID int64) error {
-	err := uc.tagRepo.RemoveFromPost(ctx, postID, tagID)
+func (uc *tagUseCase) RemoveTagFromPost(ctx context.Context, postID, tagID int64, actor entity.Viewer) error {
+	if err := uc.ensurePostEditable(ctx, postID, actor); err != nil {
+		return err
+	}
+
+	err := uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.tagRepo.RemoveFromPost(ctx, postID, tagID); err != nil {
+			return err
+		}
+		return uc.outboxRepo.Add(ctx, postTagEvent(entity.EventTagDetached, postID, tagID))
+	})
 	if err != nil {
 		uc.logger.Error("failed to remove tag from post", slog.String("err", err.Error()))
 		return err
 	}
 	return nil
 }
+
+// ensurePostEditable проверяет, что пост есть и его топик открыт для actor.
+func (uc *tagUseCase) ensurePostEditable(ctx context.Context, postID int64, actor entity.Viewer) error {
+	post, err := uc.postRepo.GetByID(ctx, postID)
+	if err != nil {
+		uc.logger.Warn("post not found", slog.Int64("postID", postID))
+		return ErrPostNotFound
+	}
+	_, err = openTopic(ctx, uc.topicRepo, post.TopicID, actor)
+	return err
+}
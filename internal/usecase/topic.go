@@ -2,7 +2,9 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/VaneZ444/forum-service/internal/entity"
@@ -12,27 +14,69 @@ import (
 
 type TopicUseCase interface {
 	CreateTopic(ctx context.Context, topic *entity.Topic, post *entity.Post) (int64, int64, error)
-	GetByID(ctx context.Context, id int64) (*entity.Topic, *entity.Post, error)
-	List(ctx context.Context, categoryID *int64, limit, offset int, sorting *forumv1.Sorting) ([]*entity.Topic, int64, error)
-	UpdateTopic(ctx context.Context, topic *entity.Topic) (*entity.Topic, error)
-	DeleteTopic(ctx context.Context, id int64) error
+	// GetByID отдаёт неопубликованный топик только тем, кому его можно видеть (entity.Viewer.CanSee).
+	GetByID(ctx context.Context, id int64, viewer entity.Viewer) (*entity.Topic, *entity.Post, error)
+	List(ctx context.Context, categoryID *int64, includeDescendants bool, limit, offset int, sorting *forumv1.Sorting) ([]*entity.Topic, int64, error)
+	// UpdateTopic и DeleteTopic пишут в журнал аудита действия над чужими топиками; actorID — кто их выполняет.
+	// topic.Version — ожидаемая версия, как в UpdateCategory. Закрытый топик правят только модераторы.
+	UpdateTopic(ctx context.Context, topic *entity.Topic, actor entity.Viewer) (*entity.Topic, error)
+	DeleteTopic(ctx context.Context, id, actorID int64) error
 	SearchTopics(ctx context.Context, query string, limit, offset int) ([]*entity.Topic, int64, error)
+	LockTopic(ctx context.Context, id, moderatorID int64, reason string) (*entity.Topic, error)
+	UnlockTopic(ctx context.Context, id, moderatorID int64) (*entity.Topic, error)
+	CloseInactiveTopics(ctx context.Context, inactiveFor time.Duration) (int64, error)
+	MoveTopic(ctx context.Context, id, categoryID, moderatorID int64, leaveRedirect bool, reason string) (*entity.Topic, error)
+	MergeTopics(ctx context.Context, sourceID, targetID, moderatorID int64, leaveRedirect bool, reason string) (*entity.Topic, error)
+	SplitTopic(ctx context.Context, sourceID int64, postIDs []int64, title string, categoryID, moderatorID int64, reason string) (*entity.Topic, error)
+	// AcceptAnswer отмечает пост топика принятым ответом, postID = 0 снимает отметку.
+	// Выбирают автор топика и модераторы; принятый ответ в чужом топике добавляет автору поста репутацию.
+	AcceptAnswer(ctx context.Context, topicID, postID int64, actor entity.Viewer) (*entity.Topic, error)
 }
 
+// autoCloseReason пишется в lock_reason топиков, закрытых по неактивности.
+const autoCloseReason = "closed automatically due to inactivity"
+
 type topicUseCase struct {
 	topicRepo    repository.TopicRepository
 	categoryRepo repository.CategoryRepository
+	postRepo     repository.PostRepository
+	auditRepo    repository.AuditRepository
+	outboxRepo   repository.OutboxRepository
+	tx           repository.Transactor
+	guard        PostingGuard
+	limiter      RateLimiter
+	premod       Premoderation
+	spam         SpamUseCase
+	notifier     Notifier
 	logger       *slog.Logger
 }
 
 func NewTopicUseCase(
 	topicRepo repository.TopicRepository,
 	categoryRepo repository.CategoryRepository,
+	postRepo repository.PostRepository,
+	auditRepo repository.AuditRepository,
+	outboxRepo repository.OutboxRepository,
+	tx repository.Transactor,
+	guard PostingGuard,
+	limiter RateLimiter,
+	premod Premoderation,
+	spam SpamUseCase,
+	notifier Notifier,
 	logger *slog.Logger,
 ) TopicUseCase {
 	return &topicUseCase{
 		topicRepo:    topicRepo,
 		categoryRepo: categoryRepo,
+		postRepo:     postRepo,
+		auditRepo:    auditRepo,
+		outboxRepo:   outboxRepo,
+		tx:           tx,
+		guard:        guard,
+		limiter:      limiter,
+		premod:       premod,
+		spam:         spam,
+		notifier:     notifier,
 		logger:       logger,
 	}
 }
@@ -47,26 +91,84 @@ func (uc *topicUseCase) CreateTopic(ctx context.Context, topic *entity.Topic, po
 		)
 		return 0, 0, ErrCategoryNotFound
 	}
+	if err := uc.guard.CheckCanPost(ctx, topic.AuthorID, topic.CategoryID); err != nil {
+		return 0, 0, err
+	}
+	if err := uc.limiter.Allow(ctx, entity.RateTopicCreate, UserSubject(topic.AuthorID)); err != nil {
+		return 0, 0, err
+	}
 
 	// Set timestamps
 	now := time.Now().UTC()
 	topic.CreatedAt = now
 	post.CreatedAt = now
+	topic.Status, post.Status = entity.StatusActive, entity.StatusActive
+	verdict := uc.spam.Check(ctx, topic.AuthorID, topic.Title+"\n"+post.Content)
+	premoderated := !verdict.Flagged() && uc.premod.Required(ctx, topic.AuthorID, topic.CategoryID)
+	held := verdict.Flagged() || premoderated
+	if held {
+		topic.Status, post.Status = entity.StatusPending, entity.StatusPending
+	}
 
 	// Create topic with first post
-	err = uc.topicRepo.CreateWithPost(ctx, topic, post)
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.topicRepo.CreateWithPost(ctx, topic, post); err != nil {
+			return err
+		}
+		// Первый пост проверяется и одобряется вместе с топиком
+		ref := &entity.ContentRef{
+			Type:       entity.ContentTopic,
+			ID:         topic.ID,
+			AuthorID:   topic.AuthorID,
+			TopicID:    topic.ID,
+			CategoryID: topic.CategoryID,
+			Status:     topic.Status,
+		}
+		if err := uc.spam.Record(ctx, ref, verdict); err != nil {
+			return err
+		}
+		if premoderated {
+			return uc.premod.Hold(ctx, ref)
+		}
+		if held {
+			// События и уведомления уйдут, когда модератор одобрит топик
+			return nil
+		}
+		return uc.outboxRepo.Add(ctx,
+			topicEvent(entity.EventTopicCreated, topic),
+			postEvent(entity.EventPostCreated, post),
+		)
+	})
 	if err != nil {
 		uc.logger.Error("failed to create topic with post",
 			slog.String("error", err.Error()),
 		)
 		return 0, 0, err
 	}
+	if held {
+		uc.logger.Info("topic held for moderation",
+			slog.Int64("topicID", topic.ID),
+			slog.Int64("authorID", topic.AuthorID),
+			slog.Any("flags", verdict.Flags),
+			slog.Bool("premoderated", premoderated),
+		)
+		return topic.ID, post.ID, nil
+	}
+	// Внутри внешней транзакции (публикация черновика) уведомления уходят после её коммита:
+	// ошибка в их SQL не должна обрывать транзакцию с контентом.
+	uc.tx.AfterCommit(ctx, func(ctx context.Context) { uc.notifier.TopicCreated(ctx, topic, post) })
 
 	return topic.ID, post.ID, nil
 }
 
-func (uc *topicUseCase) GetByID(ctx context.Context, id int64) (*entity.Topic, *entity.Post, error) {
+func (uc *topicUseCase) GetByID(ctx context.Context, id int64, viewer entity.Viewer) (*entity.Topic, *entity.Post, error) {
 	topic, post, err := uc.topicRepo.GetByIDWithFirstPost(ctx, id)
+	if err == repository.ErrNotFound {
+		// У redirect-заглушки нет постов — отдаём топик, на который она ведёт
+		if stub, stubErr := uc.topicRepo.GetByID(ctx, id); stubErr == nil && stub.IsRedirect() {
+			topic, post, err = uc.topicRepo.GetByIDWithFirstPost(ctx, stub.MovedToID)
+		}
+	}
 	if err != nil {
 		if err == repository.ErrNotFound {
 			uc.logger.Warn("topic not found", slog.Int64("id", id))
@@ -78,12 +180,24 @@ func (uc *topicUseCase) GetByID(ctx context.Context, id int64) (*entity.Topic, *
 		)
 		return nil, nil, err
 	}
+	if !viewer.CanSee(topic.AuthorID, topic.Status) {
+		return nil, nil, ErrTopicNotFound
+	}
+
+	topic.Breadcrumbs, err = uc.categoryRepo.Ancestors(ctx, topic.CategoryID)
+	if err != nil {
+		uc.logger.Warn("failed to load breadcrumbs",
+			slog.Int64("category_id", topic.CategoryID),
+			slog.String("error", err.Error()),
+		)
+	}
 	return topic, post, nil
 }
 
 func (uc *topicUseCase) List(
 	ctx context.Context,
 	categoryID *int64,
+	includeDescendants bool,
 	limit, offset int,
 	sorting *forumv1.Sorting,
 ) ([]*entity.Topic, int64, error) {
@@ -95,6 +209,7 @@ func (uc *topicUseCase) List(
 	}
 
 	// Проверяем категорию, если указана
+	var categoryIDs []int64
 	if categoryID != nil {
 		_, err := uc.categoryRepo.GetByID(ctx, *categoryID)
 		if err != nil {
@@ -104,13 +219,25 @@ func (uc *topicUseCase) List(
 			)
 			return nil, 0, ErrCategoryNotFound
 		}
+		categoryIDs = append(categoryIDs, *categoryID)
+
+		if includeDescendants {
+			descendants, err := uc.categoryRepo.Descendants(ctx, *categoryID)
+			if err != nil {
+				return nil, 0, err
+			}
+			for _, c := range descendants {
+				categoryIDs = append(categoryIDs, c.ID)
+			}
+		}
 	}
 
 	// Передаем сортировку дальше в репозиторий
-	return uc.topicRepo.List(ctx, categoryID, limit, offset, sorting)
+	return uc.topicRepo.List(ctx, categoryIDs, limit, offset, sorting)
 }
 
-func (uc *topicUseCase) UpdateTopic(ctx context.Context, topic *entity.Topic) (*entity.Topic, error) {
+func (uc *topicUseCase) UpdateTopic(ctx context.Context, topic *entity.Topic, actor entity.Viewer) (*entity.Topic, error) {
+	actorID := actor.UserID
 	existing, err := uc.topicRepo.GetByID(ctx, topic.ID)
 	if err != nil {
 		if err == repository.ErrNotFound {
@@ -118,8 +245,18 @@ func (uc *topicUseCase) UpdateTopic(ctx context.Context, topic *entity.Topic) (*
 		}
 		return nil, err
 	}
+	if err := checkTopicOpen(existing, actor); err != nil {
+		return nil, err
+	}
+	if err := uc.guard.CheckCanEdit(ctx, actorID, existing.CategoryID); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(topic.Version, existing.Version); err != nil {
+		return nil, err
+	}
 
 	// Preserve неизменяемые поля
+	topic.Version = existing.Version
 	topic.AuthorID = existing.AuthorID
 	topic.CreatedAt = existing.CreatedAt
 	topic.Status = existing.Status
@@ -129,12 +266,39 @@ func (uc *topicUseCase) UpdateTopic(ctx context.Context, topic *entity.Topic) (*
 	// Обновляем last_activity
 	topic.LastActivity = time.Now().UTC()
 
-	return uc.topicRepo.Update(ctx, topic)
+	var updated *entity.Topic
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if updated, err = uc.topicRepo.Update(ctx, topic); err != nil {
+			return versionConflict(err)
+		}
+		if actorID != existing.AuthorID {
+			if err := uc.auditRepo.Create(ctx, &entity.AuditEntry{
+				ActorID:    actorID,
+				Action:     entity.AuditActionTopicUpdate,
+				TargetType: entity.AuditTargetTopic,
+				TargetID:   topic.ID,
+				Before:     topicFields(existing),
+				After:      topicFields(updated),
+				CreatedAt:  time.Now().UTC(),
+			}); err != nil {
+				return err
+			}
+		}
+		if !existing.Status.Published() {
+			return nil // о неопубликованном подписчики узнают после одобрения
+		}
+		return uc.outboxRepo.Add(ctx, topicEvent(entity.EventTopicUpdated, updated))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
 }
 
-func (uc *topicUseCase) DeleteTopic(ctx context.Context, id int64) error {
+func (uc *topicUseCase) DeleteTopic(ctx context.Context, id, actorID int64) error {
 	// Check if topic exists
-	_, _, err := uc.topicRepo.GetByIDWithFirstPost(ctx, id)
+	topic, _, err := uc.topicRepo.GetByIDWithFirstPost(ctx, id)
 	if err != nil {
 		if err == repository.ErrNotFound {
 			return ErrTopicNotFound
@@ -142,7 +306,23 @@ func (uc *topicUseCase) DeleteTopic(ctx context.Context, id int64) error {
 		return err
 	}
 
-	return uc.topicRepo.Delete(ctx, id)
+	// Посты и комментарии удаляются каскадом, отдельных событий по ним нет
+	return uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.topicRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+		if err := uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    actorID,
+			Action:     entity.AuditActionTopicDelete,
+			TargetType: entity.AuditTargetTopic,
+			TargetID:   id,
+			Before:     topicFields(topic),
+			CreatedAt:  time.Now().UTC(),
+		}); err != nil {
+			return err
+		}
+		return uc.outboxRepo.Add(ctx, deletedEvent(entity.EventTopicDeleted, "topic", id, map[string]any{"category_id": topic.CategoryID}))
+	})
 }
 func (uc *topicUseCase) SearchTopics(ctx context.Context, query string, limit, offset int) ([]*entity.Topic, int64, error) {
 	if limit <= 0 || limit > 100 {
@@ -153,3 +333,462 @@ func (uc *topicUseCase) SearchTopics(ctx context.Context, query string, limit, o
 	}
 	return uc.topicRepo.Search(ctx, query, limit, offset)
 }
+
+func (uc *topicUseCase) LockTopic(ctx context.Context, id, moderatorID int64, reason string) (*entity.Topic, error) {
+	topic, err := uc.topicRepo.GetByID(ctx, id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrTopicNotFound
+		}
+		return nil, err
+	}
+	if topic.IsLocked() {
+		return nil, ErrTopicLocked
+	}
+
+	now := time.Now().UTC()
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.topicRepo.Lock(ctx, id, moderatorID, reason, now); err != nil {
+			return err
+		}
+		if err := uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    moderatorID,
+			Action:     entity.AuditActionTopicLock,
+			TargetType: entity.AuditTargetTopic,
+			TargetID:   id,
+			Reason:     reason,
+			CreatedAt:  now,
+		}); err != nil {
+			return err
+		}
+		if !topic.Status.Published() {
+			return nil
+		}
+		return uc.outboxRepo.Add(ctx, topicEventWith(entity.EventTopicLocked, topic, map[string]any{
+			"locked_at":   now,
+			"locked_by":   moderatorID,
+			"lock_reason": reason,
+		}))
+	})
+	if err != nil {
+		uc.logger.Error("failed to lock topic",
+			slog.Int64("id", id),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	uc.logger.Info("topic locked",
+		slog.Int64("id", id),
+		slog.Int64("moderator_id", moderatorID),
+		slog.String("reason", reason),
+	)
+
+	topic.LockedAt = &now
+	topic.LockedBy = moderatorID
+	topic.LockReason = reason
+	return topic, nil
+}
+
+func (uc *topicUseCase) UnlockTopic(ctx context.Context, id, moderatorID int64) (*entity.Topic, error) {
+	topic, err := uc.topicRepo.GetByID(ctx, id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrTopicNotFound
+		}
+		return nil, err
+	}
+	if !topic.IsLocked() {
+		return nil, ErrTopicNotLocked
+	}
+
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.topicRepo.Unlock(ctx, id); err != nil {
+			return err
+		}
+		if err := uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    moderatorID,
+			Action:     entity.AuditActionTopicUnlock,
+			TargetType: entity.AuditTargetTopic,
+			TargetID:   id,
+			Details:    map[string]any{"lock_reason": topic.LockReason},
+			CreatedAt:  time.Now().UTC(),
+		}); err != nil {
+			return err
+		}
+		if !topic.Status.Published() {
+			return nil
+		}
+		return uc.outboxRepo.Add(ctx, topicEventWith(entity.EventTopicUnlocked, topic, map[string]any{"unlocked_by": moderatorID}))
+	})
+	if err != nil {
+		uc.logger.Error("failed to unlock topic",
+			slog.Int64("id", id),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	uc.logger.Info("topic unlocked",
+		slog.Int64("id", id),
+		slog.Int64("moderator_id", moderatorID),
+	)
+
+	topic.LockedAt = nil
+	topic.LockedBy = 0
+	topic.LockReason = ""
+	return topic, nil
+}
+
+// CloseInactiveTopics закрывает топики, в которых не было активности дольше inactiveFor.
+// Как и LockTopic, пишет в аудит и отправляет topic.locked — от имени системы (actor 0).
+func (uc *topicUseCase) CloseInactiveTopics(ctx context.Context, inactiveFor time.Duration) (int64, error) {
+	now := time.Now().UTC()
+	var closed []*entity.Topic
+	err := uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		closed, err = uc.topicRepo.LockInactive(ctx, now.Add(-inactiveFor), autoCloseReason, now)
+		if err != nil {
+			return err
+		}
+		for _, topic := range closed {
+			if err := uc.auditRepo.Create(ctx, &entity.AuditEntry{
+				Action:     entity.AuditActionTopicLock,
+				TargetType: entity.AuditTargetTopic,
+				TargetID:   topic.ID,
+				Reason:     autoCloseReason,
+				CreatedAt:  now,
+			}); err != nil {
+				return err
+			}
+			if err := uc.outboxRepo.Add(ctx, topicEventWith(entity.EventTopicLocked, topic, map[string]any{
+				"locked_at":   now,
+				"locked_by":   int64(0),
+				"lock_reason": autoCloseReason,
+			})); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(closed) > 0 {
+		uc.logger.Info("inactive topics closed", slog.Int("count", len(closed)))
+	}
+	return int64(len(closed)), nil
+}
+
+func (uc *topicUseCase) MoveTopic(ctx context.Context, id, categoryID, moderatorID int64, leaveRedirect bool, reason string) (*entity.Topic, error) {
+	topic, err := uc.getMovableTopic(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if topic.CategoryID == categoryID {
+		return nil, ErrInvalidTopicMove
+	}
+	if _, err := uc.categoryRepo.GetByID(ctx, categoryID); err != nil {
+		uc.logger.Warn("category not found", slog.Int64("category_id", categoryID))
+		return nil, ErrCategoryNotFound
+	}
+
+	now := time.Now().UTC()
+	var redirectID int64
+	var moved *entity.Topic
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.topicRepo.Move(ctx, id, categoryID); err != nil {
+			return err
+		}
+		if leaveRedirect {
+			if redirectID, err = uc.topicRepo.CreateRedirect(ctx, topic, id, now); err != nil {
+				return err
+			}
+		}
+		if moved, err = uc.topicRepo.GetByID(ctx, id); err != nil {
+			return err
+		}
+		if moved.Status.Published() {
+			if err := uc.outboxRepo.Add(ctx, topicEventWith(entity.EventTopicMoved, moved, map[string]any{
+				"from_category_id": topic.CategoryID,
+				"redirect_id":      redirectID,
+			})); err != nil {
+				return err
+			}
+		}
+		return uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    moderatorID,
+			Action:     entity.AuditActionTopicMove,
+			TargetType: entity.AuditTargetTopic,
+			TargetID:   id,
+			Reason:     reason,
+			Details: map[string]any{
+				"from_category_id": topic.CategoryID,
+				"to_category_id":   categoryID,
+				"redirect_id":      redirectID,
+			},
+			CreatedAt: now,
+		})
+	})
+	if err != nil {
+		uc.logger.Error("failed to move topic",
+			slog.Int64("id", id),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	return moved, nil
+}
+
+func (uc *topicUseCase) MergeTopics(ctx context.Context, sourceID, targetID, moderatorID int64, leaveRedirect bool, reason string) (*entity.Topic, error) {
+	if sourceID == targetID {
+		return nil, ErrInvalidTopicMove
+	}
+	source, err := uc.getMovableTopic(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := uc.getMovableTopic(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		moved, err := uc.topicRepo.MovePosts(ctx, sourceID, targetID, nil)
+		if err != nil {
+			return err
+		}
+		if err := uc.topicRepo.RefreshStats(ctx, sourceID, targetID); err != nil {
+			return err
+		}
+		if leaveRedirect {
+			err = uc.topicRepo.MarkRedirect(ctx, sourceID, targetID, now)
+		} else {
+			err = uc.topicRepo.Delete(ctx, sourceID)
+		}
+		if err != nil {
+			return err
+		}
+		if source.Status.Published() {
+			if err := uc.outboxRepo.Add(ctx, topicEventWith(entity.EventTopicMerged, source, map[string]any{
+				"target_topic_id":    targetID,
+				"target_category_id": target.CategoryID,
+				"moved_posts":        moved,
+				"redirect":           leaveRedirect,
+			})); err != nil {
+				return err
+			}
+		}
+		return uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    moderatorID,
+			Action:     entity.AuditActionTopicMerge,
+			TargetType: entity.AuditTargetTopic,
+			TargetID:   targetID,
+			Reason:     reason,
+			Details: map[string]any{
+				"source_topic_id":    sourceID,
+				"source_title":       source.Title,
+				"source_category_id": source.CategoryID,
+				"moved_posts":        moved,
+				"redirect":           leaveRedirect,
+			},
+			CreatedAt: now,
+		})
+	})
+	if err != nil {
+		uc.logger.Error("failed to merge topics",
+			slog.Int64("source_id", sourceID),
+			slog.Int64("target_id", target.ID),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	return uc.topicRepo.GetByID(ctx, targetID)
+}
+
+func (uc *topicUseCase) SplitTopic(ctx context.Context, sourceID int64, postIDs []int64, title string, categoryID, moderatorID int64, reason string) (*entity.Topic, error) {
+	title = strings.TrimSpace(title)
+	if title == "" || len(postIDs) == 0 {
+		return nil, ErrInvalidSplit
+	}
+	source, err := uc.getMovableTopic(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if categoryID == 0 {
+		categoryID = source.CategoryID
+	} else if _, err := uc.categoryRepo.GetByID(ctx, categoryID); err != nil {
+		uc.logger.Warn("category not found", slog.Int64("category_id", categoryID))
+		return nil, ErrCategoryNotFound
+	}
+
+	// Автором, датой и статусом нового топика становится самый ранний из выделенных постов —
+	// тот же, что RefreshStats назначит первым: пост на премодерации не должен открыть видимый топик
+	var first *entity.Post
+	for _, postID := range postIDs {
+		post, err := uc.postRepo.GetByID(ctx, postID)
+		if err != nil {
+			return nil, ErrPostNotFound
+		}
+		if post.TopicID != sourceID {
+			return nil, ErrInvalidSplit
+		}
+		if first == nil || post.CreatedAt.Before(first.CreatedAt) ||
+			post.CreatedAt.Equal(first.CreatedAt) && post.ID < first.ID {
+			first = post
+		}
+	}
+
+	topic := &entity.Topic{
+		Title:          title,
+		AuthorID:       first.AuthorID,
+		AuthorNickname: first.AuthorNickname,
+		CategoryID:     categoryID,
+		CreatedAt:      first.CreatedAt,
+		LastActivity:   first.CreatedAt,
+		Status:         first.Status,
+	}
+
+	now := time.Now().UTC()
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.topicRepo.Create(ctx, topic); err != nil {
+			return err
+		}
+		moved, err := uc.topicRepo.MovePosts(ctx, sourceID, topic.ID, postIDs)
+		if err != nil {
+			return err
+		}
+		if err := uc.topicRepo.RefreshStats(ctx, sourceID, topic.ID); err != nil {
+			return err
+		}
+
+		// Исходный топик не должен остаться пустым — считаются и скрытые, и ожидающие модерации посты
+		hasPosts, err := uc.topicRepo.HasPosts(ctx, sourceID)
+		if err != nil {
+			return err
+		}
+		if !hasPosts {
+			return ErrInvalidSplit
+		}
+		// Топик, выделенный из поста на премодерации, в событиях появится только после одобрения
+		if source.Status.Published() && topic.Status.Published() {
+			if err := uc.outboxRepo.Add(ctx, topicEventWith(entity.EventTopicSplit, topic, map[string]any{
+				"source_topic_id": sourceID,
+				"post_ids":        postIDs,
+				"moved_posts":     moved,
+			})); err != nil {
+				return err
+			}
+		}
+
+		return uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    moderatorID,
+			Action:     entity.AuditActionTopicSplit,
+			TargetType: entity.AuditTargetTopic,
+			TargetID:   sourceID,
+			Reason:     reason,
+			Details: map[string]any{
+				"new_topic_id": topic.ID,
+				"post_ids":     postIDs,
+				"moved_posts":  moved,
+				"category_id":  categoryID,
+			},
+			CreatedAt: now,
+		})
+	})
+	if err != nil {
+		uc.logger.Error("failed to split topic",
+			slog.Int64("source_id", source.ID),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	return uc.topicRepo.GetByID(ctx, topic.ID)
+}
+
+// checkTopicOpen — закрытый топик (модератором или по неактивности) правят только модераторы:
+// и сам топик, и его посты, комментарии и теги.
+func checkTopicOpen(topic *entity.Topic, actor entity.Viewer) error {
+	if topic.IsLocked() && !actor.Moderator {
+		return ErrTopicLocked
+	}
+	return nil
+}
+
+// openTopic загружает топик, в котором actor меняет контент, и проверяет его checkTopicOpen.
+func openTopic(ctx context.Context, topicRepo repository.TopicRepository, topicID int64, actor entity.Viewer) (*entity.Topic, error) {
+	topic, err := topicRepo.GetByID(ctx, topicID)
+	if err != nil {
+		return nil, ErrTopicNotFound
+	}
+	if err := checkTopicOpen(topic, actor); err != nil {
+		return nil, err
+	}
+	return topic, nil
+}
+
+// getMovableTopic возвращает топик, если его можно переносить, объединять или разделять.
+func (uc *topicUseCase) getMovableTopic(ctx context.Context, id int64) (*entity.Topic, error) {
+	topic, err := uc.topicRepo.GetByID(ctx, id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrTopicNotFound
+		}
+		return nil, err
+	}
+	if topic.IsRedirect() {
+		return nil, ErrInvalidTopicMove
+	}
+	return topic, nil
+}
+
+func (uc *topicUseCase) AcceptAnswer(ctx context.Context, topicID, postID int64, actor entity.Viewer) (*entity.Topic, error) {
+	if actor.UserID <= 0 {
+		return nil, ErrUnauthenticated
+	}
+	topic, first, err := uc.topicRepo.GetByIDWithFirstPost(ctx, topicID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrTopicNotFound
+		}
+		return nil, err
+	}
+	if !actor.CanSee(topic.AuthorID, topic.Status) {
+		return nil, ErrTopicNotFound
+	}
+	if topic.AuthorID != actor.UserID && !actor.Moderator {
+		return nil, fmt.Errorf("%w: only the topic author or a moderator can accept an answer", ErrForbidden)
+	}
+	if postID != 0 {
+		post, err := uc.postRepo.GetByID(ctx, postID)
+		switch {
+		case err != nil || post.TopicID != topicID:
+			return nil, ErrPostNotFound
+		case post.ID == first.ID:
+			return nil, fmt.Errorf("%w: the first post is the question itself", ErrInvalidAnswer)
+		case post.Status != entity.StatusActive:
+			return nil, fmt.Errorf("%w: post is not published", ErrInvalidAnswer)
+		}
+	}
+	if topic.AcceptedPostID == postID {
+		return topic, nil
+	}
+	topic.AcceptedPostID = postID
+
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.topicRepo.SetAcceptedPost(ctx, topicID, postID); err != nil {
+			return err
+		}
+		if !topic.Status.Published() {
+			return nil
+		}
+		return uc.outboxRepo.Add(ctx, topicEvent(entity.EventTopicUpdated, topic))
+	})
+	if err != nil {
+		uc.logger.Error("failed to accept answer", slog.Int64("topicID", topicID), slog.String("err", err.Error()))
+		return nil, err
+	}
+	return topic, nil
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	forumv1 "github.com/VaneZ444/golang-forum-protos/gen/go/forum"
+)
+
+func TestUpdateTopicRespectsLock(t *testing.T) {
+	const authorID = 1
+	lockedAt := time.Now()
+
+	tests := []struct {
+		name     string
+		lockedAt *time.Time
+		actor    entity.Viewer
+		want     error
+	}{
+		{name: "open topic", actor: entity.Viewer{UserID: authorID}, want: ErrUserBanned},
+		{name: "locked topic", lockedAt: &lockedAt, actor: entity.Viewer{UserID: authorID}, want: ErrTopicLocked},
+		{name: "locked topic, moderator", lockedAt: &lockedAt, actor: entity.Viewer{UserID: 2, Moderator: true}, want: ErrUserBanned},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &topicUseCase{
+				topicRepo: &fakeTopicRepo{topic: &entity.Topic{ID: 20, AuthorID: authorID, LockedAt: tt.lockedAt}},
+				// Проверка санкций идёт сразу после проверки блокировки и останавливает правку
+				guard:  &fakeGuard{err: ErrUserBanned},
+				logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+			}
+			_, err := uc.UpdateTopic(context.Background(), &entity.Topic{ID: 20, Title: "new title"}, tt.actor)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("UpdateTopic() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// В закрытом топике посты, комментарии и теги меняют только модераторы.
+func TestLockedTopicContent(t *testing.T) {
+	const authorID = 1
+	lockedAt := time.Now()
+	errStop := errors.New("stop before write")
+
+	ops := map[string]func(actor entity.Viewer) error{
+		"update post": func(actor entity.Viewer) error {
+			content := "new content"
+			_, err := newLockedPostUC(lockedAt, errStop).UpdatePost(context.Background(), &forumv1.UpdatePostRequest{Id: 10, Content: &content}, 0, actor, "")
+			return err
+		},
+		"delete post": func(actor entity.Viewer) error {
+			return newLockedPostUC(lockedAt, errStop).DeletePost(context.Background(), 10, actor)
+		},
+		"update comment": func(actor entity.Viewer) error {
+			return newLockedCommentUC(lockedAt, errStop).UpdateComment(context.Background(), &entity.Comment{ID: 30, PostID: 10, Content: "new"}, actor, "")
+		},
+		"delete comment": func(actor entity.Viewer) error {
+			return newLockedCommentUC(lockedAt, errStop).DeleteComment(context.Background(), 30, actor)
+		},
+		"remove tag": func(actor entity.Viewer) error {
+			uc := &tagUseCase{
+				postRepo:  &fakePostRepo{post: &entity.Post{ID: 10, TopicID: 20, AuthorID: authorID}},
+				topicRepo: &fakeTopicRepo{topic: &entity.Topic{ID: 20, LockedAt: &lockedAt}},
+				tx:        &fakeTx{err: errStop},
+				logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+			}
+			return uc.RemoveTagFromPost(context.Background(), 10, 5, actor)
+		},
+	}
+	actors := []struct {
+		name       string
+		actor      entity.Viewer
+		wantLocked bool
+	}{
+		{name: "author", actor: entity.Viewer{UserID: authorID}, wantLocked: true},
+		{name: "moderator", actor: entity.Viewer{UserID: 2, Moderator: true}},
+	}
+	for name, op := range ops {
+		for _, a := range actors {
+			t.Run(name+"/"+a.name, func(t *testing.T) {
+				err := op(a.actor)
+				if errors.Is(err, ErrTopicLocked) != a.wantLocked {
+					t.Fatalf("error = %v, want locked = %v", err, a.wantLocked)
+				}
+			})
+		}
+	}
+}
+
+func newLockedPostUC(lockedAt time.Time, stop error) *postUseCase {
+	return &postUseCase{
+		postRepo:  &fakePostRepo{post: &entity.Post{ID: 10, TopicID: 20, AuthorID: 1, Version: 1}},
+		topicRepo: &fakeTopicRepo{topic: &entity.Topic{ID: 20, LockedAt: &lockedAt}},
+		guard:     &fakeGuard{err: stop},
+		tx:        &fakeTx{err: stop},
+		logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+func newLockedCommentUC(lockedAt time.Time, stop error) *commentUseCase {
+	return &commentUseCase{
+		commentRepo: &fakeCommentRepo{comment: &entity.Comment{ID: 30, PostID: 10, AuthorID: 1, Version: 1}},
+		postRepo:    &fakePostRepo{post: &entity.Post{ID: 10, TopicID: 20}},
+		topicRepo:   &fakeTopicRepo{topic: &entity.Topic{ID: 20, LockedAt: &lockedAt}},
+		guard:       &fakeGuard{err: stop},
+		tx:          &fakeTx{err: stop},
+		logger:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+// TopicViewCounter копит просмотры топиков в памяти и пишет их в topics.views_count пачками,
+// чтобы популярный топик не блокировал строку на каждом чтении. Дедупликация общая для всех реплик:
+// окна фиксированные, как у просмотров постов, а отметка зрителя в окне записывается в topic_views один раз.
+type TopicViewCounter interface {
+	// RecordView учитывает просмотр, если этот зритель не смотрел топик в текущем окне дедупликации.
+	// Боты и неопознанные зрители не учитываются.
+	RecordView(topicID int64, viewer entity.Viewer)
+	Flush(ctx context.Context) error
+}
+
+type topicViewCounter struct {
+	topicRepo repository.TopicRepository
+	window    time.Duration
+	logger    *slog.Logger
+
+	mu      sync.Mutex
+	pending map[entity.TopicView]struct{} // повтор в том же окне на этой реплике схлопывается ещё до базы
+}
+
+func NewTopicViewCounter(topicRepo repository.TopicRepository, window time.Duration, logger *slog.Logger) TopicViewCounter {
+	return &topicViewCounter{
+		topicRepo: topicRepo,
+		window:    window,
+		logger:    logger,
+		pending:   make(map[entity.TopicView]struct{}),
+	}
+}
+
+func (c *topicViewCounter) RecordView(topicID int64, viewer entity.Viewer) {
+	if isBot(viewer.UserAgent) {
+		return
+	}
+	hash := viewerHash(viewer)
+	if hash == "" {
+		return
+	}
+
+	view := entity.TopicView{TopicID: topicID, ViewerHash: hash, Bucket: c.bucket(time.Now())}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending[view] = struct{}{}
+}
+
+func (c *topicViewCounter) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	batch := c.pending
+	c.pending = make(map[entity.TopicView]struct{})
+	c.mu.Unlock()
+
+	if len(batch) > 0 {
+		views := make([]entity.TopicView, 0, len(batch))
+		for v := range batch {
+			views = append(views, v)
+		}
+		if err := c.topicRepo.AddViews(ctx, views); err != nil {
+			// Возвращаем отметки в буфер, чтобы записать их следующим flush
+			c.mu.Lock()
+			for v := range batch {
+				c.pending[v] = struct{}{}
+			}
+			c.mu.Unlock()
+			return err
+		}
+		c.logger.Debug("topic views flushed", slog.Int("views", len(views)))
+	}
+
+	// Предыдущее окно оставляем: другие реплики ещё могут дописывать его отметки
+	return c.topicRepo.DeleteViewsBefore(ctx, c.bucket(time.Now())-1)
+}
+
+// bucket — номер окна дедупликации по той же формуле, что у просмотров постов; часы реплик считаются синхронными.
+func (c *topicViewCounter) bucket(t time.Time) int64 {
+	return t.Unix() / max(int64(c.window.Seconds()), 1)
+}
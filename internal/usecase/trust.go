@@ -0,0 +1,242 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+type TrustConfig struct {
+	LikeWeight     int64         // очков за каждый лайк на посте
+	AcceptedWeight int64         // за ответ, принятый автором чужого топика
+	SurvivedWeight int64         // за пост, продержавшийся SurvivalPeriod
+	UpheldPenalty  int64         // штраф за каждую подтверждённую модератором жалобу
+	SurvivalPeriod time.Duration // столько пост должен провисеть опубликованным, чтобы учитываться
+	// LevelScores — минимальная репутация для уровней Basic, Member, Regular и Leader
+	LevelScores [entity.TrustLeader]int64
+	Privileges  map[entity.Privilege]entity.TrustLevel
+	FlagWeight  int // вес жалобы пользователя с привилегией weighted_flags; у остальных — 1
+}
+
+func DefaultTrustConfig() TrustConfig {
+	return TrustConfig{
+		LikeWeight:     1,
+		AcceptedWeight: 15,
+		SurvivedWeight: 2,
+		UpheldPenalty:  10,
+		SurvivalPeriod: 24 * time.Hour,
+		LevelScores:    [entity.TrustLeader]int64{5, 50, 200, 1000},
+		Privileges: map[entity.Privilege]entity.TrustLevel{
+			entity.PrivilegePostLinks:     entity.TrustBasic,
+			entity.PrivilegeCreateTags:    entity.TrustMember,
+			entity.PrivilegeEditWiki:      entity.TrustRegular,
+			entity.PrivilegeWeightedFlags: entity.TrustRegular,
+		},
+		FlagWeight: 2,
+	}
+}
+
+// maxTrustReason — ограничение на причину ручного назначения уровня, в символах.
+const maxTrustReason = 500
+
+type TrustUseCase interface {
+	// Reputation считает репутацию на лету: данных немного, а кэш пришлось бы сбрасывать при каждом лайке и решении модератора.
+	Reputation(ctx context.Context, userID int64) (*entity.Reputation, error)
+	// Allows — открыта ли привилегия пользователю. При ошибке хранилища отказывает:
+	// привилегии снимают ограничения, и выдавать их вслепую нельзя.
+	Allows(ctx context.Context, userID int64, p entity.Privilege) bool
+	// Require — то же, что Allows, но с ошибкой ErrInsufficientTrust для клиента.
+	Require(ctx context.Context, userID int64, p entity.Privilege) error
+	// FlagWeight — с каким весом учитывать жалобу пользователя.
+	FlagWeight(ctx context.Context, userID int64) int
+	// SetOverride закрепляет за пользователем уровень независимо от репутации; пишется в журнал аудита.
+	SetOverride(ctx context.Context, userID int64, level entity.TrustLevel, reason string, actorID int64) (*entity.Reputation, error)
+	ClearOverride(ctx context.Context, userID, actorID int64) (*entity.Reputation, error)
+}
+
+type trustUseCase struct {
+	trustRepo repository.TrustRepository
+	auditRepo repository.AuditRepository
+	tx        repository.Transactor
+	cfg       TrustConfig
+	logger    *slog.Logger
+}
+
+func NewTrustUseCase(
+	trustRepo repository.TrustRepository,
+	auditRepo repository.AuditRepository,
+	tx repository.Transactor,
+	cfg TrustConfig,
+	logger *slog.Logger,
+) TrustUseCase {
+	return &trustUseCase{
+		trustRepo: trustRepo,
+		auditRepo: auditRepo,
+		tx:        tx,
+		cfg:       cfg,
+		logger:    logger,
+	}
+}
+
+func (uc *trustUseCase) Reputation(ctx context.Context, userID int64) (*entity.Reputation, error) {
+	if userID <= 0 {
+		return nil, ErrUnauthenticated
+	}
+	stats, err := uc.trustRepo.Stats(ctx, userID, time.Now().UTC().Add(-uc.cfg.SurvivalPeriod))
+	if err != nil {
+		return nil, err
+	}
+	override, err := uc.trustRepo.GetOverride(ctx, userID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	rep := &entity.Reputation{
+		UserID: userID,
+		Stats:  stats,
+		Score: stats.LikesReceived*uc.cfg.LikeWeight +
+			stats.AcceptedAnswers*uc.cfg.AcceptedWeight +
+			stats.SurvivedPosts*uc.cfg.SurvivedWeight -
+			stats.UpheldReports*uc.cfg.UpheldPenalty,
+		Override: override,
+	}
+	for i, score := range uc.cfg.LevelScores {
+		if rep.Score >= score {
+			rep.ComputedLevel = entity.TrustLevel(i + 1)
+		}
+	}
+	rep.Level = rep.ComputedLevel
+	if override != nil {
+		rep.Level = override.Level
+	}
+	return rep, nil
+}
+
+func (uc *trustUseCase) Allows(ctx context.Context, userID int64, p entity.Privilege) bool {
+	required, ok := uc.cfg.Privileges[p]
+	if !ok || required <= entity.TrustNew {
+		return true
+	}
+	if userID <= 0 {
+		return false
+	}
+	rep, err := uc.Reputation(ctx, userID)
+	if err != nil {
+		uc.logger.Warn("failed to compute reputation", slog.Int64("userID", userID), slog.String("err", err.Error()))
+		return false
+	}
+	return rep.Level >= required
+}
+
+func (uc *trustUseCase) Require(ctx context.Context, userID int64, p entity.Privilege) error {
+	if uc.Allows(ctx, userID, p) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s requires trust level %s", ErrInsufficientTrust, p, uc.cfg.Privileges[p])
+}
+
+func (uc *trustUseCase) FlagWeight(ctx context.Context, userID int64) int {
+	if uc.cfg.FlagWeight > 1 && uc.Allows(ctx, userID, entity.PrivilegeWeightedFlags) {
+		return uc.cfg.FlagWeight
+	}
+	return 1
+}
+
+func (uc *trustUseCase) SetOverride(ctx context.Context, userID int64, level entity.TrustLevel, reason string, actorID int64) (*entity.Reputation, error) {
+	if actorID <= 0 {
+		return nil, ErrUnauthenticated
+	}
+	reason = strings.TrimSpace(reason)
+	switch {
+	case userID <= 0:
+		return nil, fmt.Errorf("%w: user_id is required", ErrInvalidTrustLevel)
+	case !level.Valid():
+		return nil, fmt.Errorf("%w: unknown level %d", ErrInvalidTrustLevel, level)
+	case reason == "":
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidTrustLevel)
+	case len([]rune(reason)) > maxTrustReason:
+		return nil, fmt.Errorf("%w: reason is longer than %d characters", ErrInvalidTrustLevel, maxTrustReason)
+	}
+
+	now := time.Now().UTC()
+	o := &entity.TrustOverride{UserID: userID, Level: level, Reason: reason, SetBy: actorID, SetAt: now}
+	err := uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		prev, err := uc.trustRepo.GetOverride(ctx, userID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		if err := uc.trustRepo.SetOverride(ctx, o); err != nil {
+			return err
+		}
+		return uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    actorID,
+			Action:     entity.AuditActionTrustOverride,
+			TargetType: entity.AuditTargetUser,
+			TargetID:   userID,
+			Reason:     reason,
+			Before:     trustOverrideFields(prev),
+			After:      trustOverrideFields(o),
+			CreatedAt:  now,
+		})
+	})
+	if err != nil {
+		uc.logger.Error("failed to set trust level", slog.Int64("userID", userID), slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	uc.logger.Info("trust level overridden",
+		slog.Int64("userID", userID),
+		slog.String("level", level.String()),
+		slog.Int64("actorID", actorID),
+	)
+	return uc.Reputation(ctx, userID)
+}
+
+func (uc *trustUseCase) ClearOverride(ctx context.Context, userID, actorID int64) (*entity.Reputation, error) {
+	if actorID <= 0 {
+		return nil, ErrUnauthenticated
+	}
+	err := uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		prev, err := uc.trustRepo.GetOverride(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if err := uc.trustRepo.DeleteOverride(ctx, userID); err != nil {
+			return err
+		}
+		return uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    actorID,
+			Action:     entity.AuditActionTrustClear,
+			TargetType: entity.AuditTargetUser,
+			TargetID:   userID,
+			Before:     trustOverrideFields(prev),
+			CreatedAt:  time.Now().UTC(),
+		})
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrTrustOverrideNotFound
+	}
+	if err != nil {
+		uc.logger.Error("failed to clear trust level", slog.Int64("userID", userID), slog.String("err", err.Error()))
+		return nil, err
+	}
+	return uc.Reputation(ctx, userID)
+}
+
+func trustOverrideFields(o *entity.TrustOverride) map[string]any {
+	if o == nil {
+		return nil
+	}
+	return map[string]any{
+		"user_id": o.UserID,
+		"level":   o.Level.String(),
+		"reason":  o.Reason,
+		"set_by":  o.SetBy,
+	}
+}
@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/VaneZ444/forum-service/internal/repository"
+)
+
+// checkVersion сверяет ожидаемую клиентом версию с прочитанной. 0 — клиент версию не передал,
+// правка применяется поверх (last write wins), как до появления версий.
+func checkVersion(expected, current int64) error {
+	if expected != 0 && expected != current {
+		return fmt.Errorf("%w: expected version %d, current is %d", ErrVersionMismatch, expected, current)
+	}
+	return nil
+}
+
+// versionConflict переводит ErrConflict репозитория — запись изменили между чтением и записью — в ErrVersionMismatch.
+func versionConflict(err error) error {
+	if errors.Is(err, repository.ErrConflict) {
+		return fmt.Errorf("%w: changed while the edit was being saved", ErrVersionMismatch)
+	}
+	return err
+}
@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+)
+
+// botUserAgents — подстроки user-agent, просмотры с которыми не учитываются.
+var botUserAgents = []string{
+	"bot", "crawler", "spider", "slurp", "crawl", "preview",
+	"curl", "wget", "python-requests", "go-http-client", "headless",
+}
+
+func isBot(userAgent string) bool {
+	ua := strings.ToLower(userAgent)
+	for _, marker := range botUserAgents {
+		if strings.Contains(ua, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// viewerHash возвращает стабильный ключ зрителя для дедупликации просмотров.
+// Сырые session_id и IP не сохраняются. Пустая строка — зрителя не опознать.
+func viewerHash(v entity.Viewer) string {
+	var key string
+	switch {
+	case v.UserID > 0:
+		key = "u:" + strconv.FormatInt(v.UserID, 10)
+	case v.SessionID != "":
+		key = "s:" + v.SessionID
+	case v.ClientIP != "":
+		key = "f:" + v.ClientIP + "|" + v.UserAgent
+	default:
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,531 @@
+package usecase
+
+import (
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/events"
+	"github.com/VaneZ444/forum-service/internal/repository"
+	"github.com/VaneZ444/forum-service/internal/webhook"
+)
+
+type WebhookConfig struct {
+	MaxAttempts int           // после стольких неудач доставка получает статус failed
+	BaseBackoff time.Duration // пауза после первой неудачи, дальше удваивается
+	MaxBackoff  time.Duration
+	DeadAfter   int // подряд идущих неудач, после которых вебхук уходит в dead-letter
+	BatchSize   int
+	Concurrency int
+	Lease       time.Duration // на сколько откладывается взятая в работу доставка
+}
+
+func DefaultWebhookConfig() WebhookConfig {
+	return WebhookConfig{
+		MaxAttempts: 8,
+		BaseBackoff: 30 * time.Second,
+		MaxBackoff:  6 * time.Hour,
+		DeadAfter:   50,
+		BatchSize:   50,
+		Concurrency: 8,
+		Lease:       time.Minute,
+	}
+}
+
+type WebhookUseCase interface {
+	// Sink ставит доставки в очередь для событий из outbox.
+	events.Sink
+	CreateWebhook(ctx context.Context, w *entity.Webhook) (*entity.Webhook, error)
+	// UpdateWebhook, DeleteWebhook и ReactivateWebhook принимают actorID для журнала аудита.
+	UpdateWebhook(ctx context.Context, w *entity.Webhook, actorID int64) (*entity.Webhook, error)
+	DeleteWebhook(ctx context.Context, id, actorID int64) error
+	GetWebhook(ctx context.Context, id int64) (*entity.Webhook, error)
+	ListWebhooks(ctx context.Context, limit, offset int) ([]*entity.Webhook, int64, error)
+	ReactivateWebhook(ctx context.Context, id, actorID int64) (*entity.Webhook, error)
+	ListDeliveries(ctx context.Context, webhookID int64, status entity.DeliveryStatus, limit, offset int) ([]*entity.WebhookDelivery, int64, error)
+	// ReplayDelivery ставит доставку в очередь заново, в каком бы статусе она ни была.
+	ReplayDelivery(ctx context.Context, deliveryID int64) error
+	// DeliverDue отправляет доставки, у которых подошло время попытки.
+	DeliverDue(ctx context.Context) error
+	// CleanupDeliveries удаляет доставленные и исчерпавшие попытки доставки старше retention.
+	// Ожидающие и остановленные dead-letter остаются: их ещё можно отправить.
+	CleanupDeliveries(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+// webhookCleanupBatch — сколько доставок удаляется за один запрос.
+const webhookCleanupBatch = 5000
+
+type webhookUseCase struct {
+	webhookRepo repository.WebhookRepository
+	topicRepo   repository.TopicRepository
+	postRepo    repository.PostRepository
+	auditRepo   repository.AuditRepository
+	tx          repository.Transactor
+	sender      *webhook.Sender
+	cfg         WebhookConfig
+	logger      *slog.Logger
+}
+
+func NewWebhookUseCase(
+	webhookRepo repository.WebhookRepository,
+	topicRepo repository.TopicRepository,
+	postRepo repository.PostRepository,
+	auditRepo repository.AuditRepository,
+	tx repository.Transactor,
+	sender *webhook.Sender,
+	cfg WebhookConfig,
+	logger *slog.Logger,
+) WebhookUseCase {
+	return &webhookUseCase{
+		webhookRepo: webhookRepo,
+		topicRepo:   topicRepo,
+		postRepo:    postRepo,
+		auditRepo:   auditRepo,
+		tx:          tx,
+		sender:      sender,
+		cfg:         cfg,
+		logger:      logger,
+	}
+}
+
+func (uc *webhookUseCase) CreateWebhook(ctx context.Context, w *entity.Webhook) (*entity.Webhook, error) {
+	if err := validateWebhook(w); err != nil {
+		return nil, err
+	}
+	if w.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		w.Secret = secret
+	}
+	w.Active = true
+	w.CreatedAt = time.Now().UTC()
+
+	err := uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.webhookRepo.Create(ctx, w); err != nil {
+			return err
+		}
+		return uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    w.CreatedBy,
+			Action:     entity.AuditActionWebhookCreate,
+			TargetType: entity.AuditTargetWebhook,
+			TargetID:   w.ID,
+			After:      webhookFields(w),
+			CreatedAt:  w.CreatedAt,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	uc.logger.Info("webhook created",
+		slog.Int64("id", w.ID),
+		slog.Int64("created_by", w.CreatedBy),
+		slog.String("url", w.URL),
+	)
+	return w, nil
+}
+
+func (uc *webhookUseCase) UpdateWebhook(ctx context.Context, w *entity.Webhook, actorID int64) (*entity.Webhook, error) {
+	existing, err := uc.GetWebhook(ctx, w.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateWebhook(w); err != nil {
+		return nil, err
+	}
+	if w.Secret == "" {
+		w.Secret = existing.Secret
+	}
+	w.UpdatedAt = time.Now().UTC()
+
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.webhookRepo.Update(ctx, w); err != nil {
+			return err
+		}
+		return uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    actorID,
+			Action:     entity.AuditActionWebhookUpdate,
+			TargetType: entity.AuditTargetWebhook,
+			TargetID:   w.ID,
+			Before:     webhookFields(existing),
+			After:      webhookFields(w),
+			Details:    map[string]any{"secret_rotated": w.Secret != existing.Secret},
+			CreatedAt:  w.UpdatedAt,
+		})
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return uc.GetWebhook(ctx, w.ID)
+}
+
+func (uc *webhookUseCase) DeleteWebhook(ctx context.Context, id, actorID int64) error {
+	existing, err := uc.GetWebhook(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.webhookRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    actorID,
+			Action:     entity.AuditActionWebhookDelete,
+			TargetType: entity.AuditTargetWebhook,
+			TargetID:   id,
+			Before:     webhookFields(existing),
+			CreatedAt:  time.Now().UTC(),
+		})
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrWebhookNotFound
+	}
+	return err
+}
+
+func (uc *webhookUseCase) GetWebhook(ctx context.Context, id int64) (*entity.Webhook, error) {
+	w, err := uc.webhookRepo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrWebhookNotFound
+	}
+	return w, err
+}
+
+func (uc *webhookUseCase) ListWebhooks(ctx context.Context, limit, offset int) ([]*entity.Webhook, int64, error) {
+	limit, offset = normalizePage(limit, offset)
+	return uc.webhookRepo.List(ctx, limit, offset)
+}
+
+func (uc *webhookUseCase) ReactivateWebhook(ctx context.Context, id, actorID int64) (*entity.Webhook, error) {
+	now := time.Now().UTC()
+	err := uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.webhookRepo.Reactivate(ctx, id, now); err != nil {
+			return err
+		}
+		return uc.auditRepo.Create(ctx, &entity.AuditEntry{
+			ActorID:    actorID,
+			Action:     entity.AuditActionWebhookReactivate,
+			TargetType: entity.AuditTargetWebhook,
+			TargetID:   id,
+			CreatedAt:  now,
+		})
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	uc.logger.Info("webhook reactivated", slog.Int64("id", id))
+	return uc.GetWebhook(ctx, id)
+}
+
+func (uc *webhookUseCase) ListDeliveries(ctx context.Context, webhookID int64, status entity.DeliveryStatus, limit, offset int) ([]*entity.WebhookDelivery, int64, error) {
+	if _, err := uc.GetWebhook(ctx, webhookID); err != nil {
+		return nil, 0, err
+	}
+	limit, offset = normalizePage(limit, offset)
+	return uc.webhookRepo.ListDeliveries(ctx, webhookID, status, limit, offset)
+}
+
+func (uc *webhookUseCase) ReplayDelivery(ctx context.Context, deliveryID int64) error {
+	d, err := uc.webhookRepo.GetDelivery(ctx, deliveryID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		return err
+	}
+	w, err := uc.GetWebhook(ctx, d.WebhookID)
+	if err != nil {
+		return err
+	}
+	if w.IsDead() {
+		return ErrWebhookDead
+	}
+	return uc.webhookRepo.ResetDelivery(ctx, deliveryID, time.Now().UTC())
+}
+
+func (uc *webhookUseCase) Name() string {
+	return "webhooks"
+}
+
+// Deliver раскладывает события по подходящим вебхукам. Сама отправка — в DeliverDue.
+func (uc *webhookUseCase) Deliver(ctx context.Context, batch []*entity.Event) error {
+	hooks, err := uc.webhookRepo.ListDeliverable(ctx)
+	if err != nil || len(hooks) == 0 {
+		return err
+	}
+
+	now := time.Now().UTC()
+	resolver := newCategoryResolver(uc.topicRepo, uc.postRepo)
+	var deliveries []*entity.WebhookDelivery
+	for _, e := range batch {
+		categoryID := resolver.resolve(ctx, e)
+		body, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event %d: %w", e.ID, err)
+		}
+		for _, w := range hooks {
+			if !w.Matches(e.Type, categoryID) {
+				continue
+			}
+			deliveries = append(deliveries, &entity.WebhookDelivery{
+				WebhookID: w.ID,
+				EventID:   e.ID,
+				EventType: e.Type,
+				Payload:   body,
+				CreatedAt: now,
+			})
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return uc.webhookRepo.EnqueueDeliveries(ctx, deliveries)
+}
+
+func (uc *webhookUseCase) DeliverDue(ctx context.Context) error {
+	now := time.Now().UTC()
+	due, err := uc.webhookRepo.ClaimDue(ctx, now, now.Add(uc.cfg.Lease), uc.cfg.BatchSize)
+	if err != nil || len(due) == 0 {
+		return err
+	}
+
+	hooks := make(map[int64]*entity.Webhook)
+	for _, d := range due {
+		if _, ok := hooks[d.WebhookID]; ok {
+			continue
+		}
+		w, err := uc.webhookRepo.GetByID(ctx, d.WebhookID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		hooks[d.WebhookID] = w
+	}
+
+	sem := make(chan struct{}, max(uc.cfg.Concurrency, 1))
+	var wg sync.WaitGroup
+	for _, d := range due {
+		w := hooks[d.WebhookID]
+		if w == nil {
+			continue // вебхук удалён, доставки уйдут каскадом
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			uc.attempt(ctx, w, d)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+func (uc *webhookUseCase) attempt(ctx context.Context, w *entity.Webhook, d *entity.WebhookDelivery) {
+	log := uc.logger.With(slog.Int64("webhook_id", w.ID), slog.Int64("delivery_id", d.ID))
+
+	if !w.Active || w.IsDead() {
+		// Выключен после постановки в очередь — ждём, не тратя попытки
+		d.NextAttemptAt = time.Now().UTC().Add(uc.cfg.MaxBackoff)
+		if err := uc.webhookRepo.SaveDeliveryResult(ctx, d); err != nil {
+			log.Error("failed to postpone webhook delivery", slog.String("err", err.Error()))
+		}
+		return
+	}
+
+	code, sendErr := uc.sender.Send(ctx, webhook.Request{
+		URL:        w.URL,
+		Secret:     w.Secret,
+		EventType:  string(d.EventType),
+		DeliveryID: d.ID,
+		Body:       d.Payload,
+	})
+
+	now := time.Now().UTC()
+	d.Attempts++
+	d.LastStatusCode = code
+	deadAfter := uc.cfg.DeadAfter
+	if sendErr == nil {
+		d.Status = entity.DeliveryDelivered
+		d.LastError = ""
+		d.DeliveredAt = &now
+	} else {
+		d.LastError = sendErr.Error()
+		if d.Attempts >= uc.cfg.MaxAttempts {
+			d.Status = entity.DeliveryFailed
+		} else {
+			d.NextAttemptAt = now.Add(uc.backoff(d.Attempts))
+		}
+		if code == http.StatusGone {
+			// 410 — получатель просит больше не присылать
+			deadAfter = 1
+		}
+	}
+
+	if err := uc.webhookRepo.SaveDeliveryResult(ctx, d); err != nil {
+		log.Error("failed to save webhook delivery", slog.String("err", err.Error()))
+		return
+	}
+	dead, err := uc.webhookRepo.RecordResult(ctx, w.ID, sendErr == nil, deadAfter, now)
+	if err != nil {
+		log.Error("failed to record webhook result", slog.String("err", err.Error()))
+		return
+	}
+
+	if sendErr != nil {
+		log.Warn("webhook delivery failed",
+			slog.Int("attempt", d.Attempts),
+			slog.Int("status_code", code),
+			slog.String("err", sendErr.Error()),
+		)
+	}
+	if dead {
+		log.Warn("webhook moved to dead-letter", slog.String("url", w.URL))
+	}
+}
+
+func (uc *webhookUseCase) CleanupDeliveries(ctx context.Context, retention time.Duration) (int64, error) {
+	before := time.Now().UTC().Add(-retention)
+
+	var total int64
+	for {
+		n, err := uc.webhookRepo.DeleteFinishedBefore(ctx, before, webhookCleanupBatch)
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if n < webhookCleanupBatch {
+			break
+		}
+	}
+
+	if total > 0 {
+		uc.logger.Info("old webhook deliveries removed", slog.Int64("rows", total))
+	}
+	return total, nil
+}
+
+// backoff — экспоненциальная пауза перед попыткой attempt+1 с разбросом ±10%.
+func (uc *webhookUseCase) backoff(attempt int) time.Duration {
+	d := uc.cfg.BaseBackoff << min(attempt-1, 30)
+	if d <= 0 || d > uc.cfg.MaxBackoff {
+		d = uc.cfg.MaxBackoff
+	}
+	jitter := time.Duration(rand.Int64N(int64(d)/5+1)) - d/10
+	return d + jitter
+}
+
+// webhookFields — снимок вебхука для журнала аудита, без секрета.
+func webhookFields(w *entity.Webhook) map[string]any {
+	return map[string]any{
+		"id":           w.ID,
+		"url":          w.URL,
+		"event_types":  w.EventTypes,
+		"category_ids": w.CategoryIDs,
+		"active":       w.Active,
+	}
+}
+
+func validateWebhook(w *entity.Webhook) error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidWebhook)
+	}
+	for _, t := range w.EventTypes {
+		if !slices.Contains(entity.EventTypes, t) {
+			return fmt.Errorf("%w: unknown event type %q", ErrInvalidWebhook, t)
+		}
+	}
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := cryptorand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// categoryResolver определяет категорию события по его payload, кэшируя поиски в пределах пачки.
+type categoryResolver struct {
+	topicRepo repository.TopicRepository
+	postRepo  repository.PostRepository
+	topics    map[int64]int64
+	posts     map[int64]int64
+}
+
+func newCategoryResolver(topicRepo repository.TopicRepository, postRepo repository.PostRepository) *categoryResolver {
+	return &categoryResolver{
+		topicRepo: topicRepo,
+		postRepo:  postRepo,
+		topics:    make(map[int64]int64),
+		posts:     make(map[int64]int64),
+	}
+}
+
+// resolve возвращает 0, если категорию уже не найти (например, топик удалён).
+func (r *categoryResolver) resolve(ctx context.Context, e *entity.Event) int64 {
+	var ref struct {
+		CategoryID int64 `json:"category_id"`
+		TopicID    int64 `json:"topic_id"`
+		PostID     int64 `json:"post_id"`
+	}
+	if err := json.Unmarshal(e.Payload, &ref); err != nil {
+		return 0
+	}
+	switch {
+	case ref.CategoryID > 0:
+		return ref.CategoryID
+	case e.AggregateType == "topic":
+		return r.topicCategory(ctx, e.AggregateID)
+	case ref.TopicID > 0:
+		return r.topicCategory(ctx, ref.TopicID)
+	case e.AggregateType == "post":
+		return r.postCategory(ctx, e.AggregateID)
+	case ref.PostID > 0:
+		return r.postCategory(ctx, ref.PostID)
+	}
+	return 0
+}
+
+func (r *categoryResolver) topicCategory(ctx context.Context, topicID int64) int64 {
+	if id, ok := r.topics[topicID]; ok {
+		return id
+	}
+	var categoryID int64
+	if t, err := r.topicRepo.GetByID(ctx, topicID); err == nil {
+		categoryID = t.CategoryID
+	}
+	r.topics[topicID] = categoryID
+	return categoryID
+}
+
+func (r *categoryResolver) postCategory(ctx context.Context, postID int64) int64 {
+	if id, ok := r.posts[postID]; ok {
+		return id
+	}
+	var categoryID int64
+	if p, err := r.postRepo.GetByID(ctx, postID); err == nil {
+		categoryID = r.topicCategory(ctx, p.TopicID)
+	}
+	r.posts[postID] = categoryID
+	return categoryID
+}
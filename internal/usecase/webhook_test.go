@@ -0,0 +1,261 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/VaneZ444/forum-service/internal/entity"
+	"github.com/VaneZ444/forum-service/internal/repository"
+	"github.com/VaneZ444/forum-service/internal/webhook"
+)
+
+// fakeWebhookRepo повторяет семантику postgres-репозитория для очереди доставок одного вебхука.
+type fakeWebhookRepo struct {
+	repository.WebhookRepository
+
+	mu         sync.Mutex
+	hook       entity.Webhook
+	deliveries []*entity.WebhookDelivery
+}
+
+func (r *fakeWebhookRepo) GetByID(context.Context, int64) (*entity.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	w := r.hook
+	return &w, nil
+}
+
+func (r *fakeWebhookRepo) ClaimDue(_ context.Context, now, leaseUntil time.Time, limit int) ([]*entity.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var due []*entity.WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.Status != entity.DeliveryPending || d.NextAttemptAt.After(now) || len(due) == limit {
+			continue
+		}
+		d.NextAttemptAt = leaseUntil
+		claimed := *d
+		due = append(due, &claimed)
+	}
+	return due, nil
+}
+
+func (r *fakeWebhookRepo) SaveDeliveryResult(_ context.Context, d *entity.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, stored := range r.deliveries {
+		if stored.ID == d.ID {
+			saved := *d
+			r.deliveries[i] = &saved
+		}
+	}
+	return nil
+}
+
+func (r *fakeWebhookRepo) RecordResult(_ context.Context, _ int64, success bool, deadAfter int, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if success {
+		r.hook.ConsecutiveFailures = 0
+		return false, nil
+	}
+	r.hook.ConsecutiveFailures++
+	if r.hook.DeadAt == nil && r.hook.ConsecutiveFailures >= deadAfter {
+		r.hook.DeadAt = &at
+	}
+	if r.hook.DeadAt == nil {
+		return false, nil
+	}
+	for _, d := range r.deliveries {
+		if d.Status == entity.DeliveryPending {
+			d.Status = entity.DeliveryDead
+		}
+	}
+	return true, nil
+}
+
+// advance переносит все отложенные попытки в прошлое, как будто пауза уже прошла.
+func (r *fakeWebhookRepo) advance() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, d := range r.deliveries {
+		d.NextAttemptAt = time.Now().Add(-time.Second)
+	}
+}
+
+func (r *fakeWebhookRepo) delivery(id int64) entity.WebhookDelivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, d := range r.deliveries {
+		if d.ID == id {
+			return *d
+		}
+	}
+	return entity.WebhookDelivery{}
+}
+
+// receiver — получатель вебхуков, который проверяет подпись и отвечает по сценарию.
+type receiver struct {
+	secret    string
+	responses []int // коды ответов по порядку, дальше — последний
+
+	mu       sync.Mutex
+	requests int
+	badSigs  int
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	ts, _ := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.requests++
+	if !webhook.Verify(rc.secret, ts, body, r.Header.Get(webhook.HeaderSignature)) {
+		rc.badSigs++
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	w.WriteHeader(rc.responses[min(rc.requests, len(rc.responses))-1])
+}
+
+func TestWebhookDeliveryRetriesAndDeadLetter(t *testing.T) {
+	const secret = "s3cret"
+	cfg := WebhookConfig{
+		MaxAttempts: 4,
+		BaseBackoff: time.Minute,
+		MaxBackoff:  time.Hour,
+		DeadAfter:   3,
+		BatchSize:   10,
+		Concurrency: 2,
+		Lease:       time.Minute,
+	}
+
+	tests := []struct {
+		name           string
+		receiverSecret string
+		responses      []int
+		deadAfter      int // 0 — из cfg
+		rounds         int
+		wantStatus     entity.DeliveryStatus
+		wantAttempts   int
+		wantDead       bool
+		wantBadSigs    int
+	}{
+		{
+			name:         "delivered first time",
+			responses:    []int{http.StatusOK},
+			rounds:       3,
+			wantStatus:   entity.DeliveryDelivered,
+			wantAttempts: 1,
+		},
+		{
+			name:         "delivered after retries",
+			responses:    []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusNoContent},
+			rounds:       5,
+			wantStatus:   entity.DeliveryDelivered,
+			wantAttempts: 3,
+		},
+		{
+			name:         "dead-letter after consecutive failures",
+			responses:    []int{http.StatusInternalServerError},
+			rounds:       5,
+			wantStatus:   entity.DeliveryDead,
+			wantAttempts: 3,
+			wantDead:     true,
+		},
+		{
+			name:         "failed after max attempts",
+			responses:    []int{http.StatusServiceUnavailable},
+			deadAfter:    10,
+			rounds:       6,
+			wantStatus:   entity.DeliveryFailed,
+			wantAttempts: 4,
+		},
+		{
+			name:         "gone moves to dead-letter at once",
+			responses:    []int{http.StatusGone},
+			rounds:       3,
+			wantStatus:   entity.DeliveryDead,
+			wantAttempts: 1,
+			wantDead:     true,
+		},
+		{
+			name:           "receiver rejects a wrong signature",
+			receiverSecret: "other",
+			responses:      []int{http.StatusOK},
+			rounds:         5,
+			wantStatus:     entity.DeliveryDead,
+			wantAttempts:   3,
+			wantDead:       true,
+			wantBadSigs:    3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := &receiver{secret: secret, responses: tt.responses}
+			if tt.receiverSecret != "" {
+				rc.secret = tt.receiverSecret
+			}
+			srv := httptest.NewServer(rc)
+			defer srv.Close()
+
+			repo := &fakeWebhookRepo{
+				hook: entity.Webhook{ID: 1, URL: srv.URL, Secret: secret, Active: true},
+				deliveries: []*entity.WebhookDelivery{{
+					ID:            10,
+					WebhookID:     1,
+					EventID:       100,
+					EventType:     entity.EventPostCreated,
+					Payload:       []byte(`{"id":100}`),
+					Status:        entity.DeliveryPending,
+					NextAttemptAt: time.Now().Add(-time.Second),
+				}},
+			}
+			cfg := cfg
+			if tt.deadAfter > 0 {
+				cfg.DeadAfter = tt.deadAfter
+			}
+			uc := &webhookUseCase{
+				webhookRepo: repo,
+				sender:      webhook.NewSender(srv.Client(), 5*time.Second),
+				cfg:         cfg,
+				logger:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+			}
+
+			for round := 0; round < tt.rounds; round++ {
+				before := time.Now()
+				if err := uc.DeliverDue(context.Background()); err != nil {
+					t.Fatalf("DeliverDue() error = %v", err)
+				}
+				d := repo.delivery(10)
+				if d.Status == entity.DeliveryPending && d.Attempts > 0 {
+					// Пауза растёт вдвое с каждой попыткой, разброс ±10%
+					want := cfg.BaseBackoff << (d.Attempts - 1)
+					if got := d.NextAttemptAt.Sub(before); got < want*9/10 || got > want*11/10+time.Second {
+						t.Fatalf("attempt %d: backoff = %v, want about %v", d.Attempts, got, want)
+					}
+				}
+				repo.advance()
+			}
+
+			d := repo.delivery(10)
+			if d.Status != tt.wantStatus || d.Attempts != tt.wantAttempts {
+				t.Fatalf("delivery = (%s, %d attempts), want (%s, %d)", d.Status, d.Attempts, tt.wantStatus, tt.wantAttempts)
+			}
+			if rc.requests != tt.wantAttempts || rc.badSigs != tt.wantBadSigs {
+				t.Fatalf("receiver got %d requests (%d badly signed), want %d (%d)", rc.requests, rc.badSigs, tt.wantAttempts, tt.wantBadSigs)
+			}
+			if dead := repo.hook.IsDead(); dead != tt.wantDead {
+				t.Fatalf("webhook dead = %v, want %v", dead, tt.wantDead)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+// Package webhook отправляет подписанные HTTP-запросы на внешние эндпоинты.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderEvent     = "X-Forum-Event"
+	HeaderDelivery  = "X-Forum-Delivery"
+	HeaderTimestamp = "X-Forum-Timestamp"
+	HeaderSignature = "X-Forum-Signature"
+)
+
+// Sign возвращает подпись "sha256=<hex>" от "<timestamp>.<body>".
+// Получатель пересчитывает её тем же секретом и отбрасывает запросы со старым timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify — проверка подписи на стороне получателя.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+type Request struct {
+	URL        string
+	Secret     string
+	EventType  string
+	DeliveryID int64
+	Body       []byte
+}
+
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender создаёт отправителя; client = nil — http.Client с заданным timeout.
+func NewSender(client *http.Client, timeout time.Duration) *Sender {
+	if client == nil {
+		client = &http.Client{Timeout: timeout}
+	}
+	return &Sender{client: client}
+}
+
+// Send отправляет POST и возвращает код ответа. Ответ не 2xx — тоже ошибка, код при этом заполнен.
+func (s *Sender) Send(ctx context.Context, req Request) (int, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	ts := time.Now().Unix()
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "forum-service-webhooks/1")
+	httpReq.Header.Set(HeaderEvent, req.EventType)
+	httpReq.Header.Set(HeaderDelivery, strconv.FormatInt(req.DeliveryID, 10))
+	httpReq.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	httpReq.Header.Set(HeaderSignature, Sign(req.Secret, ts, req.Body))
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Дочитываем тело, чтобы соединение вернулось в пул
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
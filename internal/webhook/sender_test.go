@@ -0,0 +1,43 @@
+package webhook
+
+import "testing"
+
+func TestSign(t *testing.T) {
+	// HMAC-SHA256("secret", "1700000000.{\"id\":1}"), посчитан независимо
+	const want = "sha256=3dd1b9aef568d75f6790a84bd2e5dfa1f44409eef3cbdbd3f10b837376100c11"
+	if got := Sign("secret", 1700000000, []byte(`{"id":1}`)); got != want {
+		t.Fatalf("Sign() = %q, want %q", got, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	const (
+		secret = "secret"
+		ts     = int64(1700000000)
+	)
+	body := []byte(`{"id":1}`)
+	valid := Sign(secret, ts, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      string
+		signature string
+		want      bool
+	}{
+		{name: "valid", secret: secret, timestamp: ts, body: string(body), signature: valid, want: true},
+		{name: "wrong secret", secret: "other", timestamp: ts, body: string(body), signature: valid},
+		{name: "replayed with another timestamp", secret: secret, timestamp: ts + 1, body: string(body), signature: valid},
+		{name: "tampered body", secret: secret, timestamp: ts, body: `{"id":2}`, signature: valid},
+		{name: "missing prefix", secret: secret, timestamp: ts, body: string(body), signature: valid[len("sha256="):]},
+		{name: "empty signature", secret: secret, timestamp: ts, body: string(body)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.timestamp, []byte(tt.body), tt.signature); got != tt.want {
+				t.Fatalf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
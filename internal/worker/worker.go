@@ -0,0 +1,30 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Run вызывает fn каждые interval, пока не отменён ctx.
+// Ошибка очередного запуска логируется и не останавливает цикл.
+func Run(ctx context.Context, logger *slog.Logger, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	logger = logger.With(slog.String("job", name))
+	logger.Info("background job started", slog.Duration("interval", interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := fn(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("background job failed", slog.String("err", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Info("background job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}